@@ -162,8 +162,10 @@ namespace TikTok.UserService.API.Services
         {
             try
             {
-                var userId = Guid.Parse(request.UserId);
-                var user = await _userService.GetUserByIdAsync(userId, context.CancellationToken);
+                // Services resolving @mentions only know the username
+                var user = string.IsNullOrEmpty(request.UserId) && !string.IsNullOrEmpty(request.Username)
+                    ? await _userService.GetUserByUsernameAsync(request.Username, context.CancellationToken)
+                    : await _userService.GetUserByIdAsync(Guid.Parse(request.UserId), context.CancellationToken);
 
                 if (user == null)
                 {
@@ -1,52 +1,146 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
+	"time"
 
 	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/shared/common/pagination"
 	"tiktok-clone/shared/config"
 	"tiktok-clone/shared/db"
 	"tiktok-clone/shared/middleware"
 	pb "tiktok-clone/shared/proto"
+	videoconfig "tiktok-clone/video-service/internal/config"
 	"tiktok-clone/video-service/internal/delivery/grpc/handler"
+	"tiktok-clone/video-service/internal/infrastructure/cache"
+	"tiktok-clone/video-service/internal/infrastructure/events"
 	"tiktok-clone/video-service/internal/infrastructure/persistence/postgres"
+	"tiktok-clone/video-service/internal/infrastructure/playback"
 	"tiktok-clone/video-service/internal/infrastructure/storage"
+	"tiktok-clone/video-service/internal/infrastructure/transcoding"
+	"tiktok-clone/video-service/internal/infrastructure/userservice"
 	"tiktok-clone/video-service/internal/usecase"
+	"tiktok-clone/video-service/internal/worker"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	videoCfg, err := videoconfig.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Initialize logger
 	logger.InitLogger(cfg.ServiceName, cfg.Environment)
 	log.Println("Starting Video Service...")
 
-	// Initialize database
+	// Initialize database and cache
 	database := db.InitPostgreSQL(cfg.Postgres)
+	redisClient := db.InitRedis(cfg.Redis)
+	defer redisClient.Close()
+
+	// Initialize playback URL signing. Presigned storage URLs cannot cover the
+	// files of HLS playlists, so private videos need path tokens.
+	tokenSigner, err := newTokenSigner(videoCfg.Playback, cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize playback signing: %v", err)
+	}
 
 	// Initialize storage service
-	storageService, err := storage.NewS3Storage("tiktok-videos", "us-east-1")
+	storageService, err := newStorageService(cfg.Storage, tokenSigner)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 
 	// Initialize repositories
 	videoRepo := postgres.NewVideoRepository(database)
+	contentRepo := postgres.NewContentObjectRepository(database)
+	quotaRepo := postgres.NewUploadQuotaRepository(database)
+	uploadSessionRepo := postgres.NewUploadSessionRepository(database)
+	trendingRepo := postgres.NewTrendingRepository(database)
+	viewRepo := postgres.NewVideoViewRepository(database)
+	counterRepo := postgres.NewCounterRepository(database)
+	shareRepo := postgres.NewShareLinkRepository(database)
+	hashtagRepo := postgres.NewHashtagRepository(database)
+	mentionRepo := postgres.NewMentionRepository(database)
+
+	// Initialize transcoding
+	transcodingJobRepo := postgres.NewTranscodingJobRepository(database)
+	transcodingQueue := usecase.NewTranscodingJobQueue(transcodingJobRepo, videoCfg.Transcoding.MaxAttempts)
+	transcoder := transcoding.NewFFmpegTranscoder(videoCfg.Transcoding.FFmpegPath, videoCfg.Transcoding.SegmentSeconds)
+	artworkRenderer := transcoding.NewFFmpegArtworkRenderer(videoCfg.Transcoding.FFmpegPath)
+	fingerprinter := transcoding.NewFFmpegFingerprinter(videoCfg.Transcoding.FFmpegPath)
+	watermarker := transcoding.NewFFmpegWatermarker(videoCfg.Transcoding.FFmpegPath, videoCfg.Transcoding.WatermarkFont)
+	mediaProber := transcoding.NewFFprobeProber(videoCfg.Transcoding.FFprobePath)
+
+	// Initialize authorization; followers-only videos need the follow graph of the user service,
+	// which also keeps the content region users prefer trending videos from and resolves mentions
+	var followChecker usecase.FollowChecker
+	var regionPreferences usecase.RegionPreferences
+	var userLookup usecase.UserLookup
+	if videoCfg.UserService.Addr != "" {
+		userConn, err := grpc.Dial(videoCfg.UserService.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Fatalf("Failed to connect to user service: %v", err)
+		}
+		defer userConn.Close()
+		userClient := pb.NewUserServiceClient(userConn)
+		followChecker = userservice.NewGRPCFollowChecker(userClient, videoCfg.UserService.Timeout)
+		regionPreferences = userservice.NewGRPCRegionPreferences(userClient, videoCfg.UserService.Timeout)
+		userLookup = userservice.NewGRPCUserLookup(userClient, videoCfg.UserService.Timeout)
+	}
+	videoPolicy := usecase.NewVideoPolicy(followChecker, videoCfg.Moderation)
+	mentionPublisher := events.NewRedisMentionPublisher(redisClient, videoCfg.Mentions.EventStream, videoCfg.Mentions.EventStreamMaxLen)
+	mentionResolver := usecase.NewMentionResolver(mentionRepo, userLookup, mentionPublisher)
+
+	// Initialize list pagination
+	cursorKey := []byte(videoCfg.Pagination.CursorSigningKey)
+	if len(cursorKey) == 0 {
+		// Cursors then only have to survive this process in development
+		cursorKey = make([]byte, 32)
+		if _, err := rand.Read(cursorKey); err != nil {
+			log.Fatalf("Failed to generate cursor signing key: %v", err)
+		}
+	}
+	cursorCodec := pagination.NewCodec(cursorKey)
+	countCache := cache.NewRedisCountCache(redisClient)
+	counterStore := cache.NewRedisCounterStore(redisClient, videoCfg.Counters.CacheTTL)
 
 	// Initialize use cases
-	videoUseCase := usecase.NewVideoUseCase(videoRepo, storageService, nil)
+	videoUseCase := usecase.NewVideoUseCase(videoRepo, contentRepo, quotaRepo, hashtagRepo, storageService, transcodingQueue, mediaProber, tokenSigner, videoPolicy, mentionResolver, countCache, counterStore, cursorCodec, videoCfg.Upload, videoCfg.Playback, videoCfg.Pagination)
+	uploadSessionUseCase := usecase.NewUploadSessionUseCase(uploadSessionRepo, storageService, videoUseCase, videoCfg.Upload)
+	transcodingUseCase := usecase.NewTranscodingUseCase(videoRepo, transcodingJobRepo, contentRepo, storageService, transcoder, artworkRenderer, fingerprinter, watermarker, videoUseCase, videoCfg.Transcoding, videoCfg.Moderation)
+	viewUseCase := usecase.NewViewUseCase(videoRepo, viewRepo, cache.NewRedisViewDeduplicator(redisClient), counterStore, videoPolicy, videoCfg.Views)
+	shareUseCase := usecase.NewShareUseCase(shareRepo, videoRepo, counterStore, transcodingQueue, videoUseCase, videoCfg.Shares)
+	counterUseCase := usecase.NewCounterUseCase(counterRepo, counterStore, videoCfg.Counters)
+	trendingUseCase := usecase.NewTrendingUseCase(trendingRepo, videoRepo, cache.NewRedisTrendingStore(redisClient), regionPreferences, videoUseCase, cursorCodec, videoCfg.Trending)
+
+	// Start background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go worker.RunPeriodic(ctx, "upload-session-expiry", videoCfg.Upload.SessionSweepInterval, uploadSessionUseCase.AbortExpiredSessions)
+	go worker.RunPool(ctx, "transcoding", videoCfg.Transcoding.Workers, videoCfg.Transcoding.PollInterval, transcodingUseCase.ProcessNextJob)
+	go worker.RunPeriodic(ctx, "trending", videoCfg.Trending.Interval, trendingUseCase.RecomputeTrending)
+	go worker.RunPeriodic(ctx, "counter-flush", videoCfg.Counters.FlushInterval, counterUseCase.FlushCounters)
+	go worker.RunPeriodic(ctx, "counter-reconcile", videoCfg.Counters.ReconcileInterval, counterUseCase.ReconcileCounters)
 
 	// Initialize gRPC handlers
-	videoHandler := handler.NewVideoServiceHandler(videoUseCase)
+	videoHandler := handler.NewVideoServiceHandler(videoUseCase, uploadSessionUseCase, transcodingUseCase, trendingUseCase, viewUseCase, shareUseCase)
 
 	// Create gRPC server
 	grpcServer := grpc.NewServer(
 		grpc.UnaryInterceptor(middleware.GRPCExtractUserInterceptor),
+		grpc.StreamInterceptor(middleware.GRPCExtractUserStreamInterceptor),
 	)
 
 	// Register services
@@ -68,3 +162,81 @@ func main() {
 		log.Fatalf("Failed to serve: %v", err)
 	}
 }
+
+// newTokenSigner creates the signer of the playback URLs of private videos.
+// Local storage stands in for the CDN when no CDN key is set; other storage
+// backends need one.
+func newTokenSigner(playbackCfg videoconfig.PlaybackConfig, storageCfg config.StorageConfig) (*playback.TokenSigner, error) {
+	if playbackCfg.CDNSigningKey != "" {
+		if playbackCfg.CDNURL == "" {
+			return nil, fmt.Errorf("PLAYBACK_CDN_URL is required with PLAYBACK_CDN_SIGNING_KEY")
+		}
+		return playback.NewTokenSigner(playbackCfg.CDNURL, []byte(playbackCfg.CDNSigningKey)), nil
+	}
+	if storageCfg.Type != "local" {
+		return nil, fmt.Errorf("PLAYBACK_CDN_URL and PLAYBACK_CDN_SIGNING_KEY are required with %s storage to play private videos", storageCfg.Type)
+	}
+
+	// Tokens verified by the local file server only have to survive this process
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return playback.NewTokenSigner(storageCfg.LocalPublicURL, key), nil
+}
+
+// newStorageService creates the storage backend selected by STORAGE_TYPE.
+// Local storage also serves the URLs of tokenSigner, standing in for the CDN.
+func newStorageService(cfg config.StorageConfig, tokenSigner *playback.TokenSigner) (usecase.StorageService, error) {
+	switch cfg.Type {
+	case "local":
+		signingKey := []byte(cfg.SigningKey)
+		if len(signingKey) == 0 {
+			// Presigned URLs only have to survive this process in development
+			signingKey = make([]byte, 32)
+			if _, err := rand.Read(signingKey); err != nil {
+				return nil, err
+			}
+		}
+
+		local, err := storage.NewLocalStorage(cfg.LocalRoot, cfg.LocalPublicURL, signingKey)
+		if err != nil {
+			return nil, err
+		}
+
+		storageHandler := http.NewServeMux()
+		storageHandler.Handle("/", local.Handler())
+		storageHandler.Handle(playback.PathPrefix, tokenSigner.Handler(local.VerifiedHandler()))
+
+		go func() {
+			log.Printf("Local storage serving %s on %s", cfg.LocalRoot, cfg.LocalAddr)
+			if err := http.ListenAndServe(cfg.LocalAddr, storageHandler); err != nil {
+				log.Fatalf("Failed to serve local storage: %v", err)
+			}
+		}()
+		return local, nil
+	case "s3", "minio":
+		s3Storage, err := storage.NewS3Storage(storage.S3Options{
+			Bucket:         cfg.Bucket,
+			Region:         cfg.Region,
+			Endpoint:       cfg.Endpoint,
+			UseSSL:         cfg.UseSSL,
+			ForcePathStyle: cfg.ForcePathStyle || cfg.Type == "minio", // MinIO has no virtual-host buckets by default
+			AccessKey:      cfg.AccessKey,
+			SecretKey:      cfg.SecretKey,
+			PublicURL:      cfg.PublicURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s3Storage.EnsureBucket(ctx); err != nil {
+			return nil, fmt.Errorf("ensure bucket %s: %w", cfg.Bucket, err)
+		}
+		return s3Storage, nil
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", cfg.Type)
+	}
+}
@@ -0,0 +1,106 @@
+// Command transcode-smoke runs the HLS transcoder and the artwork renderer on a
+// tiny generated test clip (or on -input) and checks that every playlist,
+// segment and image was written.
+// It needs ffmpeg but no database or storage.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/storagekey"
+	"tiktok-clone/video-service/internal/infrastructure/transcoding"
+)
+
+func main() {
+	ffmpegPath := flag.String("ffmpeg", "ffmpeg", "path to the ffmpeg binary")
+	input := flag.String("input", "", "video to transcode; a 3s 720x1280 test clip is generated when empty")
+	output := flag.String("output", "./data/transcode-smoke", "directory the HLS output is written to")
+	width := flag.Int("width", 720, "source width")
+	height := flag.Int("height", 1280, "source height")
+	duration := flag.Float64("duration", 3, "source duration in seconds")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := os.RemoveAll(*output); err != nil {
+		log.Fatalf("Failed to clean output: %v", err)
+	}
+	if err := os.MkdirAll(*output, 0o755); err != nil {
+		log.Fatalf("Failed to create output: %v", err)
+	}
+
+	if *input == "" {
+		*input = filepath.Join(*output, "test-clip.mp4")
+		if err := generateClip(ctx, *ffmpegPath, *input, *width, *height); err != nil {
+			log.Fatalf("Failed to generate test clip: %v", err)
+		}
+	}
+
+	transcoder := transcoding.NewFFmpegTranscoder(*ffmpegPath, 1)
+	hlsDir := filepath.Join(*output, "hls")
+	if err := transcoder.TranscodeHLS(ctx, *input, hlsDir, *width, *height); err != nil {
+		log.Fatalf("Transcoding failed: %v", err)
+	}
+
+	master, err := os.ReadFile(filepath.Join(hlsDir, transcoding.MasterPlaylist))
+	if err != nil {
+		log.Fatalf("Master playlist missing: %v", err)
+	}
+
+	for _, line := range strings.Split(string(master), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		segments, _ := filepath.Glob(filepath.Join(hlsDir, filepath.Dir(line), "*.ts"))
+		if len(segments) == 0 {
+			log.Fatalf("Rendition %s has no segments", line)
+		}
+		log.Printf("%s: %d segments", line, len(segments))
+	}
+
+	renderer := transcoding.NewFFmpegArtworkRenderer(*ffmpegPath)
+	artworkDir := filepath.Join(*output, "artwork")
+	if err := renderer.RenderCover(ctx, *input, artworkDir, -1, *duration); err != nil {
+		log.Fatalf("Cover rendering failed: %v", err)
+	}
+	if err := renderer.RenderPreviews(ctx, *input, artworkDir, *duration, *width, *height); err != nil {
+		log.Fatalf("Preview rendering failed: %v", err)
+	}
+
+	expected := []string{storagekey.PreviewFile, storagekey.SpriteFile, storagekey.StoryboardFile}
+	for _, width := range storagekey.CoverWidths {
+		expected = append(expected,
+			storagekey.CoverFile(width, storagekey.CoverFormatJPEG),
+			storagekey.CoverFile(width, storagekey.CoverFormatWebP))
+	}
+	for _, name := range expected {
+		if _, err := os.Stat(filepath.Join(artworkDir, name)); err != nil {
+			log.Fatalf("Artwork missing: %v", err)
+		}
+	}
+
+	log.Printf("HLS output written to %s, artwork to %s", hlsDir, artworkDir)
+}
+
+// generateClip writes a short test pattern with a sine tone
+func generateClip(ctx context.Context, ffmpegPath, path string, width, height int) error {
+	size := fmt.Sprintf("%dx%d", width, height)
+	cmd := exec.CommandContext(ctx, ffmpegPath,
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "testsrc=size="+size+":rate=30:duration=3",
+		"-f", "lavfi", "-i", "sine=frequency=440:duration=3",
+		"-c:v", "libx264", "-pix_fmt", "yuv420p", "-c:a", "aac", "-shortest",
+		path,
+	)
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
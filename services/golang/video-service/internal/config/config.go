@@ -0,0 +1,374 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Config holds video-service specific settings
+type Config struct {
+	Upload      UploadConfig
+	Transcoding TranscodingConfig
+	Playback    PlaybackConfig
+	Pagination  PaginationConfig
+	Trending    TrendingConfig
+	Views       ViewConfig
+	Counters    CounterConfig
+	Shares      ShareConfig
+	Mentions    MentionConfig
+	Moderation  ModerationConfig
+	UserService UserServiceConfig
+}
+
+// UploadConfig holds upload limits and resumable upload session settings
+type UploadConfig struct {
+	MaxFileSize          int64
+	ChunkSize            int64
+	SessionTTL           time.Duration
+	SessionSweepInterval time.Duration
+	// PresignTTL is how long presigned upload URLs stay valid
+	PresignTTL time.Duration
+	// PresignMultipartThreshold is the file size above which presigned uploads use multipart
+	PresignMultipartThreshold int64
+	// WorkDir holds uploaded files while they are probed
+	WorkDir string
+	// MaxThumbnailSize is the largest accepted thumbnail image, in bytes
+	MaxThumbnailSize int64
+	// MinDuration and MaxDuration bound the probed length of a video
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	// DailyUploadCount and DailyUploadBytes cap what one user may upload per
+	// UTC day; 0 means unlimited
+	DailyUploadCount int
+	DailyUploadBytes int64
+}
+
+// TranscodingConfig holds the transcoding worker settings
+type TranscodingConfig struct {
+	// Workers is the number of videos transcoded concurrently
+	Workers int
+	// PollInterval is how long an idle worker waits before looking for a job again
+	PollInterval time.Duration
+	// LeaseDuration is how long a job stays claimed without a heartbeat
+	// before another worker may take it over
+	LeaseDuration time.Duration
+	MaxAttempts   int
+	// RetryBaseDelay doubles after every failed attempt, up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	FFmpegPath     string
+	FFprobePath    string
+	SegmentSeconds int
+	// WorkDir holds the downloaded originals and ffmpeg output while transcoding
+	WorkDir string
+	// WatermarkText is drawn over the downloadable copies of videos, in
+	// WatermarkFont if set, else in a font ffmpeg looks up
+	WatermarkText string
+	WatermarkFont string
+}
+
+// PlaybackConfig holds how the URLs of private videos are signed
+type PlaybackConfig struct {
+	// URLTTL is how long signed URLs of private videos stay valid
+	URLTTL time.Duration
+	// CDNURL and CDNSigningKey enable path-token CDN URLs, which also cover the
+	// files of HLS playlists; without them private videos get presigned storage
+	// URLs and no playlist
+	CDNURL        string
+	CDNSigningKey string
+}
+
+// PaginationConfig holds list pagination settings
+type PaginationConfig struct {
+	// CursorSigningKey signs list cursors; when empty a random key is used,
+	// so cursors do not survive a restart or work across replicas
+	CursorSigningKey string
+	// TotalCacheTTL is how long list totals are cached
+	TotalCacheTTL time.Duration
+}
+
+// TrendingConfig holds the trending job settings
+type TrendingConfig struct {
+	// Interval is how often the scores are recomputed and the lists published
+	Interval time.Duration
+	// CandidateAge is how old a video may be to be scored at all
+	CandidateAge time.Duration
+	// HalfLife is the age at which the score of a video is halved
+	HalfLife time.Duration
+	// VelocityWindow is the time constant the view and engagement velocities
+	// are smoothed over
+	VelocityWindow time.Duration
+	// ListSize is how many videos each ranked list keeps
+	ListSize int
+	// MinRegionCandidates is how many videos a region needs for a list of its
+	// own; callers from smaller regions get the global list
+	MinRegionCandidates int
+	// MaxHashtagLists is how many hashtags, those with the most candidates, get lists
+	MaxHashtagLists int
+	// SnapshotRetention is how long score snapshots are kept in trending_scores;
+	// it must exceed a week for the weekly lists
+	SnapshotRetention time.Duration
+	// SnapshotThinAfter is the age after which only one snapshot per video and hour is kept
+	SnapshotThinAfter time.Duration
+	Weights           TrendingWeights
+}
+
+// TrendingWeights weigh the signals of the trending score
+type TrendingWeights struct {
+	// View, Like, Comment and Share weigh the hourly rate of each count
+	View    float64
+	Like    float64
+	Comment float64
+	Share   float64
+	// Completion multiplies the score by up to 1+Completion for videos watched to the end
+	Completion float64
+}
+
+// ViewConfig holds which view events count toward the view count
+type ViewConfig struct {
+	// MinWatchDuration is how long a video must be watched for the view to
+	// count; shorter videos must be watched to the end
+	MinWatchDuration time.Duration
+	// DedupWindow is how long further views of a video by the same viewer do not count
+	DedupWindow time.Duration
+}
+
+// CounterConfig holds the write-behind view, like and share counter settings
+type CounterConfig struct {
+	// FlushInterval is how often the counter deltas counted in Redis are
+	// written to the database
+	FlushInterval time.Duration
+	// CacheTTL is how long the totals of a video stay cached after they were read
+	CacheTTL time.Duration
+	// ReconcileInterval is how often the counts are recomputed from the like and view events
+	ReconcileInterval time.Duration
+	// BatchRetention is how long applied batches are remembered, so a batch
+	// flushed again after a crash is not applied twice
+	BatchRetention time.Duration
+}
+
+// ShareConfig holds the URLs of share links
+type ShareConfig struct {
+	// LinkBaseURL is where short share links are served, followed by the code
+	LinkBaseURL string
+	// VideoBaseURL is where resolved links redirect to, followed by the video ID
+	VideoBaseURL string
+}
+
+// MentionConfig holds where mention events are published for the notification service
+type MentionConfig struct {
+	// EventStream is the Redis stream mention events are added to
+	EventStream string
+	// EventStreamMaxLen caps the stream, approximately; older events are trimmed
+	EventStreamMaxLen int64
+}
+
+// ModerationConfig holds who may use the moderation endpoints
+type ModerationConfig struct {
+	// ModeratorIDs are the user IDs allowed to call moderation RPCs such as FindDuplicates
+	ModeratorIDs []string
+	// ReviewUploads holds transcoded videos in moderating until a moderator
+	// approves them; otherwise they become ready straight away
+	ReviewUploads bool
+}
+
+// IsModerator checks if userID is listed in ModeratorIDs
+func (c ModerationConfig) IsModerator(userID string) bool {
+	for _, id := range c.ModeratorIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// UserServiceConfig holds how to reach the user service, which owns the follow
+// graph, user settings and usernames
+type UserServiceConfig struct {
+	// Addr is the gRPC address; when empty followers-only videos are visible
+	// to their owner and staff only, trending lists follow the request region
+	// and mentions are not resolved
+	Addr    string
+	Timeout time.Duration
+}
+
+// MinUploadChunkSize is the smallest UPLOAD_CHUNK_SIZE: resumable uploads are
+// stored as S3 multipart uploads, whose parts but the last must be at least 5 MiB
+const MinUploadChunkSize = 5 << 20
+
+// Load reads video-service settings from environment variables
+func Load() (Config, error) {
+	viper.SetDefault("UPLOAD_MAX_FILE_SIZE", 512<<20)
+	viper.SetDefault("UPLOAD_CHUNK_SIZE", 8<<20)
+	viper.SetDefault("UPLOAD_SESSION_TTL", "24h")
+	viper.SetDefault("UPLOAD_SESSION_SWEEP_INTERVAL", "10m")
+	viper.SetDefault("UPLOAD_PRESIGN_TTL", "1h")
+	viper.SetDefault("UPLOAD_PRESIGN_MULTIPART_THRESHOLD", 100<<20)
+	viper.SetDefault("UPLOAD_WORK_DIR", os.TempDir())
+	viper.SetDefault("UPLOAD_MAX_THUMBNAIL_SIZE", 5<<20)
+	viper.SetDefault("UPLOAD_MIN_DURATION", "1s")
+	viper.SetDefault("UPLOAD_MAX_DURATION", "10m")
+	viper.SetDefault("UPLOAD_DAILY_QUOTA_COUNT", 50)
+	viper.SetDefault("UPLOAD_DAILY_QUOTA_BYTES", 10<<30)
+
+	viper.SetDefault("TRANSCODING_WORKERS", 2)
+	viper.SetDefault("TRANSCODING_POLL_INTERVAL", "2s")
+	viper.SetDefault("TRANSCODING_LEASE_DURATION", "2m")
+	viper.SetDefault("TRANSCODING_MAX_ATTEMPTS", 5)
+	viper.SetDefault("TRANSCODING_RETRY_BASE_DELAY", "30s")
+	viper.SetDefault("TRANSCODING_RETRY_MAX_DELAY", "30m")
+	viper.SetDefault("TRANSCODING_FFMPEG_PATH", "ffmpeg")
+	viper.SetDefault("TRANSCODING_FFPROBE_PATH", "ffprobe")
+	viper.SetDefault("TRANSCODING_SEGMENT_SECONDS", 4)
+	viper.SetDefault("TRANSCODING_WORK_DIR", os.TempDir())
+	viper.SetDefault("TRANSCODING_WATERMARK_TEXT", "TikTok Clone")
+	viper.SetDefault("TRANSCODING_WATERMARK_FONT", "")
+
+	viper.SetDefault("PLAYBACK_URL_TTL", "15m")
+	viper.SetDefault("PLAYBACK_CDN_URL", "")
+	viper.SetDefault("PLAYBACK_CDN_SIGNING_KEY", "")
+
+	viper.SetDefault("PAGINATION_CURSOR_SIGNING_KEY", "")
+	viper.SetDefault("PAGINATION_TOTAL_CACHE_TTL", "5m")
+
+	viper.SetDefault("TRENDING_INTERVAL", "5m")
+	viper.SetDefault("TRENDING_CANDIDATE_AGE", "168h")
+	viper.SetDefault("TRENDING_HALF_LIFE", "24h")
+	viper.SetDefault("TRENDING_VELOCITY_WINDOW", "1h")
+	viper.SetDefault("TRENDING_LIST_SIZE", 500)
+	viper.SetDefault("TRENDING_MIN_REGION_CANDIDATES", 20)
+	viper.SetDefault("TRENDING_MAX_HASHTAG_LISTS", 100)
+	viper.SetDefault("TRENDING_SNAPSHOT_RETENTION", "192h")
+	viper.SetDefault("TRENDING_SNAPSHOT_THIN_AFTER", "2h")
+	viper.SetDefault("TRENDING_WEIGHT_VIEW", 1.0)
+	viper.SetDefault("TRENDING_WEIGHT_LIKE", 4.0)
+	viper.SetDefault("TRENDING_WEIGHT_COMMENT", 6.0)
+	viper.SetDefault("TRENDING_WEIGHT_SHARE", 10.0)
+	viper.SetDefault("TRENDING_WEIGHT_COMPLETION", 1.0)
+
+	viper.SetDefault("VIEW_MIN_WATCH_DURATION", "3s")
+	viper.SetDefault("VIEW_DEDUP_WINDOW", "30m")
+
+	viper.SetDefault("COUNTER_FLUSH_INTERVAL", "10s")
+	viper.SetDefault("COUNTER_CACHE_TTL", "10m")
+	viper.SetDefault("COUNTER_RECONCILE_INTERVAL", "1h")
+	viper.SetDefault("COUNTER_BATCH_RETENTION", "168h")
+
+	viper.SetDefault("SHARE_LINK_BASE_URL", "http://localhost:8080/s")
+	viper.SetDefault("SHARE_VIDEO_BASE_URL", "http://localhost:3000/video")
+
+	viper.SetDefault("MENTION_EVENT_STREAM", "video-service:events:mentions")
+	viper.SetDefault("MENTION_EVENT_STREAM_MAX_LEN", 100000)
+
+	viper.SetDefault("MODERATOR_USER_IDS", "")
+	viper.SetDefault("MODERATION_REVIEW_UPLOADS", false)
+
+	viper.SetDefault("USER_SERVICE_ADDR", "")
+	viper.SetDefault("USER_SERVICE_TIMEOUT", "500ms")
+
+	viper.AutomaticEnv()
+
+	cfg := Config{
+		Upload: UploadConfig{
+			MaxFileSize:               viper.GetInt64("UPLOAD_MAX_FILE_SIZE"),
+			ChunkSize:                 viper.GetInt64("UPLOAD_CHUNK_SIZE"),
+			SessionTTL:                viper.GetDuration("UPLOAD_SESSION_TTL"),
+			SessionSweepInterval:      viper.GetDuration("UPLOAD_SESSION_SWEEP_INTERVAL"),
+			PresignTTL:                viper.GetDuration("UPLOAD_PRESIGN_TTL"),
+			PresignMultipartThreshold: viper.GetInt64("UPLOAD_PRESIGN_MULTIPART_THRESHOLD"),
+			WorkDir:                   viper.GetString("UPLOAD_WORK_DIR"),
+			MaxThumbnailSize:          viper.GetInt64("UPLOAD_MAX_THUMBNAIL_SIZE"),
+			MinDuration:               viper.GetDuration("UPLOAD_MIN_DURATION"),
+			MaxDuration:               viper.GetDuration("UPLOAD_MAX_DURATION"),
+			DailyUploadCount:          viper.GetInt("UPLOAD_DAILY_QUOTA_COUNT"),
+			DailyUploadBytes:          viper.GetInt64("UPLOAD_DAILY_QUOTA_BYTES"),
+		},
+		Transcoding: TranscodingConfig{
+			Workers:        viper.GetInt("TRANSCODING_WORKERS"),
+			PollInterval:   viper.GetDuration("TRANSCODING_POLL_INTERVAL"),
+			LeaseDuration:  viper.GetDuration("TRANSCODING_LEASE_DURATION"),
+			MaxAttempts:    viper.GetInt("TRANSCODING_MAX_ATTEMPTS"),
+			RetryBaseDelay: viper.GetDuration("TRANSCODING_RETRY_BASE_DELAY"),
+			RetryMaxDelay:  viper.GetDuration("TRANSCODING_RETRY_MAX_DELAY"),
+			FFmpegPath:     viper.GetString("TRANSCODING_FFMPEG_PATH"),
+			FFprobePath:    viper.GetString("TRANSCODING_FFPROBE_PATH"),
+			SegmentSeconds: viper.GetInt("TRANSCODING_SEGMENT_SECONDS"),
+			WorkDir:        viper.GetString("TRANSCODING_WORK_DIR"),
+			WatermarkText:  viper.GetString("TRANSCODING_WATERMARK_TEXT"),
+			WatermarkFont:  viper.GetString("TRANSCODING_WATERMARK_FONT"),
+		},
+		Playback: PlaybackConfig{
+			URLTTL:        viper.GetDuration("PLAYBACK_URL_TTL"),
+			CDNURL:        viper.GetString("PLAYBACK_CDN_URL"),
+			CDNSigningKey: viper.GetString("PLAYBACK_CDN_SIGNING_KEY"),
+		},
+		Pagination: PaginationConfig{
+			CursorSigningKey: viper.GetString("PAGINATION_CURSOR_SIGNING_KEY"),
+			TotalCacheTTL:    viper.GetDuration("PAGINATION_TOTAL_CACHE_TTL"),
+		},
+		Trending: TrendingConfig{
+			Interval:            viper.GetDuration("TRENDING_INTERVAL"),
+			CandidateAge:        viper.GetDuration("TRENDING_CANDIDATE_AGE"),
+			HalfLife:            viper.GetDuration("TRENDING_HALF_LIFE"),
+			VelocityWindow:      viper.GetDuration("TRENDING_VELOCITY_WINDOW"),
+			ListSize:            viper.GetInt("TRENDING_LIST_SIZE"),
+			MinRegionCandidates: viper.GetInt("TRENDING_MIN_REGION_CANDIDATES"),
+			MaxHashtagLists:     viper.GetInt("TRENDING_MAX_HASHTAG_LISTS"),
+			SnapshotRetention:   viper.GetDuration("TRENDING_SNAPSHOT_RETENTION"),
+			SnapshotThinAfter:   viper.GetDuration("TRENDING_SNAPSHOT_THIN_AFTER"),
+			Weights: TrendingWeights{
+				View:       viper.GetFloat64("TRENDING_WEIGHT_VIEW"),
+				Like:       viper.GetFloat64("TRENDING_WEIGHT_LIKE"),
+				Comment:    viper.GetFloat64("TRENDING_WEIGHT_COMMENT"),
+				Share:      viper.GetFloat64("TRENDING_WEIGHT_SHARE"),
+				Completion: viper.GetFloat64("TRENDING_WEIGHT_COMPLETION"),
+			},
+		},
+		Views: ViewConfig{
+			MinWatchDuration: viper.GetDuration("VIEW_MIN_WATCH_DURATION"),
+			DedupWindow:      viper.GetDuration("VIEW_DEDUP_WINDOW"),
+		},
+		Counters: CounterConfig{
+			FlushInterval:     viper.GetDuration("COUNTER_FLUSH_INTERVAL"),
+			CacheTTL:          viper.GetDuration("COUNTER_CACHE_TTL"),
+			ReconcileInterval: viper.GetDuration("COUNTER_RECONCILE_INTERVAL"),
+			BatchRetention:    viper.GetDuration("COUNTER_BATCH_RETENTION"),
+		},
+		Shares: ShareConfig{
+			LinkBaseURL:  viper.GetString("SHARE_LINK_BASE_URL"),
+			VideoBaseURL: viper.GetString("SHARE_VIDEO_BASE_URL"),
+		},
+		Mentions: MentionConfig{
+			EventStream:       viper.GetString("MENTION_EVENT_STREAM"),
+			EventStreamMaxLen: viper.GetInt64("MENTION_EVENT_STREAM_MAX_LEN"),
+		},
+		Moderation: ModerationConfig{
+			ModeratorIDs:  splitList(viper.GetString("MODERATOR_USER_IDS")),
+			ReviewUploads: viper.GetBool("MODERATION_REVIEW_UPLOADS"),
+		},
+		UserService: UserServiceConfig{
+			Addr:    viper.GetString("USER_SERVICE_ADDR"),
+			Timeout: viper.GetDuration("USER_SERVICE_TIMEOUT"),
+		},
+	}
+
+	if cfg.Upload.ChunkSize < MinUploadChunkSize {
+		return cfg, fmt.Errorf("UPLOAD_CHUNK_SIZE must be at least %d bytes (5 MiB), got %d", MinUploadChunkSize, cfg.Upload.ChunkSize)
+	}
+	return cfg, nil
+}
+
+// splitList parses a comma-separated environment variable, skipping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+
+	"tiktok-clone/shared/common/errors"
+	pb "tiktok-clone/shared/proto"
+	"tiktok-clone/video-service/internal/usecase/dto"
+)
+
+// GetHashtag retrieves the header of a hashtag page
+func (h *VideoServiceHandler) GetHashtag(ctx context.Context, req *pb.GetHashtagRequest) (*pb.Hashtag, error) {
+	hashtag, err := h.videoUseCase.GetHashtag(ctx, req.Name)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+	return toProtoHashtag(hashtag), nil
+}
+
+// ListVideosByHashtag retrieves a page of the public videos of a hashtag
+func (h *VideoServiceHandler) ListVideosByHashtag(ctx context.Context, req *pb.ListVideosByHashtagRequest) (*pb.GetUserVideosResponse, error) {
+	page, err := h.videoUseCase.ListVideosByHashtag(ctx, &dto.HashtagVideosRequest{
+		Hashtag: req.Hashtag,
+		Sort:    req.Sort,
+		Cursor:  req.Cursor,
+		Limit:   int(req.Limit),
+	})
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoListResponse(page), nil
+}
+
+// SuggestHashtags autocompletes a hashtag being typed
+func (h *VideoServiceHandler) SuggestHashtags(ctx context.Context, req *pb.SuggestHashtagsRequest) (*pb.SuggestHashtagsResponse, error) {
+	hashtags, err := h.videoUseCase.SuggestHashtags(ctx, req.Prefix, int(req.Limit))
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	response := &pb.SuggestHashtagsResponse{
+		Hashtags: make([]*pb.Hashtag, len(hashtags)),
+	}
+	for i, hashtag := range hashtags {
+		response.Hashtags[i] = toProtoHashtag(hashtag)
+	}
+	return response, nil
+}
+
+// toProtoHashtag converts DTO to protobuf message
+func toProtoHashtag(hashtag *dto.HashtagResponse) *pb.Hashtag {
+	return &pb.Hashtag{
+		Name:       hashtag.Name,
+		UsageCount: hashtag.UsageCount,
+		CreatedAt:  hashtag.CreatedAt.Unix(),
+	}
+}
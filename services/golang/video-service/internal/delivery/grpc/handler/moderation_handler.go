@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+
+	"tiktok-clone/shared/common/errors"
+	pb "tiktok-clone/shared/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FindDuplicates lists videos with the same content as a video, for moderators
+func (h *VideoServiceHandler) FindDuplicates(ctx context.Context, req *pb.FindDuplicatesRequest) (*pb.FindDuplicatesResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	duplicates, err := h.videoUseCase.FindDuplicates(ctx, videoID, viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	response := &pb.FindDuplicatesResponse{
+		Duplicates: make([]*pb.DuplicateVideo, len(duplicates)),
+	}
+	for i, duplicate := range duplicates {
+		response.Duplicates[i] = &pb.DuplicateVideo{
+			Video:    h.toProtoVideoResponse(duplicate.Video),
+			Match:    duplicate.Match,
+			Distance: int32(duplicate.Distance),
+		}
+	}
+	return response, nil
+}
+
+// ListVideosForReview lists the videos waiting for content review, for moderators
+func (h *VideoServiceHandler) ListVideosForReview(ctx context.Context, req *pb.ListVideosForReviewRequest) (*pb.GetUserVideosResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.videoUseCase.ListVideosForReview(ctx, req.Cursor, int(req.Limit), viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoListResponse(page), nil
+}
+
+// ReviewVideo approves or rejects a video waiting for content review, for moderators
+func (h *VideoServiceHandler) ReviewVideo(ctx context.Context, req *pb.ReviewVideoRequest) (*pb.VideoResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	video, err := h.videoUseCase.ReviewVideo(ctx, videoID, req.Approve, req.Reason, viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoResponse(video), nil
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/shared/common/errors"
+	pb "tiktok-clone/shared/proto"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetTranscodingJob reports the state of the latest transcoding job of a video
+func (h *VideoServiceHandler) GetTranscodingJob(ctx context.Context, req *pb.GetTranscodingJobRequest) (*pb.TranscodingJobResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	job, err := h.transcodingUseCase.GetTranscodingJob(ctx, videoID, viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return toProtoTranscodingJobResponse(job), nil
+}
+
+// SetCoverFrame queues re-rendering the cover of a video from the frame at the given timestamp
+func (h *VideoServiceHandler) SetCoverFrame(ctx context.Context, req *pb.SetCoverFrameRequest) (*pb.TranscodingJobResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+	if req.TimestampMs < 0 {
+		return nil, status.Error(codes.InvalidArgument, "timestamp must not be negative")
+	}
+
+	job, err := h.transcodingUseCase.SetCoverFrame(ctx, videoID, viewer, time.Duration(req.TimestampMs)*time.Millisecond)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return toProtoTranscodingJobResponse(job), nil
+}
+
+// toProtoTranscodingJobResponse converts DTO to protobuf response
+func toProtoTranscodingJobResponse(job *dto.TranscodingJobResponse) *pb.TranscodingJobResponse {
+	response := &pb.TranscodingJobResponse{
+		JobId:       job.JobID,
+		VideoId:     job.VideoID,
+		Kind:        job.Kind,
+		Status:      job.Status,
+		Attempts:    int32(job.Attempts),
+		MaxAttempts: int32(job.MaxAttempts),
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt.Unix(),
+		UpdatedAt:   job.UpdatedAt.Unix(),
+	}
+	if !job.NextRunAt.IsZero() {
+		response.NextRunAt = job.NextRunAt.Unix()
+	}
+	return response
+}
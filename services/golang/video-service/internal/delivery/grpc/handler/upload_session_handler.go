@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/shared/middleware"
+	pb "tiktok-clone/shared/proto"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateUploadSession starts a resumable upload
+func (h *VideoServiceHandler) CreateUploadSession(ctx context.Context, req *pb.CreateUploadSessionRequest) (*pb.UploadSessionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := h.uploadSessionUseCase.CreateUploadSession(ctx, &dto.CreateUploadSessionRequest{
+		UserID:          userID,
+		Title:           req.Title,
+		Description:     req.Description,
+		DurationSeconds: int(req.DurationSeconds),
+		Width:           int(req.Width),
+		Height:          int(req.Height),
+		FileSize:        req.FileSize,
+	})
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoUploadSessionResponse(session), nil
+}
+
+// UploadChunk stores one numbered part of a resumable upload
+func (h *VideoServiceHandler) UploadChunk(ctx context.Context, req *pb.UploadChunkRequest) (*pb.UploadSessionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := uuid.Parse(req.UploadId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid upload ID")
+	}
+
+	session, err := h.uploadSessionUseCase.UploadChunk(ctx, &dto.UploadChunkRequest{
+		UserID:     userID,
+		SessionID:  sessionID,
+		PartNumber: int(req.PartNumber),
+		Data:       req.Data,
+	})
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoUploadSessionResponse(session), nil
+}
+
+// GetUploadSession reports which parts of a resumable upload were received
+func (h *VideoServiceHandler) GetUploadSession(ctx context.Context, req *pb.GetUploadSessionRequest) (*pb.UploadSessionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := uuid.Parse(req.UploadId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid upload ID")
+	}
+
+	session, err := h.uploadSessionUseCase.GetUploadSession(ctx, sessionID, userID)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoUploadSessionResponse(session), nil
+}
+
+// CompleteUpload assembles a resumable upload and creates the video
+func (h *VideoServiceHandler) CompleteUpload(ctx context.Context, req *pb.CompleteUploadRequest) (*pb.VideoResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := uuid.Parse(req.UploadId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid upload ID")
+	}
+
+	video, err := h.uploadSessionUseCase.CompleteUpload(ctx, &dto.CompleteUploadRequest{
+		UserID:        userID,
+		Region:        middleware.GetRegionFromContext(ctx),
+		SessionID:     sessionID,
+		ThumbnailData: req.ThumbnailData,
+	})
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to complete upload", zap.Error(err))
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoResponse(video), nil
+}
+
+// toProtoUploadSessionResponse converts DTO to protobuf response
+func (h *VideoServiceHandler) toProtoUploadSessionResponse(session *dto.UploadSessionResponse) *pb.UploadSessionResponse {
+	received := make([]int32, len(session.ReceivedParts))
+	for i, part := range session.ReceivedParts {
+		received[i] = int32(part)
+	}
+
+	return &pb.UploadSessionResponse{
+		UploadId:      session.UploadID,
+		VideoId:       session.VideoID,
+		ChunkSize:     session.ChunkSize,
+		TotalParts:    int32(session.TotalParts),
+		ReceivedParts: received,
+		Status:        session.Status,
+		ExpiresAt:     session.ExpiresAt.Unix(),
+	}
+}
+
+// RequestUploadURL creates a pending video and returns presigned upload URLs
+func (h *VideoServiceHandler) RequestUploadURL(ctx context.Context, req *pb.RequestUploadURLRequest) (*pb.UploadURLResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := h.uploadSessionUseCase.RequestUploadURL(ctx, &dto.RequestUploadURLRequest{
+		UserID:          userID,
+		Region:          middleware.GetRegionFromContext(ctx),
+		Title:           req.Title,
+		Description:     req.Description,
+		DurationSeconds: int(req.DurationSeconds),
+		Width:           int(req.Width),
+		Height:          int(req.Height),
+		FileSize:        req.FileSize,
+		ChecksumSHA256:  req.ChecksumSha256,
+	})
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	parts := make([]*pb.PresignedPart, len(upload.Parts))
+	for i, part := range upload.Parts {
+		parts[i] = &pb.PresignedPart{
+			PartNumber: int32(part.PartNumber),
+			Url:        part.URL,
+		}
+	}
+
+	return &pb.UploadURLResponse{
+		VideoId:   upload.VideoID,
+		UploadId:  upload.UploadID,
+		UploadUrl: upload.UploadURL,
+		Parts:     parts,
+		ChunkSize: upload.ChunkSize,
+		Headers:   upload.Headers,
+		ExpiresAt: upload.ExpiresAt.Unix(),
+	}, nil
+}
+
+// ConfirmUpload verifies a presigned upload and starts processing the video
+func (h *VideoServiceHandler) ConfirmUpload(ctx context.Context, req *pb.ConfirmUploadRequest) (*pb.VideoResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	parts := make([]dto.CompletedPart, len(req.Parts))
+	for i, part := range req.Parts {
+		parts[i] = dto.CompletedPart{
+			PartNumber: int(part.PartNumber),
+			ETag:       part.Etag,
+		}
+	}
+
+	video, err := h.uploadSessionUseCase.ConfirmUpload(ctx, &dto.ConfirmUploadRequest{
+		UserID:  userID,
+		VideoID: videoID,
+		Parts:   parts,
+	})
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to confirm upload", zap.Error(err))
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoResponse(video), nil
+}
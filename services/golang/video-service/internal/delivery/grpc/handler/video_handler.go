@@ -2,6 +2,8 @@ package handler
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"tiktok-clone/shared/common/errors"
 	"tiktok-clone/shared/common/logger"
@@ -19,13 +21,30 @@ import (
 // VideoServiceHandler implements gRPC video service
 type VideoServiceHandler struct {
 	pb.UnimplementedVideoServiceServer
-	videoUseCase *usecase.VideoUseCase
+	videoUseCase         *usecase.VideoUseCase
+	uploadSessionUseCase *usecase.UploadSessionUseCase
+	transcodingUseCase   *usecase.TranscodingUseCase
+	trendingUseCase      *usecase.TrendingUseCase
+	viewUseCase          *usecase.ViewUseCase
+	shareUseCase         *usecase.ShareUseCase
 }
 
 // NewVideoServiceHandler creates a new video service handler
-func NewVideoServiceHandler(videoUseCase *usecase.VideoUseCase) *VideoServiceHandler {
+func NewVideoServiceHandler(
+	videoUseCase *usecase.VideoUseCase,
+	uploadSessionUseCase *usecase.UploadSessionUseCase,
+	transcodingUseCase *usecase.TranscodingUseCase,
+	trendingUseCase *usecase.TrendingUseCase,
+	viewUseCase *usecase.ViewUseCase,
+	shareUseCase *usecase.ShareUseCase,
+) *VideoServiceHandler {
 	return &VideoServiceHandler{
-		videoUseCase: videoUseCase,
+		videoUseCase:         videoUseCase,
+		uploadSessionUseCase: uploadSessionUseCase,
+		transcodingUseCase:   transcodingUseCase,
+		trendingUseCase:      trendingUseCase,
+		viewUseCase:          viewUseCase,
+		shareUseCase:         shareUseCase,
 	}
 }
 
@@ -34,26 +53,20 @@ func (h *VideoServiceHandler) UploadVideo(ctx context.Context, req *pb.UploadVid
 	log := logger.ForContext(ctx)
 
 	// Get user ID from context (set by auth middleware)
-	userIDStr, err := middleware.GetUserIDFromContext(ctx)
-	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "unauthorized")
-	}
-
-	userID, err := uuid.Parse(userIDStr)
+	userID, err := userIDFromContext(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+		return nil, err
 	}
 
 	// Create use case request
 	uploadReq := &dto.UploadVideoRequest{
-		UserID:          userID,
-		Title:           req.Title,
-		Description:     req.Description,
-		VideoData:       req.VideoData,
-		ThumbnailData:   req.ThumbnailData,
-		DurationSeconds: int(req.DurationSeconds),
-		Width:           int(req.Width),
-		Height:          int(req.Height),
+		UserID:        userID,
+		Region:        middleware.GetRegionFromContext(ctx),
+		Title:         req.Title,
+		Description:   req.Description,
+		Tags:          req.Tags,
+		VideoData:     req.VideoData,
+		ThumbnailData: req.ThumbnailData,
 	}
 
 	// Execute use case
@@ -66,14 +79,82 @@ func (h *VideoServiceHandler) UploadVideo(ctx context.Context, req *pb.UploadVid
 	return h.toProtoVideoResponse(video), nil
 }
 
-// GetVideo retrieves a video by ID
+// UploadVideoStream handles a client-streaming video upload.
+// The first message must carry the metadata, the following ones the video bytes.
+func (h *VideoServiceHandler) UploadVideoStream(stream pb.VideoService_UploadVideoStreamServer) error {
+	ctx := stream.Context()
+	log := logger.ForContext(ctx)
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		if err == io.EOF {
+			return status.Error(codes.InvalidArgument, "missing upload metadata")
+		}
+		return err
+	}
+	if first.Metadata == nil {
+		return status.Error(codes.InvalidArgument, "first message must carry upload metadata")
+	}
+
+	uploadReq := &dto.UploadVideoStreamRequest{
+		UserID:        userID,
+		Region:        middleware.GetRegionFromContext(ctx),
+		Title:         first.Metadata.Title,
+		Description:   first.Metadata.Description,
+		Tags:          first.Metadata.Tags,
+		ThumbnailData: first.Metadata.ThumbnailData,
+		FileSize:      first.Metadata.FileSize,
+	}
+
+	// Pipe incoming chunks into the use case while it uploads them to storage
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(receiveChunks(stream, pw))
+	}()
+
+	video, err := h.videoUseCase.UploadVideoStream(ctx, uploadReq, pr)
+	// Unblock the receiving goroutine if the use case stopped reading early
+	pr.Close()
+	if err != nil {
+		log.Error("Failed to upload video stream", zap.Error(err))
+		return errors.ToGRPCCode(err)
+	}
+
+	return stream.SendAndClose(h.toProtoVideoResponse(video))
+}
+
+// receiveChunks copies chunk messages from the stream into w until the client closes the stream
+func receiveChunks(stream pb.VideoService_UploadVideoStreamServer, w io.Writer) error {
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Metadata != nil {
+			return status.Error(codes.InvalidArgument, "upload metadata may only be sent once")
+		}
+		if _, err := w.Write(msg.Chunk); err != nil {
+			return err
+		}
+	}
+}
+
+// GetVideo retrieves a video by ID. Anonymous callers only see public videos.
 func (h *VideoServiceHandler) GetVideo(ctx context.Context, req *pb.GetVideoRequest) (*pb.VideoResponse, error) {
 	videoID, err := uuid.Parse(req.VideoId)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
 	}
 
-	video, err := h.videoUseCase.GetVideo(ctx, videoID)
+	video, err := h.videoUseCase.GetVideo(ctx, videoID, viewerFromContext(ctx))
 	if err != nil {
 		return nil, errors.ToGRPCCode(err)
 	}
@@ -81,31 +162,214 @@ func (h *VideoServiceHandler) GetVideo(ctx context.Context, req *pb.GetVideoRequ
 	return h.toProtoVideoResponse(video), nil
 }
 
-// GetUserVideos retrieves videos by user ID
+// GetUserVideos retrieves the videos of a user the caller may watch
 func (h *VideoServiceHandler) GetUserVideos(ctx context.Context, req *pb.GetUserVideosRequest) (*pb.GetUserVideosResponse, error) {
 	userID, err := uuid.Parse(req.UserId)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
 	}
 
-	videos, err := h.videoUseCase.GetUserVideos(ctx, userID, int(req.Limit), int(req.Offset))
+	page, err := h.videoUseCase.GetUserVideos(ctx, userID, viewerFromContext(ctx), req.Cursor, int(req.Limit))
 	if err != nil {
 		return nil, errors.ToGRPCCode(err)
 	}
 
-	protoVideos := make([]*pb.VideoResponse, len(videos))
-	for i, video := range videos {
-		protoVideos[i] = h.toProtoVideoResponse(video)
+	return h.toProtoVideoListResponse(page), nil
+}
+
+// ListMyVideos retrieves the caller's own videos, whatever their visibility and status
+func (h *VideoServiceHandler) ListMyVideos(ctx context.Context, req *pb.ListMyVideosRequest) (*pb.GetUserVideosResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return &pb.GetUserVideosResponse{
-		Videos: protoVideos,
-		Total:  int32(len(videos)),
+	listReq := &dto.ListMyVideosRequest{
+		Visibilities: req.Visibilities,
+		Statuses:     req.Statuses,
+		Sort:         req.Sort,
+		Cursor:       req.Cursor,
+		Limit:        int(req.Limit),
+	}
+	if req.CreatedAfter > 0 {
+		from := time.Unix(req.CreatedAfter, 0)
+		listReq.CreatedFrom = &from
+	}
+	if req.CreatedBefore > 0 {
+		to := time.Unix(req.CreatedBefore, 0)
+		listReq.CreatedTo = &to
+	}
+
+	page, err := h.videoUseCase.ListMyVideos(ctx, listReq, viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoListResponse(page), nil
+}
+
+// PinVideo pins a video to the top of its owner's grid
+func (h *VideoServiceHandler) PinVideo(ctx context.Context, req *pb.PinVideoRequest) (*pb.VideoResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	video, err := h.videoUseCase.PinVideo(ctx, videoID, viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoResponse(video), nil
+}
+
+// UnpinVideo unpins a video
+func (h *VideoServiceHandler) UnpinVideo(ctx context.Context, req *pb.PinVideoRequest) (*pb.VideoResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	video, err := h.videoUseCase.UnpinVideo(ctx, videoID, viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoResponse(video), nil
+}
+
+// ListLikedVideos retrieves the caller's liked videos
+func (h *VideoServiceHandler) ListLikedVideos(ctx context.Context, req *pb.ListLikedVideosRequest) (*pb.GetUserVideosResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.videoUseCase.ListLikedVideos(ctx, req.Cursor, int(req.Limit), viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return h.toProtoVideoListResponse(page), nil
+}
+
+// LikeVideo makes the caller like a video
+func (h *VideoServiceHandler) LikeVideo(ctx context.Context, req *pb.LikeVideoRequest) (*pb.LikeVideoResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	like, err := h.videoUseCase.LikeVideo(ctx, videoID, viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return &pb.LikeVideoResponse{IsLiked: like.IsLiked, LikesCount: like.LikeCount}, nil
+}
+
+// UnlikeVideo removes the caller's like of a video
+func (h *VideoServiceHandler) UnlikeVideo(ctx context.Context, req *pb.LikeVideoRequest) (*pb.LikeVideoResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	like, err := h.videoUseCase.UnlikeVideo(ctx, videoID, viewer)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return &pb.LikeVideoResponse{IsLiked: like.IsLiked, LikesCount: like.LikeCount}, nil
+}
+
+// GetVideoStats retrieves the counts of a video
+func (h *VideoServiceHandler) GetVideoStats(ctx context.Context, req *pb.GetVideoStatsRequest) (*pb.VideoStatsResponse, error) {
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	stats, err := h.videoUseCase.GetVideoStats(ctx, videoID, viewerFromContext(ctx))
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return &pb.VideoStatsResponse{
+		ViewsCount:    stats.ViewCount,
+		LikesCount:    stats.LikeCount,
+		CommentsCount: stats.CommentCount,
+		SharesCount:   stats.ShareCount,
+		IsLiked:       stats.IsLiked,
+	}, nil
+}
+
+// ShareVideo records a share of a video and returns its short link
+func (h *VideoServiceHandler) ShareVideo(ctx context.Context, req *pb.ShareVideoRequest) (*pb.ShareVideoResponse, error) {
+	videoID, err := uuid.Parse(req.VideoId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
+	}
+
+	share, err := h.shareUseCase.ShareVideo(ctx, &dto.ShareVideoRequest{
+		VideoID:  videoID,
+		Channel:  req.Channel,
+		Download: req.Download,
+	}, viewerFromContext(ctx))
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return &pb.ShareVideoResponse{
+		Code:            share.Code,
+		ShareUrl:        share.ShareURL,
+		SharesCount:     share.ShareCount,
+		DownloadUrl:     share.DownloadURL,
+		DownloadPending: share.DownloadPending,
+	}, nil
+}
+
+// ResolveShareLink counts a click on a share link and returns where it redirects to
+func (h *VideoServiceHandler) ResolveShareLink(ctx context.Context, req *pb.ResolveShareLinkRequest) (*pb.ResolveShareLinkResponse, error) {
+	link, err := h.shareUseCase.ResolveShareLink(ctx, req.Code)
+	if err != nil {
+		return nil, errors.ToGRPCCode(err)
+	}
+
+	return &pb.ResolveShareLinkResponse{
+		VideoId:     link.VideoID,
+		RedirectUrl: link.RedirectURL,
+		ClickCount:  link.ClickCount,
 	}, nil
 }
 
 // UpdateVideo updates video metadata
 func (h *VideoServiceHandler) UpdateVideo(ctx context.Context, req *pb.UpdateVideoRequest) (*pb.VideoResponse, error) {
+	viewer, err := authenticatedViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	videoID, err := uuid.Parse(req.VideoId)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
@@ -124,13 +388,25 @@ func (h *VideoServiceHandler) UpdateVideo(ctx context.Context, req *pb.UpdateVid
 	if req.IsPublic != nil {
 		updateReq.IsPublic = &req.IsPublic.Value
 	}
+	if req.Visibility != nil {
+		updateReq.Visibility = &req.Visibility.Value
+	}
+	if req.Category != nil {
+		updateReq.Category = &req.Category.Value
+	}
+	if req.AllowDownload != nil {
+		updateReq.AllowDownload = &req.AllowDownload.Value
+	}
+	if req.Tags != nil {
+		updateReq.Tags = &req.Tags.Tags
+	}
 
-	if err := h.videoUseCase.UpdateVideo(ctx, updateReq); err != nil {
+	if err := h.videoUseCase.UpdateVideo(ctx, updateReq, viewer); err != nil {
 		return nil, errors.ToGRPCCode(err)
 	}
 
 	// Get updated video
-	video, err := h.videoUseCase.GetVideo(ctx, videoID)
+	video, err := h.videoUseCase.GetVideo(ctx, videoID, viewer)
 	if err != nil {
 		return nil, errors.ToGRPCCode(err)
 	}
@@ -145,56 +421,157 @@ func (h *VideoServiceHandler) DeleteVideo(ctx context.Context, req *pb.DeleteVid
 		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
 	}
 
-	userIDStr, err := middleware.GetUserIDFromContext(ctx)
-	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "unauthorized")
-	}
-
-	userID, err := uuid.Parse(userIDStr)
+	viewer, err := authenticatedViewer(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+		return nil, err
 	}
 
-	if err := h.videoUseCase.DeleteVideo(ctx, videoID, userID); err != nil {
+	if err := h.videoUseCase.DeleteVideo(ctx, videoID, viewer); err != nil {
 		return nil, errors.ToGRPCCode(err)
 	}
 
 	return &pb.DeleteVideoResponse{Success: true}, nil
 }
 
-// GetTrendingVideos retrieves trending videos
+// GetTrendingVideos retrieves a page of a trending list, by default of the caller's region
 func (h *VideoServiceHandler) GetTrendingVideos(ctx context.Context, req *pb.GetTrendingVideosRequest) (*pb.GetTrendingVideosResponse, error) {
-	videos, err := h.videoUseCase.GetTrendingVideos(ctx, int(req.Limit))
+	trendingReq := &dto.TrendingVideosRequest{
+		ClientRegion: middleware.GetRegionFromContext(ctx),
+		Category:     req.Category,
+		Hashtag:      req.Hashtag,
+		Window:       req.Window,
+		Cursor:       req.Cursor,
+		Limit:        int(req.Limit),
+	}
+	if req.Region != "" {
+		region, ok := middleware.ParseRegion(req.Region)
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "invalid region")
+		}
+		trendingReq.Region = region
+	}
+
+	page, err := h.trendingUseCase.GetTrendingVideos(ctx, trendingReq, viewerFromContext(ctx))
 	if err != nil {
 		return nil, errors.ToGRPCCode(err)
 	}
 
-	protoVideos := make([]*pb.VideoResponse, len(videos))
-	for i, video := range videos {
+	protoVideos := make([]*pb.VideoResponse, len(page.Videos))
+	for i, video := range page.Videos {
 		protoVideos[i] = h.toProtoVideoResponse(video)
 	}
 
 	return &pb.GetTrendingVideosResponse{
-		Videos: protoVideos,
+		Videos:     protoVideos,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+		Region:     page.Region,
 	}, nil
 }
 
-// RecordView records a video view
+// RecordView records a view event reported by a player
 func (h *VideoServiceHandler) RecordView(ctx context.Context, req *pb.RecordViewRequest) (*pb.RecordViewResponse, error) {
 	videoID, err := uuid.Parse(req.VideoId)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid video ID")
 	}
 
-	if err := h.videoUseCase.RecordView(ctx, videoID); err != nil {
+	counted, err := h.viewUseCase.RecordView(ctx, &dto.RecordViewRequest{
+		VideoID:        videoID,
+		WatchDuration:  time.Duration(req.WatchDurationMs) * time.Millisecond,
+		CompletionRate: req.CompletionRate,
+		DeviceType:     req.DeviceType,
+		SessionID:      req.SessionId,
+		ClientIP:       middleware.GetClientIPFromContext(ctx),
+		UserAgent:      middleware.GetUserAgentFromContext(ctx),
+	}, viewerFromContext(ctx))
+	if err != nil {
 		return nil, errors.ToGRPCCode(err)
 	}
 
-	return &pb.RecordViewResponse{Success: true}, nil
+	return &pb.RecordViewResponse{Success: true, Counted: counted}, nil
+}
+
+// userIDFromContext returns the authenticated user ID set by the auth middleware
+func userIDFromContext(ctx context.Context) (uuid.UUID, error) {
+	userIDStr, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid user ID")
+	}
+
+	return userID, nil
+}
+
+// viewerFromContext returns the caller set by the auth middleware; anonymous
+// callers have no user ID and no roles
+func viewerFromContext(ctx context.Context) usecase.Viewer {
+	userIDStr, ok := middleware.LookupUserID(ctx)
+	if !ok {
+		return usecase.Viewer{}
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return usecase.Viewer{}
+	}
+	return usecase.Viewer{UserID: userID, Roles: middleware.GetUserRolesFromContext(ctx)}
+}
+
+// authenticatedViewer returns the caller of an endpoint that requires sign-in
+func authenticatedViewer(ctx context.Context) (usecase.Viewer, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return usecase.Viewer{}, err
+	}
+	return usecase.Viewer{UserID: userID, Roles: middleware.GetUserRolesFromContext(ctx)}, nil
+}
+
+// toProtoVideoListResponse converts a list page to protobuf response
+func (h *VideoServiceHandler) toProtoVideoListResponse(page *dto.VideoListResponse) *pb.GetUserVideosResponse {
+	protoVideos := make([]*pb.VideoResponse, len(page.Videos))
+	for i, video := range page.Videos {
+		protoVideos[i] = h.toProtoVideoResponse(video)
+	}
+
+	return &pb.GetUserVideosResponse{
+		Videos:     protoVideos,
+		Total:      int32(page.Total),
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+	}
 }
 
 // toProtoVideoResponse converts DTO to protobuf response
 func (h *VideoServiceHandler) toProtoVideoResponse(video *dto.VideoResponse) *pb.VideoResponse {
+	covers := make([]*pb.CoverImage, len(video.Covers))
+	for i, cover := range video.Covers {
+		covers[i] = &pb.CoverImage{
+			Width:  int32(cover.Width),
+			Format: cover.Format,
+			Url:    cover.URL,
+		}
+	}
+
+	mentions := make([]*pb.MentionSpan, len(video.Mentions))
+	for i, mention := range video.Mentions {
+		mentions[i] = &pb.MentionSpan{
+			UserId:   mention.UserID,
+			Username: mention.Username,
+			Start:    int32(mention.Start),
+			End:      int32(mention.End),
+		}
+	}
+
+	var urlsExpireAt int64
+	if video.URLsExpireAt != nil {
+		urlsExpireAt = video.URLsExpireAt.Unix()
+	}
+
 	return &pb.VideoResponse{
 		VideoId:         video.VideoID,
 		UserId:          video.UserID,
@@ -202,10 +579,22 @@ func (h *VideoServiceHandler) toProtoVideoResponse(video *dto.VideoResponse) *pb
 		Description:     video.Description,
 		VideoUrl:        video.VideoURL,
 		ThumbnailUrl:    video.ThumbnailURL,
+		PlaylistUrl:     video.PlaylistURL,
+		Covers:          covers,
+		PreviewUrl:      video.PreviewURL,
+		StoryboardUrl:   video.StoryboardURL,
+		UrlsExpireAt:    urlsExpireAt,
 		DurationSeconds: int32(video.DurationSeconds),
 		Width:           int32(video.Width),
 		Height:          int32(video.Height),
 		EncodingStatus:  video.EncodingStatus,
+		Visibility:      video.Visibility,
+		IsPinned:        video.IsPinned,
+		Category:        video.Category,
+		Tags:            video.Hashtags,
+		Mentions:        mentions,
+		IsLiked:         video.IsLiked,
+		AllowDownload:   video.AllowDownload,
 		ViewCount:       video.ViewCount,
 		LikeCount:       video.LikeCount,
 		CommentCount:    video.CommentCount,
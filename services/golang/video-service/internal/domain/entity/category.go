@@ -0,0 +1,41 @@
+package entity
+
+import "fmt"
+
+// Category is the topic a creator files a video under
+type Category string
+
+// Categories
+const (
+	CategoryNone      Category = ""
+	CategoryComedy    Category = "comedy"
+	CategoryMusic     Category = "music"
+	CategoryDance     Category = "dance"
+	CategorySports    Category = "sports"
+	CategoryFood      Category = "food"
+	CategoryGaming    Category = "gaming"
+	CategoryEducation Category = "education"
+	CategoryBeauty    Category = "beauty"
+	CategoryPets      Category = "pets"
+	CategoryTravel    Category = "travel"
+	CategoryNews      Category = "news"
+)
+
+// categories lists every category a client may set
+var categories = []Category{
+	CategoryComedy, CategoryMusic, CategoryDance, CategorySports, CategoryFood, CategoryGaming,
+	CategoryEducation, CategoryBeauty, CategoryPets, CategoryTravel, CategoryNews,
+}
+
+// ParseCategory validates a category sent by a client; "" clears it
+func ParseCategory(value string) (Category, error) {
+	if value == "" {
+		return CategoryNone, nil
+	}
+	for _, c := range categories {
+		if Category(value) == c {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown category %q", value)
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// ContentObject entity - an original file and its renditions, stored once and
+// shared by every video uploaded with the same bytes
+type ContentObject struct {
+	ContentSHA256    string `gorm:"column:content_sha256;type:char(64);primary_key"`
+	OriginalKey      string `gorm:"type:varchar(500);not null"`
+	RenditionsPrefix string `gorm:"type:varchar(500)"`  // Empty until the content is transcoded
+	RefCount         int    `gorm:"not null;default:0"` // Number of videos using the content
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// TableName specifies the table name
+func (ContentObject) TableName() string {
+	return "content_objects"
+}
+
+// IsTranscoded checks if renditions of the content can be reused
+func (c *ContentObject) IsTranscoded() bool {
+	return c.RenditionsPrefix != ""
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EncodingStatus is the lifecycle state of a video
+type EncodingStatus string
+
+// Encoding statuses
+const (
+	// EncodingStatusPendingUpload - created, waiting for the file (presigned uploads)
+	EncodingStatusPendingUpload EncodingStatus = "pending_upload"
+	// EncodingStatusUploaded - the original file is in storage
+	EncodingStatusUploaded EncodingStatus = "uploaded"
+	// EncodingStatusQueued - waiting for a transcoding worker, also between retries
+	EncodingStatusQueued      EncodingStatus = "queued"
+	EncodingStatusTranscoding EncodingStatus = "transcoding"
+	// EncodingStatusModerating - transcoded, waiting for content review
+	EncodingStatusModerating EncodingStatus = "moderating"
+	// EncodingStatusReady - playable
+	EncodingStatusReady  EncodingStatus = "ready"
+	EncodingStatusFailed EncodingStatus = "failed"
+	// EncodingStatusRemoved - taken down; final
+	EncodingStatusRemoved EncodingStatus = "removed"
+)
+
+// ErrInvalidStatusTransition is returned for a transition not in the transition table
+var ErrInvalidStatusTransition = errors.New("invalid encoding status transition")
+
+// encodingStatusTransitions lists the statuses each status may move to
+var encodingStatusTransitions = map[EncodingStatus][]EncodingStatus{
+	EncodingStatusPendingUpload: {EncodingStatusUploaded, EncodingStatusRemoved},
+	EncodingStatusUploaded:      {EncodingStatusQueued, EncodingStatusFailed, EncodingStatusRemoved},
+	EncodingStatusQueued:        {EncodingStatusTranscoding, EncodingStatusFailed, EncodingStatusRemoved},
+	EncodingStatusTranscoding:   {EncodingStatusQueued, EncodingStatusModerating, EncodingStatusReady, EncodingStatusFailed, EncodingStatusRemoved},
+	EncodingStatusModerating:    {EncodingStatusReady, EncodingStatusFailed, EncodingStatusRemoved},
+	EncodingStatusReady:         {EncodingStatusRemoved},
+	EncodingStatusFailed:        {EncodingStatusQueued, EncodingStatusRemoved},
+	EncodingStatusRemoved:       {},
+}
+
+// ParseEncodingStatus validates an encoding status sent by a client
+func ParseEncodingStatus(value string) (EncodingStatus, error) {
+	if _, ok := encodingStatusTransitions[EncodingStatus(value)]; !ok {
+		return "", fmt.Errorf("unknown encoding status %q", value)
+	}
+	return EncodingStatus(value), nil
+}
+
+// CanTransitionTo checks if the transition table allows moving from s to next
+func (s EncodingStatus) CanTransitionTo(next EncodingStatus) bool {
+	for _, allowed := range encodingStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns ErrInvalidStatusTransition if s cannot move to next
+func (s EncodingStatus) ValidateTransition(next EncodingStatus) error {
+	if !s.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, s, next)
+	}
+	return nil
+}
+
+// VideoStatusHistory entity - one recorded encoding status transition
+type VideoStatusHistory struct {
+	ID         int64          `gorm:"primary_key;autoIncrement"`
+	VideoID    uuid.UUID      `gorm:"type:uuid;not null;index"`
+	FromStatus EncodingStatus `gorm:"type:varchar(20);not null"`
+	ToStatus   EncodingStatus `gorm:"type:varchar(20);not null"`
+	Reason     string         `gorm:"type:text"`
+	CreatedAt  time.Time
+}
+
+// TableName specifies the table name
+func (VideoStatusHistory) TableName() string {
+	return "video_status_history"
+}
@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+var allEncodingStatuses = []EncodingStatus{
+	EncodingStatusPendingUpload,
+	EncodingStatusUploaded,
+	EncodingStatusQueued,
+	EncodingStatusTranscoding,
+	EncodingStatusModerating,
+	EncodingStatusReady,
+	EncodingStatusFailed,
+	EncodingStatusRemoved,
+}
+
+func TestEncodingStatusCanTransitionTo(t *testing.T) {
+	// Every allowed transition; any other pair, including staying put, is rejected
+	allowed := map[[2]EncodingStatus]bool{
+		{EncodingStatusPendingUpload, EncodingStatusUploaded}: true,
+		{EncodingStatusPendingUpload, EncodingStatusRemoved}:  true,
+
+		{EncodingStatusUploaded, EncodingStatusQueued}:  true,
+		{EncodingStatusUploaded, EncodingStatusFailed}:  true,
+		{EncodingStatusUploaded, EncodingStatusRemoved}: true,
+
+		{EncodingStatusQueued, EncodingStatusTranscoding}: true,
+		{EncodingStatusQueued, EncodingStatusFailed}:      true,
+		{EncodingStatusQueued, EncodingStatusRemoved}:     true,
+
+		// Back to queued for a retry
+		{EncodingStatusTranscoding, EncodingStatusQueued}:     true,
+		{EncodingStatusTranscoding, EncodingStatusModerating}: true,
+		{EncodingStatusTranscoding, EncodingStatusReady}:      true,
+		{EncodingStatusTranscoding, EncodingStatusFailed}:     true,
+		{EncodingStatusTranscoding, EncodingStatusRemoved}:    true,
+
+		{EncodingStatusModerating, EncodingStatusReady}:   true,
+		{EncodingStatusModerating, EncodingStatusFailed}:  true,
+		{EncodingStatusModerating, EncodingStatusRemoved}: true,
+
+		{EncodingStatusReady, EncodingStatusRemoved}: true,
+
+		{EncodingStatusFailed, EncodingStatusQueued}:  true,
+		{EncodingStatusFailed, EncodingStatusRemoved}: true,
+	}
+
+	for _, from := range allEncodingStatuses {
+		for _, to := range allEncodingStatuses {
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				want := allowed[[2]EncodingStatus{from, to}]
+				if got := from.CanTransitionTo(to); got != want {
+					t.Errorf("CanTransitionTo() = %v, want %v", got, want)
+				}
+
+				err := from.ValidateTransition(to)
+				if want && err != nil {
+					t.Errorf("ValidateTransition() error = %v, want nil", err)
+				}
+				if !want && !errors.Is(err, ErrInvalidStatusTransition) {
+					t.Errorf("ValidateTransition() error = %v, want ErrInvalidStatusTransition", err)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodingStatusCanTransitionToUnknown(t *testing.T) {
+	for _, status := range allEncodingStatuses {
+		if status.CanTransitionTo("processing") || EncodingStatus("completed").CanTransitionTo(status) {
+			t.Errorf("transition between %s and a pre-lifecycle status allowed", status)
+		}
+	}
+}
+
+func TestParseEncodingStatus(t *testing.T) {
+	for _, status := range allEncodingStatuses {
+		if got, err := ParseEncodingStatus(string(status)); got != status || err != nil {
+			t.Errorf("ParseEncodingStatus(%q) = %q, %v", status, got, err)
+		}
+	}
+	for _, value := range []string{"", "processing", "completed", "READY"} {
+		if _, err := ParseEncodingStatus(value); err == nil {
+			t.Errorf("ParseEncodingStatus(%q) error = nil, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"encoding/hex"
+	"math/bits"
+)
+
+// FingerprintFrames is the number of evenly spaced frames a perceptual fingerprint samples
+const FingerprintFrames = 16
+
+// FingerprintFrameSize is the number of bytes a sampled frame contributes to a fingerprint
+const FingerprintFrameSize = 8
+
+// A perceptual fingerprint is the hex encoding of one 64-bit difference hash
+// (dHash) per sampled frame. Re-encoded, resized or lightly edited copies of a
+// video keep most of their hash bits, unlike the SHA-256 of the file.
+
+// FingerprintDistance returns the mean number of differing bits per frame
+// between two fingerprints, from 0 (identical) to 64. ok is false if the
+// fingerprints are malformed or sampled a different number of frames.
+func FingerprintDistance(a, b string) (distance int, ok bool) {
+	x, errA := hex.DecodeString(a)
+	y, errB := hex.DecodeString(b)
+	if errA != nil || errB != nil || len(x) == 0 || len(x) != len(y) || len(x)%FingerprintFrameSize != 0 {
+		return 0, false
+	}
+
+	total := 0
+	for i := range x {
+		total += bits.OnesCount8(x[i] ^ y[i])
+	}
+	return total / (len(x) / FingerprintFrameSize), true
+}
@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxHashtagLength is the longest hashtag kept, in runes
+const MaxHashtagLength = 100
+
+// MaxVideoHashtags is how many hashtags a video keeps, description ones first
+const MaxVideoHashtags = 30
+
+// Hashtag is a hashtag used by at least one video
+type Hashtag struct {
+	HashtagID  uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name       string    `gorm:"type:varchar(100);uniqueIndex;not null"`
+	UsageCount int64     `gorm:"default:0"` // videos tagged with it
+	CreatedAt  time.Time
+}
+
+// TableName specifies the table name
+func (Hashtag) TableName() string {
+	return "hashtags"
+}
+
+// VideoTag is a hashtag of a video, from its description or set explicitly
+type VideoTag struct {
+	VideoID  uuid.UUID
+	Name     string
+	Explicit bool // set as a tag rather than found in the description
+}
+
+// ExtractHashtags returns the distinct hashtags of text, lowercased and
+// without the leading '#', in order of appearance. A hashtag is made of
+// letters, combining marks, digits and underscores of any script. Text is normalized to NFC
+// first, so precomposed and decomposed accents, as typed by different
+// Vietnamese keyboards, give the same hashtag.
+func ExtractHashtags(text string) []string {
+	var hashtags []string
+	seen := map[string]bool{}
+
+	runes := []rune(norm.NFC.String(text))
+	for i := 0; i < len(runes); i++ {
+		// A '#' inside a word, as in "C#", does not start a hashtag
+		if runes[i] != '#' || (i > 0 && isHashtagRune(runes[i-1])) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isHashtagRune(runes[j]) {
+			j++
+		}
+		if tag := strings.ToLower(string(runes[i+1 : j])); tag != "" && len([]rune(tag)) <= MaxHashtagLength && !seen[tag] {
+			seen[tag] = true
+			hashtags = append(hashtags, tag)
+		}
+		i = j - 1
+	}
+	return hashtags
+}
+
+// NormalizeHashtag returns a hashtag named by a client, with or without the
+// leading '#', the way ExtractHashtags returns it, and false if value is not
+// exactly one hashtag
+func NormalizeHashtag(value string) (string, bool) {
+	value = strings.ToLower(norm.NFC.String(strings.TrimPrefix(value, "#")))
+	tags := ExtractHashtags("#" + value)
+	if len(tags) != 1 || tags[0] != value {
+		return "", false
+	}
+	return tags[0], true
+}
+
+// NormalizeHashtagPrefix returns the start of a hashtag typed by a client the
+// way ExtractHashtags returns hashtags, and false if it cannot start one
+func NormalizeHashtagPrefix(value string) (string, bool) {
+	value = strings.ToLower(norm.NFC.String(strings.TrimPrefix(value, "#")))
+	if value == "" || len([]rune(value)) > MaxHashtagLength {
+		return "", false
+	}
+	for _, r := range value {
+		if !isHashtagRune(r) {
+			return "", false
+		}
+	}
+	return value, true
+}
+
+// VideoTags merges the hashtags of a description with the tags set
+// explicitly, keeping the first MaxVideoHashtags. Tags that are not valid
+// hashtags are reported with false.
+func VideoTags(videoID uuid.UUID, description string, explicit []string) ([]*VideoTag, bool) {
+	var tags []*VideoTag
+	byName := map[string]*VideoTag{}
+	for _, name := range ExtractHashtags(description) {
+		tag := &VideoTag{VideoID: videoID, Name: name}
+		byName[name] = tag
+		tags = append(tags, tag)
+	}
+	for _, value := range explicit {
+		name, ok := NormalizeHashtag(value)
+		if !ok {
+			return nil, false
+		}
+		if tag, ok := byName[name]; ok {
+			tag.Explicit = true
+			continue
+		}
+		tag := &VideoTag{VideoID: videoID, Name: name, Explicit: true}
+		byName[name] = tag
+		tags = append(tags, tag)
+	}
+
+	if len(tags) > MaxVideoHashtags {
+		tags = tags[:MaxVideoHashtags]
+	}
+	return tags, true
+}
+
+// isHashtagRune checks if r may appear in a hashtag. Combining marks, spacing
+// ones included (Hindi vowel signs), belong to the letter before them.
+func isHashtagRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc)
+}
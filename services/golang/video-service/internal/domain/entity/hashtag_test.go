@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	vietNFC = "Vi\u1ec7t"        // precomposed ệ
+	vietNFD = "Vie\u0323\u0302t" // e, dot below, circumflex
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"no hashtags", "just a video", nil},
+		{"single", "hello #world", []string{"world"}},
+		{"order of appearance", "#b then #a", []string{"b", "a"}},
+		{"digits and underscores", "#go_1 #2024", []string{"go_1", "2024"}},
+
+		{"NFC", "#" + vietNFC, []string{"vi\u1ec7t"}},
+		{"NFD", "#" + vietNFD, []string{"vi\u1ec7t"}},
+		{"NFC and NFD are one hashtag", "#" + vietNFC + " #" + vietNFD, []string{"vi\u1ec7t"}},
+		{"combining mark composed", "#cafe\u0301", []string{"caf\u00e9"}},
+		{"uncomposable combining mark kept", "#a\u0316b", []string{"a\u0316b"}},
+		{"spacing combining mark kept", "#\u0939\u093f\u0902\u0926\u0940", []string{"\u0939\u093f\u0902\u0926\u0940"}},
+		{"other scripts", "#東京 #Москва", []string{"東京", "москва"}},
+
+		{"lowercased", "#GoLang", []string{"golang"}},
+		{"accented capitals lowercased", "#VI\u1ec6T", []string{"vi\u1ec7t"}},
+		{"case variants are one hashtag", "#Go #GO #go", []string{"go"}},
+
+		{"max length", "#" + strings.Repeat("a", MaxHashtagLength), []string{strings.Repeat("a", MaxHashtagLength)}},
+		{"too long dropped", "#" + strings.Repeat("a", MaxHashtagLength+1) + " #ok", []string{"ok"}},
+		{"length counted in runes", "#" + strings.Repeat("\u1ec7", MaxHashtagLength), []string{strings.Repeat("\u1ec7", MaxHashtagLength)}},
+		{"length counted after NFC", "#" + strings.Repeat("e\u0323\u0302", MaxHashtagLength), []string{strings.Repeat("\u1ec7", MaxHashtagLength)}},
+
+		{"comma and period end", "#go, #rust.", []string{"go", "rust"}},
+		{"hyphen ends", "#go-lang", []string{"go"}},
+		{"apostrophe ends", "#rock'n'roll", []string{"rock"}},
+		{"emoji ends", "#party🎉", []string{"party"}},
+		{"inside parentheses", "(#go)", []string{"go"}},
+		{"line breaks", "#a\n#b\t#c", []string{"a", "b", "c"}},
+		{"hash inside word", "C# and F#", nil},
+		{"adjacent hashtags", "#go#rust", []string{"go"}},
+		{"double hash", "##go", []string{"go"}},
+		{"bare hash", "# alone", nil},
+		{"hash then punctuation", "#!", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractHashtags(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractHashtags(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"with hash", "#Go", "go", true},
+		{"without hash", "go", "go", true},
+		{"NFC", vietNFC, "vi\u1ec7t", true},
+		{"NFD", "#" + vietNFD, "vi\u1ec7t", true},
+		{"max length", strings.Repeat("a", MaxHashtagLength), strings.Repeat("a", MaxHashtagLength), true},
+
+		{"empty", "", "", false},
+		{"bare hash", "#", "", false},
+		{"double hash", "##go", "", false},
+		{"two words", "go lang", "", false},
+		{"two hashtags", "#go #rust", "", false},
+		{"trailing punctuation", "go!", "", false},
+		{"too long", strings.Repeat("a", MaxHashtagLength+1), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeHashtag(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizeHashtag(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNormalizeHashtagPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"with hash", "#Vi", "vi", true},
+		{"without hash", "vi", "vi", true},
+		{"NFD", "Vie\u0323", "vi\u1eb9", true},
+		{"underscore kept for escaping", "go_", "go_", true},
+		{"max length", strings.Repeat("a", MaxHashtagLength), strings.Repeat("a", MaxHashtagLength), true},
+
+		{"empty", "", "", false},
+		{"bare hash", "#", "", false},
+		{"LIKE wildcard", "go%", "", false},
+		{"backslash", `go\`, "", false},
+		{"space", "go lang", "", false},
+		{"too long", strings.Repeat("a", MaxHashtagLength+1), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeHashtagPrefix(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizeHashtagPrefix(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "errors"
+
+// ErrInvalidMedia is returned by media probing for corrupt or unreadable files
+var ErrInvalidMedia = errors.New("invalid media file")
+
+// MediaInfo describes an uploaded video file as measured by the media prober
+type MediaInfo struct {
+	Container  string // mp4, mov, webm, matroska...
+	VideoCodec string
+	AudioCodec string // empty if the file has no audio
+	Duration   float64
+	// Width and Height are the display dimensions, with Rotation already applied
+	Width     int
+	Height    int
+	Rotation  int // clockwise degrees: 0, 90, 180 or 270
+	FrameRate float64
+	Bitrate   int64 // bit/s
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxUsernameLength is the longest username the user service accepts
+const MaxUsernameLength = 50
+
+// MaxVideoMentions is how many distinct users a description may mention;
+// later mentions are left as plain text
+const MaxVideoMentions = 20
+
+// MentionSpan is an @username in a description. Start and End are offsets in
+// runes, End exclusive, covering the '@'.
+type MentionSpan struct {
+	Username string
+	Start    int
+	End      int
+}
+
+// VideoMention is a mention of a user in the description of a video
+type VideoMention struct {
+	VideoID     uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index"` // mentioned user
+	Username    string    `gorm:"type:varchar(50);not null"`
+	StartOffset int       `gorm:"primary_key"`
+	EndOffset   int       `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+// TableName specifies the table name
+func (VideoMention) TableName() string {
+	return "video_mentions"
+}
+
+// MentionEvent tells that a user was mentioned in a video, for notifications
+type MentionEvent struct {
+	VideoID         uuid.UUID `json:"video_id"`
+	AuthorID        uuid.UUID `json:"author_id"`
+	MentionedUserID uuid.UUID `json:"mentioned_user_id"`
+	Username        string    `json:"username"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ExtractMentions returns the @username mentions of text in order, at most
+// MaxVideoMentions distinct usernames. Usernames are made of ASCII letters,
+// digits, dots and underscores, as the user service allows; a trailing dot
+// ends the sentence rather than the username, and an '@' inside a word, as in
+// an email address, does not start a mention.
+func ExtractMentions(text string) []MentionSpan {
+	var spans []MentionSpan
+	seen := map[string]bool{}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && (isUsernameRune(runes[i-1]) || runes[i-1] == '@')) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isUsernameRune(runes[j]) {
+			j++
+		}
+		end := j
+		for end > i+1 && runes[end-1] == '.' {
+			end--
+		}
+		username := string(runes[i+1 : end])
+		if username != "" && len(username) <= MaxUsernameLength {
+			if !seen[username] && len(seen) == MaxVideoMentions {
+				break
+			}
+			seen[username] = true
+			spans = append(spans, MentionSpan{Username: username, Start: i, End: end})
+		}
+		i = j - 1
+	}
+	return spans
+}
+
+// isUsernameRune checks if r may be part of a username
+func isUsernameRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.'
+}
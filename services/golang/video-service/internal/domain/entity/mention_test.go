@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []MentionSpan
+	}{
+		{"no mentions", "just a video", nil},
+		{"single", "hi @alice", []MentionSpan{{"alice", 3, 9}}},
+		{"start of text", "@bob!", []MentionSpan{{"bob", 0, 4}}},
+		{"several", "@alice and @bob_2", []MentionSpan{{"alice", 0, 6}, {"bob_2", 11, 17}}},
+		{"repeated", "@alice @alice", []MentionSpan{{"alice", 0, 6}, {"alice", 7, 13}}},
+		{"dots inside", "@a.b.c", []MentionSpan{{"a.b.c", 0, 6}}},
+		{"trailing dot", "thanks @alice.", []MentionSpan{{"alice", 7, 13}}},
+		{"offsets in runes", "chào @minh", []MentionSpan{{"minh", 5, 10}}},
+		{"emoji before", "🎉🎉 @alice", []MentionSpan{{"alice", 3, 9}}},
+		{"email address", "mail me@example.com", nil},
+		{"double at", "@@alice", nil},
+		{"bare at", "@ alone", nil},
+		{"too long", "@" + strings.Repeat("a", MaxUsernameLength+1), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractMentions(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMentionsLimit(t *testing.T) {
+	var words []string
+	for i := 0; i < MaxVideoMentions; i++ {
+		words = append(words, fmt.Sprintf("@user%d", i))
+	}
+	// A user already mentioned does not count against the limit, a new one past it is dropped
+	words = append(words, "@user0", "@extra")
+
+	spans := ExtractMentions(strings.Join(words, " "))
+	if len(spans) != MaxVideoMentions+1 {
+		t.Fatalf("got %d mentions, want %d", len(spans), MaxVideoMentions+1)
+	}
+	if last := spans[len(spans)-1].Username; last != "user0" {
+		t.Errorf("last mention = %q, want user0", last)
+	}
+}
@@ -0,0 +1,10 @@
+package entity
+
+// ObjectInfo describes a file stored by the storage service
+type ObjectInfo struct {
+	Key            string
+	URL            string
+	Size           int64
+	ContentType    string
+	ChecksumSHA256 string // base64 encoded, empty if storage did not record one
+}
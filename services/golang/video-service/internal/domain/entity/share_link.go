@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareChannel is where a video was shared to
+type ShareChannel string
+
+// Share channels
+const (
+	ShareChannelCopyLink    ShareChannel = "copy_link"
+	ShareChannelMessage     ShareChannel = "message"
+	ShareChannelExternalApp ShareChannel = "external_app"
+)
+
+// ParseShareChannel validates a share channel sent by a client
+func ParseShareChannel(value string) (ShareChannel, error) {
+	switch c := ShareChannel(value); c {
+	case ShareChannelCopyLink, ShareChannelMessage, ShareChannelExternalApp:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unknown share channel %q", value)
+	}
+}
+
+// ShareLink is one share of a video, behind the short link handed to the sharer
+type ShareLink struct {
+	Code       string       `gorm:"type:varchar(16);primary_key"`
+	VideoID    uuid.UUID    `gorm:"type:uuid;not null"`
+	UserID     *uuid.UUID   `gorm:"type:uuid"` // nil for anonymous sharers
+	Channel    ShareChannel `gorm:"type:varchar(20);not null"`
+	Download   bool         // whether the sharer asked for the watermarked file
+	ClickCount int64        `gorm:"default:0"`
+	CreatedAt  time.Time
+}
+
+// TableName specifies the table name
+func (ShareLink) TableName() string {
+	return "share_links"
+}
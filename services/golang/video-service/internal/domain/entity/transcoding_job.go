@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transcoding job statuses
+const (
+	// TranscodingJobPending - waiting for a worker, possibly until RunAfter for a retry
+	TranscodingJobPending = "pending"
+	// TranscodingJobRunning - leased by a worker until LeaseExpiresAt
+	TranscodingJobRunning   = "running"
+	TranscodingJobSucceeded = "succeeded"
+	// TranscodingJobDead - gave up after MaxAttempts
+	TranscodingJobDead = "dead"
+)
+
+// Transcoding job kinds
+const (
+	// TranscodingJobKindTranscode - HLS renditions and generated artwork for a new upload
+	TranscodingJobKindTranscode = "transcode"
+	// TranscodingJobKindCover - re-render the cover images at CoverAtMs
+	TranscodingJobKindCover = "cover"
+	// TranscodingJobKindWatermark - render the watermarked download
+	TranscodingJobKindWatermark = "watermark"
+)
+
+// TranscodingJob entity - a durable request to process one video
+type TranscodingJob struct {
+	JobID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	VideoID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	Kind           string    `gorm:"type:varchar(20);not null;default:'transcode'"`
+	CoverAtMs      *int64    // Cover frame timestamp, set for cover jobs
+	Status         string    `gorm:"type:varchar(20);default:'pending'"`
+	Attempts       int       `gorm:"not null;default:0"`
+	MaxAttempts    int       `gorm:"not null"`
+	LastError      string    `gorm:"type:text"`
+	RunAfter       time.Time `gorm:"not null"`
+	LeaseOwner     *string   `gorm:"type:varchar(255)"`
+	LeaseExpiresAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName specifies the table name
+func (TranscodingJob) TableName() string {
+	return "transcoding_jobs"
+}
+
+// HasAttemptsLeft checks if the job may be retried after the current attempt
+func (j *TranscodingJob) HasAttemptsLeft() bool {
+	return j.Attempts < j.MaxAttempts
+}
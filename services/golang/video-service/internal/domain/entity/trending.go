@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrendingScore entity - the trending score of a video at one run of the
+// trending job, with the counts it was computed from
+type TrendingScore struct {
+	VideoID        uuid.UUID `gorm:"type:uuid;primary_key"`
+	ComputedAt     time.Time `gorm:"primary_key"`
+	Region         string    `gorm:"type:varchar(2)"`
+	ViewCount      int64
+	LikeCount      int64
+	CommentCount   int64
+	ShareCount     int64
+	ViewVelocity   float64 // Views per hour, smoothed
+	EngagementRate float64 // Weighted likes, comments and shares per hour, smoothed
+	CompletionRate float64 // Mean fraction of the video watched, 0-1
+	TrendingScore  float64
+}
+
+// TableName specifies the table name
+func (TrendingScore) TableName() string {
+	return "trending_scores"
+}
+
+// TrendingCandidate is a video the trending job scores, with its current counts
+type TrendingCandidate struct {
+	VideoID        uuid.UUID
+	Region         string
+	Category       Category
+	Hashtags       []string `gorm:"-"` // Stored in video_hashtags
+	CreatedAt      time.Time
+	ViewCount      int64
+	LikeCount      int64
+	CommentCount   int64
+	ShareCount     int64
+	CompletionRate float64 // Mean fraction watched in recent view events, 0 without any
+}
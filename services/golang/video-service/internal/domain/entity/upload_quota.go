@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadQuota entity - what a user uploaded on one UTC day
+type UploadQuota struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primary_key"`
+	Day         time.Time `gorm:"type:date;primary_key"`
+	UploadCount int       `gorm:"not null;default:0"`
+	UploadBytes int64     `gorm:"not null;default:0"`
+	UpdatedAt   time.Time
+}
+
+// TableName specifies the table name
+func (UploadQuota) TableName() string {
+	return "upload_quotas"
+}
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload session statuses
+const (
+	UploadSessionActive     = "active"
+	UploadSessionCompleting = "completing"
+	UploadSessionCompleted  = "completed"
+	UploadSessionExpired    = "expired"
+)
+
+// Upload modes
+const (
+	// UploadModeChunked - parts are sent through the service with UploadChunk
+	UploadModeChunked = "chunked"
+	// UploadModePresigned - the client uploads straight to storage with presigned URLs
+	UploadModePresigned = "presigned"
+)
+
+// UploadSession entity - a resumable upload assembled from numbered parts
+type UploadSession struct {
+	SessionID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID          uuid.UUID `gorm:"type:uuid;not null;index"`
+	VideoID         uuid.UUID `gorm:"type:uuid;not null"`
+	StorageUploadID string    `gorm:"type:varchar(1024)"`
+	Mode            string    `gorm:"type:varchar(20);default:'chunked'"`
+	ChecksumSHA256  string    `gorm:"column:checksum_sha256;type:varchar(64)"`
+	Title           string    `gorm:"type:varchar(255)"`
+	Description     string    `gorm:"type:text"`
+	DurationSeconds int
+	Width           int
+	Height          int
+	TotalSize       int64     `gorm:"not null"`
+	ChunkSize       int64     `gorm:"not null"`
+	Status          string    `gorm:"type:varchar(20);default:'active';index"`
+	ExpiresAt       time.Time `gorm:"not null;index"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// TableName specifies the table name
+func (UploadSession) TableName() string {
+	return "upload_sessions"
+}
+
+// TotalParts returns the number of parts needed to assemble the whole file
+func (s *UploadSession) TotalParts() int {
+	return int((s.TotalSize + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// PartSize returns the exact size expected for the given part number.
+// Every part is ChunkSize bytes except the last one, which holds the remainder.
+func (s *UploadSession) PartSize(partNumber int) int64 {
+	if partNumber < s.TotalParts() {
+		return s.ChunkSize
+	}
+	return s.TotalSize - int64(s.TotalParts()-1)*s.ChunkSize
+}
+
+// IsMultipart checks if the session is backed by a storage multipart upload
+func (s *UploadSession) IsMultipart() bool {
+	return s.StorageUploadID != ""
+}
+
+// IsActive checks if the session still accepts parts
+func (s *UploadSession) IsActive() bool {
+	return s.Status == UploadSessionActive
+}
+
+// IsExpired checks if the session TTL has passed
+func (s *UploadSession) IsExpired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
+
+// UploadPart entity - a part received for an upload session
+type UploadPart struct {
+	SessionID  uuid.UUID `gorm:"type:uuid;primary_key"`
+	PartNumber int       `gorm:"primary_key;autoIncrement:false"`
+	ETag       string    `gorm:"column:etag;type:varchar(255);not null"`
+	Size       int64     `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+// TableName specifies the table name
+func (UploadPart) TableName() string {
+	return "upload_session_parts"
+}
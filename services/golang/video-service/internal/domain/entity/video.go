@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,28 +9,52 @@ import (
 
 // Video entity - Enterprise Business Rules
 type Video struct {
-	VideoID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID          uuid.UUID `gorm:"type:uuid;not null;index"`
-	Title           string    `gorm:"type:varchar(255)"`
-	Description     string    `gorm:"type:text"`
-	VideoURL        string    `gorm:"type:varchar(500);not null"`
-	ThumbnailURL    string    `gorm:"type:varchar(500)"`
-	DurationSeconds int       `gorm:"not null"`
-	Width           int
-	Height          int
-	FileSize        int64
-	EncodingStatus  string     `gorm:"type:varchar(20);default:'processing';index"`
-	ViewCount       int64      `gorm:"default:0;index:idx_view_count"`
-	LikeCount       int64      `gorm:"default:0"`
-	CommentCount    int64      `gorm:"default:0"`
-	ShareCount      int64      `gorm:"default:0"`
-	IsPublic        bool       `gorm:"default:true"`
-	AllowComments   bool       `gorm:"default:true"`
-	AllowDuet       bool       `gorm:"default:true"`
-	AllowStitch     bool       `gorm:"default:true"`
-	OriginalVideoID *uuid.UUID `gorm:"type:uuid"`
-	CreatedAt       time.Time  `gorm:"index:idx_created_at"`
-	UpdatedAt       time.Time
+	VideoID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID           uuid.UUID `gorm:"type:uuid;not null;index"`
+	Region           string    `gorm:"type:varchar(2)"` // Country the video was uploaded from, "" if unknown
+	Title            string    `gorm:"type:varchar(255)"`
+	Description      string    `gorm:"type:text"`
+	Category         Category  `gorm:"type:varchar(20)"`
+	VideoURL         string    `gorm:"type:varchar(500);not null"`
+	ThumbnailURL     string    `gorm:"type:varchar(500)"`
+	VideoKey         string    `gorm:"type:varchar(500)"`                         // Storage key of the original file
+	ContentSHA256    string    `gorm:"column:content_sha256;type:char(64);index"` // SHA-256 of the original file
+	Fingerprint      string    `gorm:"type:varchar(512)"`                         // Perceptual fingerprint, see FingerprintDistance
+	ThumbnailKey     string    `gorm:"type:varchar(500)"`
+	RenditionsPrefix string    `gorm:"type:varchar(500)"` // Storage prefix of the transcoded renditions
+	CoverPrefix      string    `gorm:"type:varchar(500)"` // Storage prefix of the generated cover images
+	CoverAtMs        *int64    // Frame timestamp picked by the creator, nil for the automatic cover
+	PreviewKey       string    `gorm:"type:varchar(500)"` // Animated preview
+	StoryboardKey    string    `gorm:"type:varchar(500)"` // WebVTT index of the scrub sprite sheet
+	DownloadKey      string    `gorm:"type:varchar(500)"` // Watermarked file for downloads, rendered on the first request
+	DurationSeconds  int       `gorm:"not null"`
+	Width            int
+	Height           int
+	FileSize         int64
+	ContentType      string  `gorm:"type:varchar(50)"` // Sniffed from the uploaded bytes
+	Container        string  `gorm:"type:varchar(20)"`
+	VideoCodec       string  `gorm:"type:varchar(20)"`
+	AudioCodec       string  `gorm:"type:varchar(20)"`
+	Rotation         int     `gorm:"default:0"`
+	FrameRate        float64 `gorm:"type:numeric(6,2)"`
+	Bitrate          int64
+	EncodingStatus   EncodingStatus `gorm:"type:varchar(20);default:'uploaded';index"`
+	ViewCount        int64          `gorm:"default:0;index:idx_view_count"`
+	LikeCount        int64          `gorm:"default:0"`
+	CommentCount     int64          `gorm:"default:0"`
+	ShareCount       int64          `gorm:"default:0"`
+	Visibility       Visibility     `gorm:"type:varchar(20);default:'public'"`
+	IsPublic         bool           `gorm:"default:true"` // Visibility == VisibilityPublic, kept for listing queries
+	AllowComments    bool           `gorm:"default:true"`
+	AllowDuet        bool           `gorm:"default:true"`
+	AllowStitch      bool           `gorm:"default:true"`
+	AllowDownload    bool           `gorm:"default:true"` // Whether shares may include the watermarked file
+	OriginalVideoID  *uuid.UUID     `gorm:"type:uuid"`
+	PinnedAt         *time.Time     // Set while pinned to the top of the owner's grid
+	CreatedAt        time.Time      `gorm:"index:idx_created_at"`
+	UpdatedAt        time.Time
+	Hashtags         []string        `gorm:"-"` // Loaded from video_hashtags, in order of appearance
+	Mentions         []*VideoMention `gorm:"-"` // Loaded from video_mentions, in order of appearance
 }
 
 // TableName specifies the table name
@@ -37,26 +62,52 @@ func (Video) TableName() string {
 	return "videos"
 }
 
+// IsPendingUpload checks if the video file has not been uploaded yet
+func (v *Video) IsPendingUpload() bool {
+	return v.EncodingStatus == EncodingStatusPendingUpload
+}
+
 // IsProcessing checks if video is still being processed
 func (v *Video) IsProcessing() bool {
-	return v.EncodingStatus == "processing"
+	switch v.EncodingStatus {
+	case EncodingStatusUploaded, EncodingStatusQueued, EncodingStatusTranscoding, EncodingStatusModerating:
+		return true
+	}
+	return false
 }
 
-// IsCompleted checks if video processing is completed
-func (v *Video) IsCompleted() bool {
-	return v.EncodingStatus == "completed"
+// IsReady checks if video processing is completed and the video can be played
+func (v *Video) IsReady() bool {
+	return v.EncodingStatus == EncodingStatusReady
 }
 
-// MarkAsCompleted marks video as completed
-func (v *Video) MarkAsCompleted() {
-	v.EncodingStatus = "completed"
+// TransitionTo moves the video to status if the transition table allows it
+func (v *Video) TransitionTo(status EncodingStatus) error {
+	if err := v.EncodingStatus.ValidateTransition(status); err != nil {
+		return err
+	}
+	v.EncodingStatus = status
 	v.UpdatedAt = time.Now()
+	return nil
 }
 
-// MarkAsFailed marks video as failed
-func (v *Video) MarkAsFailed() {
-	v.EncodingStatus = "failed"
-	v.UpdatedAt = time.Now()
+// SetVisibility changes who may watch the video
+func (v *Video) SetVisibility(visibility Visibility) {
+	v.Visibility = visibility
+	v.IsPublic = visibility == VisibilityPublic
+}
+
+// ApplyMediaInfo stores the measured properties of the uploaded file
+func (v *Video) ApplyMediaInfo(info *MediaInfo) {
+	v.Container = info.Container
+	v.VideoCodec = info.VideoCodec
+	v.AudioCodec = info.AudioCodec
+	v.DurationSeconds = int(math.Round(info.Duration))
+	v.Width = info.Width
+	v.Height = info.Height
+	v.Rotation = info.Rotation
+	v.FrameRate = info.FrameRate
+	v.Bitrate = info.Bitrate
 }
 
 // IncrementViewCount increments view count
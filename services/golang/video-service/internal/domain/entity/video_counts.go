@@ -0,0 +1,40 @@
+package entity
+
+import "github.com/google/uuid"
+
+// VideoCounts are the engagement counters of a video that are counted in
+// Redis and flushed to the database in batches
+type VideoCounts struct {
+	Views  int64
+	Likes  int64
+	Shares int64
+}
+
+// IsZero checks if no counter is set
+func (c VideoCounts) IsZero() bool {
+	return c == VideoCounts{}
+}
+
+// Counts returns the engagement counters of a video as stored
+func (v *Video) Counts() VideoCounts {
+	return VideoCounts{Views: v.ViewCount, Likes: v.LikeCount, Shares: v.ShareCount}
+}
+
+// SetCounts replaces the engagement counters of a video
+func (v *Video) SetCounts(counts VideoCounts) {
+	v.ViewCount = counts.Views
+	v.LikeCount = counts.Likes
+	v.ShareCount = counts.Shares
+}
+
+// VideoRecount compares the stored counters of a video with the counts
+// recomputed from the like, view and share events
+type VideoRecount struct {
+	VideoID      uuid.UUID
+	ViewCount    int64
+	LikeCount    int64
+	ShareCount   int64
+	CountedViews int64 // video_views events that were counted
+	Likes        int64 // rows in video_likes
+	Shares       int64 // rows in share_links
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VideoLike records that a user liked a video
+type VideoLike struct {
+	LikeID    uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null"`
+	VideoID   uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name
+func (VideoLike) TableName() string {
+	return "video_likes"
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceType is the kind of device a video was watched on
+type DeviceType string
+
+// Device types
+const (
+	DeviceTypeUnknown DeviceType = ""
+	DeviceTypeIOS     DeviceType = "ios"
+	DeviceTypeAndroid DeviceType = "android"
+	DeviceTypeWeb     DeviceType = "web"
+	DeviceTypeTV      DeviceType = "tv"
+	// DeviceTypeOther - any device type clients report that is not listed
+	DeviceTypeOther DeviceType = "other"
+)
+
+// ParseDeviceType maps a device type reported by a client to a known one;
+// unlisted types become DeviceTypeOther
+func ParseDeviceType(value string) DeviceType {
+	switch d := DeviceType(value); d {
+	case DeviceTypeUnknown, DeviceTypeIOS, DeviceTypeAndroid, DeviceTypeWeb, DeviceTypeTV:
+		return d
+	default:
+		return DeviceTypeOther
+	}
+}
+
+// VideoView is one view event reported by a player, kept for analytics
+type VideoView struct {
+	ViewID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	VideoID              uuid.UUID  `gorm:"type:uuid;not null"`
+	UserID               *uuid.UUID `gorm:"type:uuid"` // nil for anonymous viewers
+	SessionID            string     `gorm:"type:varchar(100)"`
+	DeviceType           DeviceType `gorm:"type:varchar(20)"`
+	WatchDurationSeconds int
+	CompletionRate       float64 `gorm:"type:decimal(5,2)"` // Percent of the video watched, 0-100
+	IPAddress            *string `gorm:"type:inet"`
+	UserAgent            string  `gorm:"type:text"`
+	Counted              bool    // Whether the view was added to the view count
+	CreatedAt            time.Time
+}
+
+// TableName specifies the table name
+func (VideoView) TableName() string {
+	return "video_views"
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "fmt"
+
+// Visibility controls who may watch a video
+type Visibility string
+
+// Visibility levels
+const (
+	// VisibilityPublic - anyone, listed in profiles and trending
+	VisibilityPublic Visibility = "public"
+	// VisibilityFollowers - the owner and the users following them
+	VisibilityFollowers Visibility = "followers"
+	// VisibilityPrivate - only the owner
+	VisibilityPrivate Visibility = "private"
+)
+
+// ParseVisibility validates a visibility level sent by a client
+func ParseVisibility(value string) (Visibility, error) {
+	switch v := Visibility(value); v {
+	case VisibilityPublic, VisibilityFollowers, VisibilityPrivate:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown visibility %q", value)
+	}
+}
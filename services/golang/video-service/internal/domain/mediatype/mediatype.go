@@ -0,0 +1,138 @@
+// Package mediatype detects the real type of uploaded files from their leading
+// bytes, so the content type declared by a client is never trusted.
+package mediatype
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Content types accepted for uploads
+const (
+	VideoMP4       = "video/mp4"
+	VideoQuickTime = "video/quicktime"
+	VideoWebM      = "video/webm"
+	ImageJPEG      = "image/jpeg"
+	ImagePNG       = "image/png"
+	ImageWebP      = "image/webp"
+)
+
+// SniffLen is the number of leading bytes Sniff functions look at
+const SniffLen = 512
+
+// QuickTime files without an ftyp box start straight with one of these atoms
+var quickTimeAtoms = [][]byte{[]byte("moov"), []byte("mdat"), []byte("wide"), []byte("free"), []byte("skip"), []byte("pnot")}
+
+// videoBrands are the ftyp brands of the video files accepted, by content type
+var videoBrands = map[string]string{
+	"qt  ": VideoQuickTime,
+	"isom": VideoMP4,
+	"iso2": VideoMP4,
+	"iso4": VideoMP4,
+	"iso5": VideoMP4,
+	"iso6": VideoMP4,
+	"mp41": VideoMP4,
+	"mp42": VideoMP4,
+	"avc1": VideoMP4,
+	"dash": VideoMP4,
+	"M4V ": VideoMP4,
+	"M4VH": VideoMP4,
+	"M4VP": VideoMP4,
+	"MSNV": VideoMP4,
+	"XAVC": VideoMP4,
+}
+
+// imageBrands mark HEIF still images and image sequences (HEIC, AVIF), which
+// share the ftyp box of MP4 but are not videos
+var imageBrands = map[string]bool{
+	"mif1": true, "msf1": true, "miaf": true,
+	"heic": true, "heix": true, "heim": true, "heis": true, "hevc": true, "hevx": true,
+	"avif": true, "avis": true,
+}
+
+// SniffVideo returns the content type of an MP4, QuickTime or WebM file from
+// its first bytes, or false for anything else (including other Matroska files)
+func SniffVideo(header []byte) (string, bool) {
+	if len(header) >= 12 && bytes.Equal(header[4:8], []byte("ftyp")) {
+		return sniffFtyp(header)
+	}
+
+	if len(header) >= 8 {
+		size := binary.BigEndian.Uint32(header[:4])
+		for _, atom := range quickTimeAtoms {
+			if size >= 8 && bytes.Equal(header[4:8], atom) {
+				return VideoQuickTime, true
+			}
+		}
+	}
+
+	// EBML header; the DocType element tells WebM from generic Matroska
+	if bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}) {
+		if bytes.Contains(header[:min(len(header), 64)], []byte{0x42, 0x82, 0x84, 'w', 'e', 'b', 'm'}) {
+			return VideoWebM, true
+		}
+	}
+
+	return "", false
+}
+
+// sniffFtyp returns the content type of a file starting with an ftyp box from
+// its major brand, or else from the first accepted compatible brand. Files
+// with any image brand are rejected.
+func sniffFtyp(header []byte) (string, bool) {
+	end := min(len(header), int(binary.BigEndian.Uint32(header[:4])))
+	brands := []string{string(header[8:12])}
+	// The minor version at 12:16 is followed by the compatible brands
+	for i := 16; i+4 <= end; i += 4 {
+		brands = append(brands, string(header[i:i+4]))
+	}
+
+	for _, brand := range brands {
+		if imageBrands[brand] {
+			return "", false
+		}
+	}
+	for _, brand := range brands {
+		if contentType, ok := videoBrands[brand]; ok {
+			return contentType, true
+		}
+	}
+	return "", false
+}
+
+// SniffImage returns the content type of a JPEG, PNG or WebP image from its
+// first bytes, or false for anything else
+func SniffImage(header []byte) (string, bool) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		return ImageJPEG, true
+	case bytes.HasPrefix(header, []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}):
+		return ImagePNG, true
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WEBP")):
+		return ImageWebP, true
+	}
+	return "", false
+}
+
+// Extension returns the file extension, without the dot, used for a content type
+func Extension(contentType string) string {
+	switch contentType {
+	case VideoQuickTime:
+		return "mov"
+	case VideoWebM:
+		return "webm"
+	case ImageJPEG:
+		return "jpg"
+	case ImagePNG:
+		return "png"
+	case ImageWebP:
+		return "webp"
+	default:
+		return "mp4"
+	}
+}
+
+// Header returns at most the first SniffLen bytes of data
+func Header(data []byte) []byte {
+	return data[:min(len(data), SniffLen)]
+}
@@ -0,0 +1,99 @@
+package mediatype
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// ftyp returns an ftyp box with a major brand and compatible brands
+func ftyp(major string, compatible ...string) []byte {
+	box := make([]byte, 16, 16+4*len(compatible))
+	binary.BigEndian.PutUint32(box, uint32(16+4*len(compatible)))
+	copy(box[4:], "ftyp")
+	copy(box[8:], major)
+	for _, brand := range compatible {
+		box = append(box, brand...)
+	}
+	// The next box follows
+	return append(box, 0, 0, 0, 8, 'f', 'r', 'e', 'e')
+}
+
+// ebml returns an EBML header with a DocType
+func ebml(docType string) []byte {
+	header := []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86, 0x81, 0x01, 0x42, 0x82, byte(0x80 | len(docType))}
+	return append(header, docType...)
+}
+
+func TestSniffVideo(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"isom", ftyp("isom", "isom", "iso2", "avc1", "mp41"), VideoMP4},
+		{"mp42", ftyp("mp42", "mp42", "isom"), VideoMP4},
+		{"mp41", ftyp("mp41"), VideoMP4},
+		{"avc1", ftyp("avc1"), VideoMP4},
+		{"dash", ftyp("dash", "iso6", "mp41"), VideoMP4},
+		{"M4V", ftyp("M4V ", "M4V ", "M4A ", "mp42", "isom"), VideoMP4},
+		{"XAVC", ftyp("XAVC", "XAVC", "mp42", "iso2"), VideoMP4},
+		{"unknown major brand with MP4 compatible brand", ftyp("3gp5", "3gp5", "isom"), VideoMP4},
+		{"QuickTime ftyp", ftyp("qt  ", "qt  "), VideoQuickTime},
+		{"QuickTime moov", []byte{0, 0, 0, 8, 'm', 'o', 'o', 'v'}, VideoQuickTime},
+		{"QuickTime mdat", []byte{0, 0, 0, 8, 'm', 'd', 'a', 't'}, VideoQuickTime},
+		{"QuickTime wide", []byte{0, 0, 0, 8, 'w', 'i', 'd', 'e'}, VideoQuickTime},
+		{"WebM", ebml("webm"), VideoWebM},
+
+		{"HEIC", ftyp("heic", "mif1", "heic"), ""},
+		{"HEIF", ftyp("mif1", "mif1", "heic"), ""},
+		{"HEIF sequence", ftyp("msf1", "msf1", "hevc"), ""},
+		{"AVIF", ftyp("avif", "avif", "mif1", "miaf"), ""},
+		{"AVIF sequence", ftyp("avis", "avis", "msf1", "iso8", "mif1", "miaf"), ""},
+		{"image brand with MP4 compatible brand", ftyp("isom", "isom", "mif1"), ""},
+		{"M4A audio", ftyp("M4A ", "M4A "), ""},
+		{"unknown brand", ftyp("abcd"), ""},
+		{"Canon raw", ftyp("crx ", "crx "), ""},
+		{"Matroska", ebml("matroska"), ""},
+		{"JPEG", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10, 'J', 'F', 'I', 'F', 0, 1}, ""},
+		{"short ftyp", []byte{0, 0, 0, 12, 'f', 't', 'y', 'p', 'i', 's'}, ""},
+		{"short atom", []byte{0, 0, 0, 8, 'm', 'o'}, ""},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SniffVideo(tt.header)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("SniffVideo(%q) = %q, %v, want %q, %v", tt.header, got, ok, tt.want, tt.want != "")
+			}
+		})
+	}
+}
+
+func TestSniffImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"JPEG", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10, 'J', 'F', 'I', 'F', 0, 1}, ImageJPEG},
+		{"PNG", []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n', 0, 0, 0, 0x0D}, ImagePNG},
+		{"WebP", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), ImageWebP},
+
+		{"HEIC", ftyp("heic", "mif1", "heic"), ""},
+		{"AVIF", ftyp("avif", "avif", "mif1", "miaf"), ""},
+		{"WAV", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), ""},
+		{"MP4", ftyp("isom"), ""},
+		{"short WebP", []byte("RIFF\x24\x00"), ""},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SniffImage(tt.header)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("SniffImage(%q) = %q, %v, want %q, %v", tt.header, got, ok, tt.want, tt.want != "")
+			}
+		})
+	}
+}
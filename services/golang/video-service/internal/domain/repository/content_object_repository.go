@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+)
+
+// ContentObjectRepository defines the interface for shared content data access.
+// Every video using a content object holds one reference to it.
+type ContentObjectRepository interface {
+	// Acquire adds a reference to the content, creating it with originalKey if
+	// it does not exist yet. A RefCount of 1 means the caller created it and
+	// must store the original; with more references the original may still be
+	// missing if the upload that created it failed before storing it.
+	Acquire(ctx context.Context, sha256, originalKey string) (*entity.ContentObject, error)
+	GetBySHA256(ctx context.Context, sha256 string) (*entity.ContentObject, error)
+	UpdateRenditions(ctx context.Context, sha256, renditionsPrefix string) error
+	// Release drops a reference and reports true once the content is no longer
+	// used, after deleting it; its objects must then be removed from storage
+	Release(ctx context.Context, sha256 string) (bool, error)
+}
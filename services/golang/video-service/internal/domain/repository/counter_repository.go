@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// CounterRepository defines the interface for flushing and reconciling video counters
+type CounterRepository interface {
+	// ApplyBatch adds a batch of counter deltas to the videos, unless the batch
+	// was applied before, which it reports with false
+	ApplyBatch(ctx context.Context, batchID string, deltas map[uuid.UUID]entity.VideoCounts) (bool, error)
+	// PruneBatches forgets the batches applied before a time
+	PruneBatches(ctx context.Context, before time.Time) (int64, error)
+	// Recount lists the stored and recomputed counts of videos in video_id
+	// order, starting after afterID
+	Recount(ctx context.Context, afterID uuid.UUID, limit int) ([]*entity.VideoRecount, error)
+	// SetCounts overwrites the view, like and share counts of a video
+	SetCounts(ctx context.Context, videoID uuid.UUID, counts entity.VideoCounts) error
+}
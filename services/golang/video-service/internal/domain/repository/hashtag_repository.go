@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// HashtagRepository defines the interface for hashtag data access
+type HashtagRepository interface {
+	// SetVideoHashtags replaces the hashtags of a video with tags, creating
+	// missing hashtags and keeping their usage counts in step
+	SetVideoHashtags(ctx context.Context, videoID uuid.UUID, tags []*entity.VideoTag) error
+	// ListByVideos retrieves the tags of each video, in order of appearance
+	ListByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID][]*entity.VideoTag, error)
+	GetByName(ctx context.Context, name string) (*entity.Hashtag, error)
+	// Suggest lists the used hashtags starting with prefix, most used first
+	Suggest(ctx context.Context, prefix string, limit int) ([]*entity.Hashtag, error)
+	// ListVideos lists the public, ready videos tagged with a hashtag in sort
+	// order (newest or most viewed), starting after the cursor position
+	ListVideos(ctx context.Context, hashtagID uuid.UUID, sort VideoSort, after *pagination.Cursor, limit int) ([]*entity.Video, error)
+	// CountVideos counts the videos ListVideos lists
+	CountVideos(ctx context.Context, hashtagID uuid.UUID) (int64, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// MentionRepository defines the interface for video mention data access
+type MentionRepository interface {
+	// SetVideoMentions replaces the mentions of a video, and returns the users
+	// mentioned now who were not before
+	SetVideoMentions(ctx context.Context, videoID uuid.UUID, mentions []*entity.VideoMention) ([]uuid.UUID, error)
+	// ListByVideos retrieves the mentions of each video, in order of appearance
+	ListByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID][]*entity.VideoMention, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+)
+
+// ShareLinkRepository defines the interface for share link data access
+type ShareLinkRepository interface {
+	// Create stores a share link, and reports false if its code is taken
+	Create(ctx context.Context, link *entity.ShareLink) (bool, error)
+	// Click counts a click on a link and returns the link, nil if there is none
+	Click(ctx context.Context, code string) (*entity.ShareLink, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// TranscodingJobRepository defines the interface for transcoding job data access.
+// Claim, Heartbeat, Complete and Fail only act on jobs leased by the given owner,
+// so a worker that lost its lease cannot overwrite the job of another worker.
+type TranscodingJobRepository interface {
+	Create(ctx context.Context, job *entity.TranscodingJob) error
+	GetLatestByVideoID(ctx context.Context, videoID uuid.UUID) (*entity.TranscodingJob, error)
+	// HasActive checks if a job of a kind is pending or running for a video
+	HasActive(ctx context.Context, videoID uuid.UUID, kind string) (bool, error)
+	// Claim leases the next runnable job (pending and due, or running with an expired lease)
+	// and increments its attempts. It returns nil when there is nothing to run.
+	Claim(ctx context.Context, owner string, lease time.Duration) (*entity.TranscodingJob, error)
+	Heartbeat(ctx context.Context, jobID uuid.UUID, owner string, lease time.Duration) (bool, error)
+	Complete(ctx context.Context, jobID uuid.UUID, owner string) error
+	// Fail records an error; the job is retried at retryAt, or marked dead when retryAt is nil
+	Fail(ctx context.Context, jobID uuid.UUID, owner string, errMsg string, retryAt *time.Time) error
+}
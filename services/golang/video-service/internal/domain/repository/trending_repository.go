@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// TrendingRepository defines the interface for trending score data access
+type TrendingRepository interface {
+	// ListCandidates lists the public, ready videos created after createdAfter,
+	// in video_id order starting after afterID, with their hashtags. Their
+	// completion rate is averaged over the views recorded after viewsSince.
+	ListCandidates(ctx context.Context, createdAfter, viewsSince time.Time, afterID uuid.UUID, limit int) ([]*entity.TrendingCandidate, error)
+	// ScoresAt returns for each of the videos that has snapshots the last one
+	// computed at or before at, or its first one if all are later
+	ScoresAt(ctx context.Context, videoIDs []uuid.UUID, at time.Time) (map[uuid.UUID]*entity.TrendingScore, error)
+	SaveScores(ctx context.Context, scores []*entity.TrendingScore) error
+	// TopScores lists the best scores of the last run, of videos from region,
+	// or from every region when region is ""
+	TopScores(ctx context.Context, region string, limit int) ([]*entity.TrendingScore, error)
+	// PruneScores keeps one snapshot per video and hour of those computed
+	// before thinBefore, and deletes the ones computed before deleteBefore
+	PruneScores(ctx context.Context, thinBefore, deleteBefore time.Time) (int64, error)
+}
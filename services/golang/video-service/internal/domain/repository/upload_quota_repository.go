@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// UploadQuotaRepository defines the interface for daily upload quota data access
+type UploadQuotaRepository interface {
+	// Consume adds count uploads and bytes to the usage of userID on day, unless
+	// that would go over maxCount or maxBytes (0 means unlimited). It reports
+	// false, changing nothing, when a limit would be exceeded. The returned
+	// usage is the state after the call.
+	Consume(ctx context.Context, userID uuid.UUID, day time.Time, count int, bytes int64, maxCount int, maxBytes int64) (*entity.UploadQuota, bool, error)
+	// Refund gives back uploads and bytes previously consumed on day
+	Refund(ctx context.Context, userID uuid.UUID, day time.Time, count int, bytes int64) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// UploadSessionRepository defines the interface for upload session data access
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *entity.UploadSession) error
+	GetByID(ctx context.Context, sessionID uuid.UUID) (*entity.UploadSession, error)
+	GetByVideoID(ctx context.Context, videoID uuid.UUID) (*entity.UploadSession, error)
+	// TransitionStatus moves the session to status `to` only if it is currently `from`.
+	// It reports whether the session was updated.
+	TransitionStatus(ctx context.Context, sessionID uuid.UUID, from, to string) (bool, error)
+	SavePart(ctx context.Context, part *entity.UploadPart) error
+	GetParts(ctx context.Context, sessionID uuid.UUID) ([]*entity.UploadPart, error)
+	GetExpired(ctx context.Context, before time.Time, limit int) ([]*entity.UploadSession, error)
+}
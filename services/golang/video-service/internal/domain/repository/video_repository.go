@@ -2,20 +2,86 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"tiktok-clone/shared/common/pagination"
 	"tiktok-clone/video-service/internal/domain/entity"
 
 	"github.com/google/uuid"
 )
 
+// VideoSort is the order of an owner video list
+type VideoSort string
+
+// Owner video list orders, each ending with video_id as tie-breaker
+const (
+	VideoSortNewest     VideoSort = "newest"
+	VideoSortMostViewed VideoSort = "most_viewed"
+	VideoSortMostLiked  VideoSort = "most_liked"
+)
+
+// OwnerVideoFilter narrows the videos ListByOwner returns; empty fields match every video
+type OwnerVideoFilter struct {
+	Visibilities []entity.Visibility
+	Statuses     []entity.EncodingStatus
+	CreatedFrom  *time.Time // inclusive
+	CreatedTo    *time.Time // exclusive
+	Sort         VideoSort
+}
+
 // VideoRepository defines the interface for video data access
 type VideoRepository interface {
 	Create(ctx context.Context, video *entity.Video) error
 	GetByID(ctx context.Context, videoID uuid.UUID) (*entity.Video, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Video, error)
+	// GetByIDs retrieves the videos that still exist among videoIDs, in no particular order
+	GetByIDs(ctx context.Context, videoIDs []uuid.UUID) ([]*entity.Video, error)
+	// GetByUserID lists the unpinned videos of userID with one of the given
+	// visibility levels and encoding statuses, newest first, starting after
+	// the cursor position (nil for the first page)
+	GetByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus, after *pagination.Cursor, limit int) ([]*entity.Video, error)
+	// GetPinnedByUserID lists the pinned videos of userID with one of the given
+	// visibility levels and encoding statuses, most recently pinned first
+	GetPinnedByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus) ([]*entity.Video, error)
+	// CountByUserID counts the videos GetByUserID and GetPinnedByUserID list together
+	CountByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus) (int64, error)
+	// ListByOwner lists every video of userID matching filter, whatever its
+	// visibility and status, in filter.Sort order starting after the cursor position
+	ListByOwner(ctx context.Context, userID uuid.UUID, filter OwnerVideoFilter, after *pagination.Cursor, limit int) ([]*entity.Video, error)
+	// CountByOwner counts the videos ListByOwner lists
+	CountByOwner(ctx context.Context, userID uuid.UUID, filter OwnerVideoFilter) (int64, error)
+	// Pin pins a video of userID unless maxPinned of their listed videos are
+	// already pinned, which it reports with false. Pinning a pinned video succeeds.
+	Pin(ctx context.Context, videoID, userID uuid.UUID, maxPinned int) (bool, error)
+	Unpin(ctx context.Context, videoID uuid.UUID) error
+	// Update saves the fields of a video except its counts and the columns owned
+	// by jobs and other RPCs: encoding status, renditions, fingerprint, artwork,
+	// download and pin
 	Update(ctx context.Context, video *entity.Video) error
+	// UpdateDetails saves only the title, description, category, visibility and
+	// permissions of a video
+	UpdateDetails(ctx context.Context, video *entity.Video) error
 	Delete(ctx context.Context, videoID uuid.UUID) error
-	GetTrending(ctx context.Context, limit int) ([]*entity.Video, error)
-	IncrementViewCount(ctx context.Context, videoID uuid.UUID) error
-	UpdateEncodingStatus(ctx context.Context, videoID uuid.UUID, status string) error
+	// Like records that userID likes a video, and reports false if they already did
+	Like(ctx context.Context, videoID, userID uuid.UUID) (bool, error)
+	// Unlike removes the like of userID, and reports false if there was none
+	Unlike(ctx context.Context, videoID, userID uuid.UUID) (bool, error)
+	IsLiked(ctx context.Context, videoID, userID uuid.UUID) (bool, error)
+	// ListLikes lists the likes of userID, most recent first, starting after
+	// the cursor position (nil for the first page)
+	ListLikes(ctx context.Context, userID uuid.UUID, after *pagination.Cursor, limit int) ([]*entity.VideoLike, error)
+	CountLikes(ctx context.Context, userID uuid.UUID) (int64, error)
+	UpdateEncodingStatus(ctx context.Context, videoID uuid.UUID, from, to entity.EncodingStatus, reason string) (bool, error)
+	UpdateRenditions(ctx context.Context, videoID uuid.UUID, renditionsPrefix string) error
+	UpdateArtwork(ctx context.Context, video *entity.Video) error
+	UpdateDownload(ctx context.Context, videoID uuid.UUID, downloadKey string) error
+	UpdateFingerprint(ctx context.Context, videoID uuid.UUID, fingerprint string) error
+	// FindByContentSHA256 returns other videos uploaded with exactly the same file
+	FindByContentSHA256(ctx context.Context, sha256 string, excludeID uuid.UUID, limit int) ([]*entity.Video, error)
+	// FindFingerprinted returns other fingerprinted videos whose duration is within the given range
+	FindFingerprinted(ctx context.Context, minDuration, maxDuration int, excludeID uuid.UUID, limit int) ([]*entity.Video, error)
+	// ListByEncodingStatus lists the videos in status, oldest first, starting
+	// after the cursor position (nil for the first page)
+	ListByEncodingStatus(ctx context.Context, status entity.EncodingStatus, after *pagination.Cursor, limit int) ([]*entity.Video, error)
+	// CountByEncodingStatus counts the videos in status
+	CountByEncodingStatus(ctx context.Context, status entity.EncodingStatus) (int64, error)
 }
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+)
+
+// VideoViewRepository defines the interface for view event data access
+type VideoViewRepository interface {
+	Create(ctx context.Context, view *entity.VideoView) error
+}
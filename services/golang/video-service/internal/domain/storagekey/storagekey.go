@@ -0,0 +1,103 @@
+// Package storagekey defines where the files of a video live in object storage.
+// Objects belonging to one video are stored under VideoPrefix, so deleting that
+// prefix removes them. Deduplicated originals and their renditions are stored
+// under Content, keyed by the SHA-256 of the original, and shared by every
+// video with the same content; they are deleted when the last video goes.
+package storagekey
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// VideoPrefix returns the prefix of all objects belonging to a video
+func VideoPrefix(videoID uuid.UUID) string {
+	return fmt.Sprintf("videos/%s/", videoID.String())
+}
+
+// Original returns the key of the uploaded video file
+func Original(videoID uuid.UUID) string {
+	return VideoPrefix(videoID) + "original.mp4"
+}
+
+// Thumbnail returns the key of the thumbnail uploaded by the creator; ext
+// matches the image type, so servers relying on extensions serve it correctly
+func Thumbnail(videoID uuid.UUID, ext string) string {
+	return VideoPrefix(videoID) + "thumbnail." + ext
+}
+
+// Renditions returns the prefix of the transcoded renditions of a video
+func Renditions(videoID uuid.UUID) string {
+	return VideoPrefix(videoID) + "renditions/"
+}
+
+// MasterPlaylistFile is the name of the HLS master playlist within a renditions prefix
+const MasterPlaylistFile = "master.m3u8"
+
+// MasterPlaylist returns the key of the HLS master playlist of a video
+func MasterPlaylist(videoID uuid.UUID) string {
+	return Renditions(videoID) + MasterPlaylistFile
+}
+
+// Download returns the key of the watermarked copy of a video handed out with shares
+func Download(videoID uuid.UUID) string {
+	return VideoPrefix(videoID) + "download.mp4"
+}
+
+// Content returns the prefix of the shared objects of the content with the given SHA-256
+func Content(sha256 string) string {
+	return fmt.Sprintf("content/%s/", sha256)
+}
+
+// ContentOriginal returns the key of a deduplicated original file
+func ContentOriginal(sha256 string) string {
+	return Content(sha256) + "original"
+}
+
+// ContentRenditions returns the prefix of the renditions shared by every video with the same content
+func ContentRenditions(sha256 string) string {
+	return Content(sha256) + "renditions/"
+}
+
+// CoverWidths are the widths the cover frame is rendered at, smallest first
+var CoverWidths = []int{180, 360, 720}
+
+// Cover image formats, as file extensions
+const (
+	CoverFormatJPEG = "jpg"
+	CoverFormatWebP = "webp"
+)
+
+// Artwork returns the prefix of the images generated from a video
+func Artwork(videoID uuid.UUID) string {
+	return VideoPrefix(videoID) + "artwork/"
+}
+
+// CoverSet returns the prefix of one set of cover images. Every cover frame the
+// creator picks gets a new set, so cached copies of the previous cover stay valid.
+func CoverSet(videoID uuid.UUID, name string) string {
+	return Artwork(videoID) + "cover-" + name + "/"
+}
+
+// CoverFile returns the file name of a cover image within its set
+func CoverFile(width int, format string) string {
+	return fmt.Sprintf("cover_%d.%s", width, format)
+}
+
+// Preview returns the key of the animated preview of a video
+func Preview(videoID uuid.UUID) string {
+	return Artwork(videoID) + PreviewFile
+}
+
+// Storyboard returns the key of the WebVTT index of the scrub sprite sheet
+func Storyboard(videoID uuid.UUID) string {
+	return Artwork(videoID) + StoryboardFile
+}
+
+// File names of the generated artwork, relative to Artwork
+const (
+	PreviewFile    = "preview.webp"
+	SpriteFile     = "sprite.jpg"
+	StoryboardFile = "storyboard.vtt"
+)
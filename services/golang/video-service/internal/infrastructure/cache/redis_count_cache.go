@@ -0,0 +1,49 @@
+// Package cache keeps derived values in Redis so they are not recomputed on every request.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces the keys of this service in a shared Redis
+const keyPrefix = "video-service:"
+
+// RedisCountCache caches counts such as list totals in Redis
+type RedisCountCache struct {
+	client *redis.Client
+}
+
+// NewRedisCountCache creates a new Redis count cache
+func NewRedisCountCache(client *redis.Client) *RedisCountCache {
+	return &RedisCountCache{client: client}
+}
+
+// GetCount returns a cached count, and false if it is not cached
+func (c *RedisCountCache) GetCount(ctx context.Context, key string) (int64, bool, error) {
+	count, err := c.client.Get(ctx, keyPrefix+key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
+// SetCount caches a count for ttl
+func (c *RedisCountCache) SetCount(ctx context.Context, key string, count int64, ttl time.Duration) error {
+	return c.client.Set(ctx, keyPrefix+key, count, ttl).Err()
+}
+
+// DeleteCounts drops cached counts
+func (c *RedisCountCache) DeleteCounts(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = keyPrefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Keys of the write-behind counters. Totals are cached per video and counter,
+// as in video:views:{video_id}. Deltas not yet in the database accumulate in
+// the pending hash, with fields such as {video_id}:views, until a flush moves
+// them to a batch hash listed in the batch set.
+const (
+	counterTotalPrefix = keyPrefix + "video:"
+	counterPendingKey  = keyPrefix + "counters:pending"
+	counterBatchSetKey = keyPrefix + "counters:batches"
+	counterBatchPrefix = keyPrefix + "counters:batch:"
+)
+
+// Counter names, in keys and hash fields
+const (
+	counterViews  = "views"
+	counterLikes  = "likes"
+	counterShares = "shares"
+)
+
+var counterNames = []string{counterViews, counterLikes, counterShares}
+
+// incrementScript adds a delta to the pending hash, and to the cached total if there is one
+var incrementScript = redis.NewScript(`
+redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	redis.call('INCRBY', KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+// totalsScript returns the cached totals of KEYS, caching missing ones as the
+// stored value plus the deltas pending and in batches not flushed yet.
+// ARGV: pending key, batch set key, batch key prefix, ttl in seconds, then a
+// hash field and a stored value per key.
+var totalsScript = redis.NewScript(`
+local batches = redis.call('SMEMBERS', ARGV[2])
+local totals = {}
+for i, key in ipairs(KEYS) do
+	local total = redis.call('GET', key)
+	if not total then
+		local field = ARGV[3 + 2 * i]
+		total = tonumber(ARGV[4 + 2 * i]) + tonumber(redis.call('HGET', ARGV[1], field) or 0)
+		for _, batch in ipairs(batches) do
+			total = total + tonumber(redis.call('HGET', ARGV[3] .. batch, field) or 0)
+		end
+		redis.call('SET', key, total, 'EX', ARGV[4])
+	end
+	totals[i] = tonumber(total)
+end
+return totals
+`)
+
+// batchScript moves the pending hash to a new batch, if there are pending deltas
+var batchScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('RENAME', KEYS[1], KEYS[2])
+	redis.call('SADD', KEYS[3], ARGV[1])
+end
+return 1
+`)
+
+// RedisCounterStore counts views, likes and shares in Redis and hands the
+// deltas to the flusher in batches
+type RedisCounterStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisCounterStore creates a new Redis counter store; cached totals expire after ttl
+func NewRedisCounterStore(client *redis.Client, ttl time.Duration) *RedisCounterStore {
+	return &RedisCounterStore{client: client, ttl: ttl}
+}
+
+// counterField is the pending hash field of a counter of a video
+func counterField(videoID uuid.UUID, counter string) string {
+	return videoID.String() + ":" + counter
+}
+
+// counterTotalKey is the key of the cached total of a counter of a video
+func counterTotalKey(videoID uuid.UUID, counter string) string {
+	return counterTotalPrefix + counter + ":" + videoID.String()
+}
+
+// countsValues lists the counters of counts in counterNames order
+func countsValues(counts entity.VideoCounts) []int64 {
+	return []int64{counts.Views, counts.Likes, counts.Shares}
+}
+
+// addCount adds a delta to the counter of counts named counter
+func addCount(counts *entity.VideoCounts, counter string, delta int64) {
+	switch counter {
+	case counterViews:
+		counts.Views += delta
+	case counterLikes:
+		counts.Likes += delta
+	case counterShares:
+		counts.Shares += delta
+	}
+}
+
+// Increment adds the non-zero counters of delta
+func (s *RedisCounterStore) Increment(ctx context.Context, videoID uuid.UUID, delta entity.VideoCounts) error {
+	pipe := s.client.Pipeline()
+	for i, value := range countsValues(delta) {
+		if value != 0 {
+			counter := counterNames[i]
+			incrementScript.Run(ctx, pipe, []string{counterPendingKey, counterTotalKey(videoID, counter)},
+				counterField(videoID, counter), value)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Totals returns the cached totals of videos, caching the missing ones
+func (s *RedisCounterStore) Totals(ctx context.Context, stored map[uuid.UUID]entity.VideoCounts) (map[uuid.UUID]entity.VideoCounts, error) {
+	totals := make(map[uuid.UUID]entity.VideoCounts, len(stored))
+	if len(stored) == 0 {
+		return totals, nil
+	}
+
+	videoIDs := make([]uuid.UUID, 0, len(stored))
+	keys := make([]string, 0, len(stored)*len(counterNames))
+	args := []interface{}{counterPendingKey, counterBatchSetKey, counterBatchPrefix, int64(s.ttl / time.Second)}
+	for videoID, counts := range stored {
+		videoIDs = append(videoIDs, videoID)
+		for i, value := range countsValues(counts) {
+			keys = append(keys, counterTotalKey(videoID, counterNames[i]))
+			args = append(args, counterField(videoID, counterNames[i]), value)
+		}
+	}
+
+	values, err := totalsScript.Run(ctx, s.client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(keys) {
+		return nil, fmt.Errorf("counter totals: got %d values for %d keys", len(values), len(keys))
+	}
+	for i, videoID := range videoIDs {
+		var counts entity.VideoCounts
+		for j, counter := range counterNames {
+			addCount(&counts, counter, values[i*len(counterNames)+j])
+		}
+		totals[videoID] = counts
+	}
+	return totals, nil
+}
+
+// PendingBatches moves the pending deltas to a new batch and returns every
+// batch not flushed yet, including those left behind by a failed flush
+func (s *RedisCounterStore) PendingBatches(ctx context.Context) ([]*usecase.CounterBatch, error) {
+	batchID := uuid.NewString()
+	err := batchScript.Run(ctx, s.client, []string{counterPendingKey, counterBatchPrefix + batchID, counterBatchSetKey}, batchID).Err()
+	if err != nil {
+		return nil, err
+	}
+
+	batchIDs, err := s.client.SMembers(ctx, counterBatchSetKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]*usecase.CounterBatch, 0, len(batchIDs))
+	for _, id := range batchIDs {
+		fields, err := s.client.HGetAll(ctx, counterBatchPrefix+id).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		batch := &usecase.CounterBatch{ID: id, Deltas: map[uuid.UUID]entity.VideoCounts{}}
+		for field, value := range fields {
+			videoID, counter, ok := parseCounterField(field)
+			delta, err := strconv.ParseInt(value, 10, 64)
+			if !ok || err != nil {
+				continue
+			}
+			counts := batch.Deltas[videoID]
+			addCount(&counts, counter, delta)
+			batch.Deltas[videoID] = counts
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// parseCounterField splits a pending hash field into the video ID and counter
+func parseCounterField(field string) (uuid.UUID, string, bool) {
+	id, counter, ok := strings.Cut(field, ":")
+	if !ok {
+		return uuid.Nil, "", false
+	}
+	videoID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+	return videoID, counter, true
+}
+
+// DeleteBatch forgets a flushed batch
+func (s *RedisCounterStore) DeleteBatch(ctx context.Context, batchID string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, counterBatchPrefix+batchID)
+		pipe.SRem(ctx, counterBatchSetKey, batchID)
+		return nil
+	})
+	return err
+}
+
+// HasPending returns which of videoIDs have deltas pending or in a batch
+func (s *RedisCounterStore) HasPending(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	pending := make(map[uuid.UUID]bool, len(videoIDs))
+	if len(videoIDs) == 0 {
+		return pending, nil
+	}
+
+	batchIDs, err := s.client.SMembers(ctx, counterBatchSetKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	hashes := append([]string{counterPendingKey}, make([]string, len(batchIDs))...)
+	for i, id := range batchIDs {
+		hashes[i+1] = counterBatchPrefix + id
+	}
+
+	fields := make([]string, 0, len(videoIDs)*len(counterNames))
+	for _, videoID := range videoIDs {
+		for _, counter := range counterNames {
+			fields = append(fields, counterField(videoID, counter))
+		}
+	}
+
+	pipe := s.client.Pipeline()
+	results := make([]*redis.SliceCmd, len(hashes))
+	for i, hash := range hashes {
+		results[i] = pipe.HMGet(ctx, hash, fields...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		for i, value := range result.Val() {
+			if value != nil {
+				pending[videoIDs[i/len(counterNames)]] = true
+			}
+		}
+	}
+	return pending, nil
+}
+
+// Forget drops the cached totals of videos, so they are cached again from the database
+func (s *RedisCounterStore) Forget(ctx context.Context, videoIDs []uuid.UUID) error {
+	if len(videoIDs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(videoIDs)*len(counterNames))
+	for _, videoID := range videoIDs {
+		for _, counter := range counterNames {
+			keys = append(keys, counterTotalKey(videoID, counter))
+		}
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
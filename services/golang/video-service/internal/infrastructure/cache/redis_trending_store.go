@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// trendingKeyPrefix is followed by the list name, as in trending:videos:{region}
+const trendingKeyPrefix = keyPrefix + "trending:videos:"
+
+// RedisTrendingStore keeps each trending list in a Redis sorted set scored by
+// trending score. Every generation of a list has its own set, and the list key
+// holds the current generation, so pages of a generation stay consistent
+// until it expires.
+type RedisTrendingStore struct {
+	client *redis.Client
+}
+
+// NewRedisTrendingStore creates a new Redis trending store
+func NewRedisTrendingStore(client *redis.Client) *RedisTrendingStore {
+	return &RedisTrendingStore{client: client}
+}
+
+// ReplaceRanking writes the new generation of a list, then points the list at
+// it, so readers never see a half-written list
+func (s *RedisTrendingStore) ReplaceRanking(ctx context.Context, list, generation string, ranking []usecase.RankedVideo, ttl time.Duration) error {
+	key := trendingKeyPrefix + list
+	if len(ranking) == 0 {
+		return s.client.Del(ctx, key).Err()
+	}
+
+	members := make([]redis.Z, len(ranking))
+	for i, ranked := range ranking {
+		members[i] = redis.Z{Score: ranked.Score, Member: ranked.VideoID.String()}
+	}
+
+	generationKey := key + ":gen:" + generation
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, generationKey)
+		pipe.ZAdd(ctx, generationKey, members...)
+		pipe.Expire(ctx, generationKey, ttl)
+		pipe.Set(ctx, key, generation, ttl)
+		return nil
+	})
+	return err
+}
+
+// Ranking returns a page of a generation of a list, best first
+func (s *RedisTrendingStore) Ranking(ctx context.Context, list, generation string, offset, limit int) ([]uuid.UUID, string, bool, error) {
+	key := trendingKeyPrefix + list
+	if generation == "" {
+		current, err := s.client.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return nil, "", false, nil
+		}
+		if err != nil {
+			return nil, "", false, err
+		}
+		generation = current
+	}
+	generationKey := key + ":gen:" + generation
+
+	pipe := s.client.Pipeline()
+	exists := pipe.Exists(ctx, generationKey)
+	members := pipe.ZRevRange(ctx, generationKey, int64(offset), int64(offset+limit-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, "", false, err
+	}
+	if exists.Val() == 0 {
+		return nil, "", false, nil
+	}
+
+	videoIDs := make([]uuid.UUID, 0, len(members.Val()))
+	for _, member := range members.Val() {
+		videoID, err := uuid.Parse(member)
+		if err != nil {
+			continue
+		}
+		videoIDs = append(videoIDs, videoID)
+	}
+	return videoIDs, generation, true, nil
+}
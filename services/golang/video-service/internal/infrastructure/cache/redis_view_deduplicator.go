@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// viewedKeyPrefix is followed by the video ID and the viewer
+const viewedKeyPrefix = keyPrefix + "video:viewed:"
+
+// RedisViewDeduplicator remembers who viewed which video with one expiring key per pair
+type RedisViewDeduplicator struct {
+	client *redis.Client
+}
+
+// NewRedisViewDeduplicator creates a new Redis view deduplicator
+func NewRedisViewDeduplicator(client *redis.Client) *RedisViewDeduplicator {
+	return &RedisViewDeduplicator{client: client}
+}
+
+// FirstView sets the key of the pair unless it exists, which it reports with false
+func (d *RedisViewDeduplicator) FirstView(ctx context.Context, videoID uuid.UUID, viewer string, window time.Duration) (bool, error) {
+	return d.client.SetNX(ctx, viewedKeyPrefix+videoID.String()+":"+viewer, 1, window).Result()
+}
@@ -0,0 +1,48 @@
+// Package events publishes the domain events other services react to.
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MentionEventType is the type field of mention events
+const MentionEventType = "video.mention"
+
+// RedisMentionPublisher adds mention events to a Redis stream, which the
+// notification service reads with a consumer group
+type RedisMentionPublisher struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisMentionPublisher creates a publisher to stream, trimmed to about maxLen events
+func NewRedisMentionPublisher(client *redis.Client, stream string, maxLen int64) *RedisMentionPublisher {
+	return &RedisMentionPublisher{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+// PublishMention adds an event with its type and JSON payload
+func (p *RedisMentionPublisher) PublishMention(ctx context.Context, event *entity.MentionEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"type":    MentionEventType,
+			"payload": payload,
+		},
+	}).Err()
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+// ContentObjectRepositoryImpl implements ContentObjectRepository
+type ContentObjectRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewContentObjectRepository creates a new content object repository
+func NewContentObjectRepository(db *gorm.DB) *ContentObjectRepositoryImpl {
+	return &ContentObjectRepositoryImpl{db: db}
+}
+
+// Acquire creates the content object or adds a reference to it in a single statement,
+// so concurrent uploads of the same file agree on who stores the original
+func (r *ContentObjectRepositoryImpl) Acquire(ctx context.Context, sha256, originalKey string) (*entity.ContentObject, error) {
+	var content entity.ContentObject
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO content_objects (content_sha256, original_key, ref_count, created_at, updated_at)
+		VALUES (?, ?, 1, now(), now())
+		ON CONFLICT (content_sha256) DO UPDATE
+		SET ref_count = content_objects.ref_count + 1, updated_at = now()
+		RETURNING *`,
+		sha256, originalKey,
+	).Scan(&content).Error
+	if err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// GetBySHA256 retrieves a content object by the SHA-256 of its original
+func (r *ContentObjectRepositoryImpl) GetBySHA256(ctx context.Context, sha256 string) (*entity.ContentObject, error) {
+	var content entity.ContentObject
+	err := r.db.WithContext(ctx).Where("content_sha256 = ?", sha256).First(&content).Error
+	if err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// UpdateRenditions records where the shared renditions of the content are stored
+func (r *ContentObjectRepositoryImpl) UpdateRenditions(ctx context.Context, sha256, renditionsPrefix string) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.ContentObject{}).
+		Where("content_sha256 = ?", sha256).
+		Updates(map[string]interface{}{
+			"renditions_prefix": renditionsPrefix,
+			"updated_at":        gorm.Expr("now()"),
+		}).
+		Error
+}
+
+// Release drops a reference and deletes the content object once unreferenced
+func (r *ContentObjectRepositoryImpl) Release(ctx context.Context, sha256 string) (bool, error) {
+	removed := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entity.ContentObject{}).
+			Where("content_sha256 = ? AND ref_count > 0", sha256).
+			Updates(map[string]interface{}{
+				"ref_count":  gorm.Expr("ref_count - 1"),
+				"updated_at": gorm.Expr("now()"),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("content_sha256 = ? AND ref_count = 0", sha256).Delete(&entity.ContentObject{})
+		removed = result.RowsAffected > 0
+		return result.Error
+	})
+	return removed, err
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CounterRepositoryImpl implements CounterRepository
+type CounterRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewCounterRepository creates a new counter repository
+func NewCounterRepository(db *gorm.DB) *CounterRepositoryImpl {
+	return &CounterRepositoryImpl{db: db}
+}
+
+// ApplyBatch records the batch and adds its deltas in one transaction, so a
+// batch is applied exactly once however often it is flushed
+func (r *CounterRepositoryImpl) ApplyBatch(ctx context.Context, batchID string, deltas map[uuid.UUID]entity.VideoCounts) (bool, error) {
+	applied := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("INSERT INTO counter_flush_batches (batch_id) VALUES (?) ON CONFLICT DO NOTHING", batchID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		applied = true
+
+		for videoID, delta := range deltas {
+			err := tx.Exec(`
+				UPDATE videos SET
+					view_count = GREATEST(view_count + ?, 0),
+					like_count = GREATEST(like_count + ?, 0),
+					share_count = GREATEST(share_count + ?, 0)
+				WHERE video_id = ?`,
+				delta.Views, delta.Likes, delta.Shares, videoID,
+			).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// PruneBatches deletes old batch records
+func (r *CounterRepositoryImpl) PruneBatches(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Exec("DELETE FROM counter_flush_batches WHERE applied_at < ?", before)
+	return result.RowsAffected, result.Error
+}
+
+// Recount counts the likes, shares and counted view events of a page of videos
+func (r *CounterRepositoryImpl) Recount(ctx context.Context, afterID uuid.UUID, limit int) ([]*entity.VideoRecount, error) {
+	var recounts []*entity.VideoRecount
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT v.video_id, v.view_count, v.like_count, v.share_count,
+			(SELECT COUNT(*) FROM video_views w WHERE w.video_id = v.video_id AND w.counted) AS counted_views,
+			(SELECT COUNT(*) FROM video_likes l WHERE l.video_id = v.video_id) AS likes,
+			(SELECT COUNT(*) FROM share_links s WHERE s.video_id = v.video_id) AS shares
+		FROM videos v
+		WHERE v.video_id > ?
+		ORDER BY v.video_id
+		LIMIT ?`,
+		afterID, limit,
+	).Scan(&recounts).Error
+	return recounts, err
+}
+
+// SetCounts overwrites the view, like and share counts of a video
+func (r *CounterRepositoryImpl) SetCounts(ctx context.Context, videoID uuid.UUID, counts entity.VideoCounts) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Video{}).
+		Where("video_id = ?", videoID).
+		UpdateColumns(map[string]interface{}{
+			"view_count":  counts.Views,
+			"like_count":  counts.Likes,
+			"share_count": counts.Shares,
+		}).Error
+}
@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// videoHashtag is a row of video_hashtags
+type videoHashtag struct {
+	VideoID   uuid.UUID `gorm:"type:uuid;primary_key"`
+	HashtagID uuid.UUID `gorm:"type:uuid;primary_key"`
+	Position  int
+	Explicit  bool
+}
+
+// TableName specifies the table name
+func (videoHashtag) TableName() string {
+	return "video_hashtags"
+}
+
+// HashtagRepositoryImpl implements HashtagRepository
+type HashtagRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewHashtagRepository creates a new hashtag repository
+func NewHashtagRepository(db *gorm.DB) *HashtagRepositoryImpl {
+	return &HashtagRepositoryImpl{db: db}
+}
+
+// SetVideoHashtags diffs tags against the stored ones in a transaction,
+// adjusting usage_count by the hashtags added and removed
+func (r *HashtagRepositoryImpl) SetVideoHashtags(ctx context.Context, videoID uuid.UUID, tags []*entity.VideoTag) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes the updates of a video, so concurrent ones cannot count a hashtag twice
+		var locked []uuid.UUID
+		err := tx.Model(&entity.Video{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("video_id = ?", videoID).
+			Pluck("video_id", &locked).Error
+		if err != nil || len(locked) == 0 {
+			return err
+		}
+
+		wanted := make([]*videoHashtag, 0, len(tags))
+		if len(tags) > 0 {
+			hashtags := make([]*entity.Hashtag, len(tags))
+			names := make([]string, len(tags))
+			for i, tag := range tags {
+				hashtags[i] = &entity.Hashtag{Name: tag.Name}
+				names[i] = tag.Name
+			}
+			err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+				Create(&hashtags).Error
+			if err != nil {
+				return err
+			}
+
+			var stored []*entity.Hashtag
+			if err := tx.Where("name IN ?", names).Find(&stored).Error; err != nil {
+				return err
+			}
+			ids := make(map[string]uuid.UUID, len(stored))
+			for _, hashtag := range stored {
+				ids[hashtag.Name] = hashtag.HashtagID
+			}
+			for i, tag := range tags {
+				wanted = append(wanted, &videoHashtag{VideoID: videoID, HashtagID: ids[tag.Name], Position: i, Explicit: tag.Explicit})
+			}
+		}
+
+		var existing []*videoHashtag
+		if err := tx.Where("video_id = ?", videoID).Find(&existing).Error; err != nil {
+			return err
+		}
+		kept := make(map[uuid.UUID]bool, len(wanted))
+		for _, row := range wanted {
+			kept[row.HashtagID] = true
+		}
+		var added, removed []uuid.UUID
+		had := make(map[uuid.UUID]bool, len(existing))
+		for _, row := range existing {
+			had[row.HashtagID] = true
+			if !kept[row.HashtagID] {
+				removed = append(removed, row.HashtagID)
+			}
+		}
+		for _, row := range wanted {
+			if !had[row.HashtagID] {
+				added = append(added, row.HashtagID)
+			}
+		}
+
+		if len(removed) > 0 {
+			err := tx.Where("video_id = ? AND hashtag_id IN ?", videoID, removed).Delete(&videoHashtag{}).Error
+			if err != nil {
+				return err
+			}
+			if err := adjustUsage(tx, removed, -1); err != nil {
+				return err
+			}
+		}
+		if len(wanted) > 0 {
+			// Existing rows only get their position and explicit flag updated
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "video_id"}, {Name: "hashtag_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"position", "explicit"}),
+			}).Create(&wanted).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			return adjustUsage(tx, added, 1)
+		}
+		return nil
+	})
+}
+
+// adjustUsage adds delta to the usage count of hashtags
+func adjustUsage(tx *gorm.DB, hashtagIDs []uuid.UUID, delta int) error {
+	return tx.Model(&entity.Hashtag{}).
+		Where("hashtag_id IN ?", hashtagIDs).
+		Update("usage_count", gorm.Expr("GREATEST(COALESCE(usage_count, 0) + ?, 0)", delta)).
+		Error
+}
+
+// ListByVideos retrieves the tags of videos
+func (r *HashtagRepositoryImpl) ListByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID][]*entity.VideoTag, error) {
+	byVideo := make(map[uuid.UUID][]*entity.VideoTag, len(videoIDs))
+	if len(videoIDs) == 0 {
+		return byVideo, nil
+	}
+
+	var tags []*entity.VideoTag
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT vh.video_id, h.name, vh.explicit
+		FROM video_hashtags vh
+		JOIN hashtags h ON h.hashtag_id = vh.hashtag_id
+		WHERE vh.video_id IN ?
+		ORDER BY vh.video_id, vh.position`,
+		videoIDs,
+	).Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		byVideo[tag.VideoID] = append(byVideo[tag.VideoID], tag)
+	}
+	return byVideo, nil
+}
+
+// GetByName retrieves a hashtag by its normalized name
+func (r *HashtagRepositoryImpl) GetByName(ctx context.Context, name string) (*entity.Hashtag, error) {
+	var hashtag entity.Hashtag
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&hashtag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hashtag, nil
+}
+
+// likeEscaper escapes the LIKE wildcards of a prefix; '_' is a hashtag character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Suggest retrieves used hashtags by prefix, using the text_pattern_ops index on name
+func (r *HashtagRepositoryImpl) Suggest(ctx context.Context, prefix string, limit int) ([]*entity.Hashtag, error) {
+	var hashtags []*entity.Hashtag
+	err := r.db.WithContext(ctx).
+		Where("name LIKE ? AND usage_count > 0", likeEscaper.Replace(prefix)+"%").
+		Order("usage_count DESC, name").
+		Limit(limit).
+		Find(&hashtags).Error
+	return hashtags, err
+}
+
+// hashtagVideos selects the listed videos tagged with a hashtag
+func hashtagVideos(db *gorm.DB, hashtagID uuid.UUID) *gorm.DB {
+	return db.Model(&entity.Video{}).
+		Joins("JOIN video_hashtags vh ON vh.video_id = videos.video_id").
+		Where("vh.hashtag_id = ? AND videos.is_public = TRUE AND videos.encoding_status = ?", hashtagID, entity.EncodingStatusReady)
+}
+
+// ListVideos retrieves the videos of a hashtag, using keyset pagination over
+// (sort column, video_id)
+func (r *HashtagRepositoryImpl) ListVideos(ctx context.Context, hashtagID uuid.UUID, sort repository.VideoSort, after *pagination.Cursor, limit int) ([]*entity.Video, error) {
+	column := "videos." + ownerSortColumn(sort)
+	query := hashtagVideos(r.db.WithContext(ctx), hashtagID)
+	if after != nil {
+		var key interface{} = after.CreatedAt
+		if column != "videos.created_at" {
+			key = after.Score
+		}
+		query = query.Where(fmt.Sprintf("(%s, videos.video_id) < (?, ?)", column), key, after.ID)
+	}
+
+	var videos []*entity.Video
+	err := query.
+		Order(column + " DESC, videos.video_id DESC").
+		Limit(limit).
+		Find(&videos).Error
+	return videos, err
+}
+
+// CountVideos counts the videos listed by ListVideos
+func (r *HashtagRepositoryImpl) CountVideos(ctx context.Context, hashtagID uuid.UUID) (int64, error) {
+	var count int64
+	err := hashtagVideos(r.db.WithContext(ctx), hashtagID).Count(&count).Error
+	return count, err
+}
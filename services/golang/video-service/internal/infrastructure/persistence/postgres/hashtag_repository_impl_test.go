@@ -0,0 +1,24 @@
+package postgres
+
+import "testing"
+
+func TestLikeEscaper(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"go", "go"},
+		{"go_", `go\_`},
+		{"_", `\_`},
+		{"50%", `50\%`},
+		{`a\b`, `a\\b`},
+		{`\_%`, `\\\_\%`},
+		{"việt", "việt"},
+	}
+
+	for _, tt := range tests {
+		if got := likeEscaper.Replace(tt.prefix); got != tt.want {
+			t.Errorf("likeEscaper.Replace(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MentionRepositoryImpl implements MentionRepository
+type MentionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewMentionRepository creates a new mention repository
+func NewMentionRepository(db *gorm.DB) *MentionRepositoryImpl {
+	return &MentionRepositoryImpl{db: db}
+}
+
+// SetVideoMentions rewrites the mentions of a video in a transaction
+func (r *MentionRepositoryImpl) SetVideoMentions(ctx context.Context, videoID uuid.UUID, mentions []*entity.VideoMention) ([]uuid.UUID, error) {
+	var added []uuid.UUID
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes the updates of a video, so concurrent ones cannot both report a user as new
+		var locked []uuid.UUID
+		err := tx.Model(&entity.Video{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("video_id = ?", videoID).
+			Pluck("video_id", &locked).Error
+		if err != nil || len(locked) == 0 {
+			return err
+		}
+
+		var previous []uuid.UUID
+		err = tx.Model(&entity.VideoMention{}).
+			Where("video_id = ?", videoID).
+			Distinct().
+			Pluck("user_id", &previous).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("video_id = ?", videoID).Delete(&entity.VideoMention{}).Error; err != nil {
+			return err
+		}
+		if len(mentions) > 0 {
+			if err := tx.Create(&mentions).Error; err != nil {
+				return err
+			}
+		}
+
+		known := make(map[uuid.UUID]bool, len(previous)+len(mentions))
+		for _, userID := range previous {
+			known[userID] = true
+		}
+		for _, mention := range mentions {
+			if !known[mention.UserID] {
+				known[mention.UserID] = true
+				added = append(added, mention.UserID)
+			}
+		}
+		return nil
+	})
+	return added, err
+}
+
+// ListByVideos retrieves the mentions of videos
+func (r *MentionRepositoryImpl) ListByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID][]*entity.VideoMention, error) {
+	byVideo := make(map[uuid.UUID][]*entity.VideoMention, len(videoIDs))
+	if len(videoIDs) == 0 {
+		return byVideo, nil
+	}
+
+	var mentions []*entity.VideoMention
+	err := r.db.WithContext(ctx).
+		Where("video_id IN ?", videoIDs).
+		Order("video_id, start_offset").
+		Find(&mentions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, mention := range mentions {
+		byVideo[mention.VideoID] = append(byVideo[mention.VideoID], mention)
+	}
+	return byVideo, nil
+}
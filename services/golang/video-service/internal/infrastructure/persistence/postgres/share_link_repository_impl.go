@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ShareLinkRepositoryImpl implements ShareLinkRepository
+type ShareLinkRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewShareLinkRepository creates a new share link repository
+func NewShareLinkRepository(db *gorm.DB) *ShareLinkRepositoryImpl {
+	return &ShareLinkRepositoryImpl{db: db}
+}
+
+// Create inserts the link unless its code exists
+func (r *ShareLinkRepositoryImpl) Create(ctx context.Context, link *entity.ShareLink) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(link)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Click increments the click count of the link in place
+func (r *ShareLinkRepositoryImpl) Click(ctx context.Context, code string) (*entity.ShareLink, error) {
+	var links []*entity.ShareLink
+	err := r.db.WithContext(ctx).
+		Raw("UPDATE share_links SET click_count = click_count + 1 WHERE code = ? RETURNING *", code).
+		Scan(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+	return links[0], nil
+}
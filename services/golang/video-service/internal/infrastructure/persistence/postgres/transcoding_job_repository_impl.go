@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TranscodingJobRepositoryImpl implements TranscodingJobRepository
+type TranscodingJobRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewTranscodingJobRepository creates a new transcoding job repository
+func NewTranscodingJobRepository(db *gorm.DB) *TranscodingJobRepositoryImpl {
+	return &TranscodingJobRepositoryImpl{db: db}
+}
+
+// Create creates a new transcoding job
+func (r *TranscodingJobRepositoryImpl) Create(ctx context.Context, job *entity.TranscodingJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// GetLatestByVideoID retrieves the most recent transcoding job of a video
+func (r *TranscodingJobRepositoryImpl) GetLatestByVideoID(ctx context.Context, videoID uuid.UUID) (*entity.TranscodingJob, error) {
+	var job entity.TranscodingJob
+	err := r.db.WithContext(ctx).
+		Where("video_id = ?", videoID).
+		Order("created_at DESC").
+		First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// HasActive checks for a pending or running job of a kind
+func (r *TranscodingJobRepositoryImpl) HasActive(ctx context.Context, videoID uuid.UUID, kind string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.TranscodingJob{}).
+		Where("video_id = ? AND kind = ? AND status IN ?", videoID, kind,
+			[]string{entity.TranscodingJobPending, entity.TranscodingJobRunning}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Claim leases the next runnable job.
+// SKIP LOCKED lets concurrent workers claim different jobs without blocking each other.
+func (r *TranscodingJobRepositoryImpl) Claim(ctx context.Context, owner string, lease time.Duration) (*entity.TranscodingJob, error) {
+	var jobs []*entity.TranscodingJob
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE transcoding_jobs
+		SET status = ?, attempts = attempts + 1, lease_owner = ?,
+			lease_expires_at = now() + make_interval(secs => ?), updated_at = now()
+		WHERE job_id = (
+			SELECT job_id FROM transcoding_jobs
+			WHERE (status = ? AND run_after <= now())
+				OR (status = ? AND lease_expires_at < now())
+			ORDER BY run_after
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		entity.TranscodingJobRunning, owner, lease.Seconds(),
+		entity.TranscodingJobPending, entity.TranscodingJobRunning,
+	).Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+// Heartbeat extends the lease of a running job; it reports false if the lease was lost
+func (r *TranscodingJobRepositoryImpl) Heartbeat(ctx context.Context, jobID uuid.UUID, owner string, lease time.Duration) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.TranscodingJob{}).
+		Where("job_id = ? AND lease_owner = ? AND status = ?", jobID, owner, entity.TranscodingJobRunning).
+		Update("lease_expires_at", gorm.Expr("now() + make_interval(secs => ?)", lease.Seconds()))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Complete marks a running job as succeeded
+func (r *TranscodingJobRepositoryImpl) Complete(ctx context.Context, jobID uuid.UUID, owner string) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.TranscodingJob{}).
+		Where("job_id = ? AND lease_owner = ? AND status = ?", jobID, owner, entity.TranscodingJobRunning).
+		Updates(map[string]interface{}{
+			"status":           entity.TranscodingJobSucceeded,
+			"last_error":       "",
+			"lease_owner":      nil,
+			"lease_expires_at": nil,
+		}).Error
+}
+
+// Fail records a failed attempt and schedules a retry or marks the job dead
+func (r *TranscodingJobRepositoryImpl) Fail(ctx context.Context, jobID uuid.UUID, owner string, errMsg string, retryAt *time.Time) error {
+	updates := map[string]interface{}{
+		"status":           entity.TranscodingJobDead,
+		"last_error":       errMsg,
+		"lease_owner":      nil,
+		"lease_expires_at": nil,
+	}
+	if retryAt != nil {
+		updates["status"] = entity.TranscodingJobPending
+		updates["run_after"] = *retryAt
+	}
+
+	return r.db.WithContext(ctx).
+		Model(&entity.TranscodingJob{}).
+		Where("job_id = ? AND lease_owner = ? AND status = ?", jobID, owner, entity.TranscodingJobRunning).
+		Updates(updates).Error
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TrendingRepositoryImpl implements TrendingRepository
+type TrendingRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewTrendingRepository creates a new trending repository
+func NewTrendingRepository(db *gorm.DB) *TrendingRepositoryImpl {
+	return &TrendingRepositoryImpl{db: db}
+}
+
+// trendingCandidateRow is a candidate as selected, with its hashtags separated by spaces
+type trendingCandidateRow struct {
+	entity.TrendingCandidate
+	HashtagNames string
+}
+
+// ListCandidates retrieves the videos the trending job scores. video_views
+// stores the completion rate in percent.
+func (r *TrendingRepositoryImpl) ListCandidates(ctx context.Context, createdAfter, viewsSince time.Time, afterID uuid.UUID, limit int) ([]*entity.TrendingCandidate, error) {
+	var rows []*trendingCandidateRow
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT v.video_id, v.region, v.category, v.created_at,
+			v.view_count, v.like_count, v.comment_count, v.share_count,
+			COALESCE((
+				SELECT AVG(vv.completion_rate) FROM video_views vv
+				WHERE vv.video_id = v.video_id AND vv.created_at >= ?
+			), 0) / 100 AS completion_rate,
+			COALESCE((
+				SELECT string_agg(h.name, ' ') FROM video_hashtags vh
+				JOIN hashtags h ON h.hashtag_id = vh.hashtag_id
+				WHERE vh.video_id = v.video_id
+			), '') AS hashtag_names
+		FROM videos v
+		WHERE v.is_public = TRUE AND v.encoding_status = ? AND v.created_at >= ? AND v.video_id > ?
+		ORDER BY v.video_id
+		LIMIT ?`,
+		viewsSince, entity.EncodingStatusReady, createdAfter, afterID, limit,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*entity.TrendingCandidate, len(rows))
+	for i, row := range rows {
+		candidates[i] = &row.TrendingCandidate
+		candidates[i].Hashtags = strings.Fields(row.HashtagNames)
+	}
+	return candidates, nil
+}
+
+// ScoresAt retrieves the snapshot of each video closest to at without being
+// later, falling back to its first snapshot
+func (r *TrendingRepositoryImpl) ScoresAt(ctx context.Context, videoIDs []uuid.UUID, at time.Time) (map[uuid.UUID]*entity.TrendingScore, error) {
+	byVideo := make(map[uuid.UUID]*entity.TrendingScore, len(videoIDs))
+	if len(videoIDs) == 0 {
+		return byVideo, nil
+	}
+
+	var scores []*entity.TrendingScore
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (video_id) * FROM trending_scores
+		WHERE video_id IN ?
+		ORDER BY video_id, computed_at <= ? DESC,
+			CASE WHEN computed_at <= ? THEN computed_at END DESC, computed_at`,
+		videoIDs, at, at,
+	).Scan(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, score := range scores {
+		byVideo[score.VideoID] = score
+	}
+	return byVideo, nil
+}
+
+// SaveScores inserts a batch of snapshots
+func (r *TrendingRepositoryImpl) SaveScores(ctx context.Context, scores []*entity.TrendingScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(scores, 500).Error
+}
+
+// TopScores retrieves the best scores of the last run
+func (r *TrendingRepositoryImpl) TopScores(ctx context.Context, region string, limit int) ([]*entity.TrendingScore, error) {
+	query := r.db.WithContext(ctx).
+		Where("computed_at = (SELECT MAX(computed_at) FROM trending_scores)")
+	if region != "" {
+		query = query.Where("region = ?", region)
+	}
+
+	var scores []*entity.TrendingScore
+	err := query.
+		Order("trending_score DESC").
+		Limit(limit).
+		Find(&scores).Error
+	return scores, err
+}
+
+// PruneScores thins and deletes old snapshots; the first snapshot of each hour is kept
+func (r *TrendingRepositoryImpl) PruneScores(ctx context.Context, thinBefore, deleteBefore time.Time) (int64, error) {
+	var pruned int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("computed_at < ?", deleteBefore).Delete(&entity.TrendingScore{})
+		if result.Error != nil {
+			return result.Error
+		}
+		pruned = result.RowsAffected
+
+		result = tx.Exec(`
+			DELETE FROM trending_scores t
+			WHERE t.computed_at < ? AND EXISTS (
+				SELECT 1 FROM trending_scores o
+				WHERE o.video_id = t.video_id
+					AND date_trunc('hour', o.computed_at) = date_trunc('hour', t.computed_at)
+					AND o.computed_at < t.computed_at
+			)`, thinBefore)
+		pruned += result.RowsAffected
+		return result.Error
+	})
+	return pruned, err
+}
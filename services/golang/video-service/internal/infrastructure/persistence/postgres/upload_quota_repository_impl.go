@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UploadQuotaRepositoryImpl implements UploadQuotaRepository
+type UploadQuotaRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUploadQuotaRepository creates a new upload quota repository
+func NewUploadQuotaRepository(db *gorm.DB) *UploadQuotaRepositoryImpl {
+	return &UploadQuotaRepositoryImpl{db: db}
+}
+
+// Consume charges an upload to the daily quota. The usage row is locked while
+// the limits are checked, so concurrent uploads cannot both squeeze under them.
+func (r *UploadQuotaRepositoryImpl) Consume(ctx context.Context, userID uuid.UUID, day time.Time, count int, bytes int64, maxCount int, maxBytes int64) (*entity.UploadQuota, bool, error) {
+	var quota entity.UploadQuota
+	allowed := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.UploadQuota{
+			UserID: userID,
+			Day:    day,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND day = ?", userID, day).
+			First(&quota).Error
+		if err != nil {
+			return err
+		}
+
+		if maxCount > 0 && quota.UploadCount+count > maxCount {
+			return nil
+		}
+		if maxBytes > 0 && quota.UploadBytes+bytes > maxBytes {
+			return nil
+		}
+		allowed = true
+
+		quota.UploadCount += count
+		quota.UploadBytes += bytes
+		return tx.Model(&quota).
+			Where("user_id = ? AND day = ?", userID, day).
+			Updates(map[string]interface{}{
+				"upload_count": quota.UploadCount,
+				"upload_bytes": quota.UploadBytes,
+				"updated_at":   gorm.Expr("now()"),
+			}).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &quota, allowed, nil
+}
+
+// Refund gives back consumed uploads and bytes, never going below zero
+func (r *UploadQuotaRepositoryImpl) Refund(ctx context.Context, userID uuid.UUID, day time.Time, count int, bytes int64) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.UploadQuota{}).
+		Where("user_id = ? AND day = ?", userID, day).
+		Updates(map[string]interface{}{
+			"upload_count": gorm.Expr("GREATEST(upload_count - ?, 0)", count),
+			"upload_bytes": gorm.Expr("GREATEST(upload_bytes - ?, 0)", bytes),
+			"updated_at":   gorm.Expr("now()"),
+		}).
+		Error
+}
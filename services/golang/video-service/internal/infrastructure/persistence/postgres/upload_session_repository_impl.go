@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UploadSessionRepositoryImpl implements UploadSessionRepository
+type UploadSessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUploadSessionRepository creates a new upload session repository
+func NewUploadSessionRepository(db *gorm.DB) *UploadSessionRepositoryImpl {
+	return &UploadSessionRepositoryImpl{db: db}
+}
+
+// Create creates a new upload session
+func (r *UploadSessionRepositoryImpl) Create(ctx context.Context, session *entity.UploadSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// GetByID retrieves an upload session by ID
+func (r *UploadSessionRepositoryImpl) GetByID(ctx context.Context, sessionID uuid.UUID) (*entity.UploadSession, error) {
+	var session entity.UploadSession
+	err := r.db.WithContext(ctx).Where("session_id = ?", sessionID).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByVideoID retrieves the upload session of a video
+func (r *UploadSessionRepositoryImpl) GetByVideoID(ctx context.Context, videoID uuid.UUID) (*entity.UploadSession, error) {
+	var session entity.UploadSession
+	err := r.db.WithContext(ctx).Where("video_id = ?", videoID).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// TransitionStatus updates the session status if it still has the expected one
+func (r *UploadSessionRepositoryImpl) TransitionStatus(ctx context.Context, sessionID uuid.UUID, from, to string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.UploadSession{}).
+		Where("session_id = ? AND status = ?", sessionID, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SavePart records a received part, replacing a previous upload of the same part
+func (r *UploadSessionRepositoryImpl) SavePart(ctx context.Context, part *entity.UploadPart) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "session_id"}, {Name: "part_number"}},
+			DoUpdates: clause.AssignmentColumns([]string{"etag", "size", "created_at"}),
+		}).
+		Create(part).Error
+}
+
+// GetParts retrieves the received parts of a session ordered by part number
+func (r *UploadSessionRepositoryImpl) GetParts(ctx context.Context, sessionID uuid.UUID) ([]*entity.UploadPart, error) {
+	var parts []*entity.UploadPart
+	err := r.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("part_number ASC").
+		Find(&parts).Error
+	return parts, err
+}
+
+// GetExpired retrieves unfinished sessions whose TTL passed before the given time
+func (r *UploadSessionRepositoryImpl) GetExpired(ctx context.Context, before time.Time, limit int) ([]*entity.UploadSession, error) {
+	var sessions []*entity.UploadSession
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", entity.UploadSessionActive, before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&sessions).Error
+	return sessions, err
+}
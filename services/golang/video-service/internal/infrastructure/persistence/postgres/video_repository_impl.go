@@ -2,11 +2,15 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 
+	"tiktok-clone/shared/common/pagination"
 	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // VideoRepositoryImpl implements VideoRepository
@@ -34,53 +38,377 @@ func (r *VideoRepositoryImpl) GetByID(ctx context.Context, videoID uuid.UUID) (*
 	return &video, nil
 }
 
-// GetByUserID retrieves videos by user ID
-func (r *VideoRepositoryImpl) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Video, error) {
+// GetByIDs retrieves videos by ID
+func (r *VideoRepositoryImpl) GetByIDs(ctx context.Context, videoIDs []uuid.UUID) ([]*entity.Video, error) {
+	var videos []*entity.Video
+	if len(videoIDs) == 0 {
+		return videos, nil
+	}
+	err := r.db.WithContext(ctx).Where("video_id IN ?", videoIDs).Find(&videos).Error
+	return videos, err
+}
+
+// unlistedStatuses are never listed, even to their owner: videos without a
+// file and taken down videos
+var unlistedStatuses = []entity.EncodingStatus{entity.EncodingStatusPendingUpload, entity.EncodingStatusRemoved}
+
+// GetByUserID retrieves the unpinned videos of a user with one of the given
+// visibility levels and statuses, using keyset pagination over (created_at, video_id)
+func (r *VideoRepositoryImpl) GetByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus, after *pagination.Cursor, limit int) ([]*entity.Video, error) {
+	query := r.db.WithContext(ctx).
+		Where("user_id = ? AND visibility IN ? AND encoding_status IN ?", userID, visibilities, statuses).
+		Where("pinned_at IS NULL")
+	if after != nil {
+		query = query.Where("(created_at, video_id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var videos []*entity.Video
+	err := query.
+		Order("created_at DESC, video_id DESC").
+		Limit(limit).
+		Find(&videos).Error
+	return videos, err
+}
+
+// GetPinnedByUserID retrieves the pinned videos of a user with one of the given visibility levels and statuses
+func (r *VideoRepositoryImpl) GetPinnedByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus) ([]*entity.Video, error) {
 	var videos []*entity.Video
 	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND is_public = ?", userID, true).
-		Order("created_at DESC").
+		Where("user_id = ? AND visibility IN ? AND encoding_status IN ?", userID, visibilities, statuses).
+		Where("pinned_at IS NOT NULL").
+		Order("pinned_at DESC").
+		Find(&videos).Error
+	return videos, err
+}
+
+// CountByUserID counts the videos listed by GetByUserID and GetPinnedByUserID
+func (r *VideoRepositoryImpl) CountByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.Video{}).
+		Where("user_id = ? AND visibility IN ? AND encoding_status IN ?", userID, visibilities, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+// ownerSortColumn returns the column an owner video list is sorted by before video_id
+func ownerSortColumn(sort repository.VideoSort) string {
+	switch sort {
+	case repository.VideoSortMostViewed:
+		return "view_count"
+	case repository.VideoSortMostLiked:
+		return "like_count"
+	default:
+		return "created_at"
+	}
+}
+
+// ownerVideos selects the videos of a user matching filter
+func ownerVideos(db *gorm.DB, userID uuid.UUID, filter repository.OwnerVideoFilter) *gorm.DB {
+	query := db.Model(&entity.Video{}).Where("user_id = ?", userID)
+	if len(filter.Visibilities) > 0 {
+		query = query.Where("visibility IN ?", filter.Visibilities)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("encoding_status IN ?", filter.Statuses)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	return query
+}
+
+// ListByOwner retrieves the videos of a user matching filter, using keyset
+// pagination over (sort column, video_id)
+func (r *VideoRepositoryImpl) ListByOwner(ctx context.Context, userID uuid.UUID, filter repository.OwnerVideoFilter, after *pagination.Cursor, limit int) ([]*entity.Video, error) {
+	column := ownerSortColumn(filter.Sort)
+	query := ownerVideos(r.db.WithContext(ctx), userID, filter)
+	if after != nil {
+		var key interface{} = after.CreatedAt
+		if column != "created_at" {
+			key = after.Score
+		}
+		query = query.Where(fmt.Sprintf("(%s, video_id) < (?, ?)", column), key, after.ID)
+	}
+
+	var videos []*entity.Video
+	err := query.
+		Order(column + " DESC, video_id DESC").
 		Limit(limit).
-		Offset(offset).
 		Find(&videos).Error
 	return videos, err
 }
 
+// CountByOwner counts the videos listed by ListByOwner
+func (r *VideoRepositoryImpl) CountByOwner(ctx context.Context, userID uuid.UUID, filter repository.OwnerVideoFilter) (int64, error) {
+	var count int64
+	err := ownerVideos(r.db.WithContext(ctx), userID, filter).Count(&count).Error
+	return count, err
+}
+
+// Pin pins a video unless the user already has maxPinned listed pinned videos
+func (r *VideoRepositoryImpl) Pin(ctx context.Context, videoID, userID uuid.UUID, maxPinned int) (bool, error) {
+	pinned := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes the pins of a user, so two requests cannot both take the last slot
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "pin:"+userID.String()).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		err := tx.Model(&entity.Video{}).
+			Where("user_id = ? AND pinned_at IS NOT NULL AND encoding_status NOT IN ? AND video_id <> ?", userID, unlistedStatuses, videoID).
+			Count(&count).Error
+		if err != nil || count >= int64(maxPinned) {
+			return err
+		}
+		pinned = true
+
+		return tx.Model(&entity.Video{}).
+			Where("video_id = ? AND pinned_at IS NULL", videoID).
+			Update("pinned_at", gorm.Expr("now()")).
+			Error
+	})
+	return pinned, err
+}
+
+// Unpin unpins a video
+func (r *VideoRepositoryImpl) Unpin(ctx context.Context, videoID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Video{}).
+		Where("video_id = ?", videoID).
+		Update("pinned_at", nil).
+		Error
+}
+
+// unsavedColumns are only changed by the counters, by jobs and by their own
+// RPCs, so saving a video loaded earlier does not undo the views and likes
+// counted, the status transitions, renditions, artwork and download rendered,
+// or the pin set in between
+var unsavedColumns = []string{
+	"view_count", "like_count", "comment_count", "share_count",
+	"encoding_status", "renditions_prefix", "fingerprint", "download_key", "pinned_at",
+	"thumbnail_key", "thumbnail_url", "cover_prefix", "cover_at_ms", "preview_key", "storyboard_key",
+}
+
 // Update updates a video
 func (r *VideoRepositoryImpl) Update(ctx context.Context, video *entity.Video) error {
-	return r.db.WithContext(ctx).Save(video).Error
+	return r.db.WithContext(ctx).Omit(unsavedColumns...).Save(video).Error
 }
 
-// Delete deletes a video
+// UpdateDetails saves the fields of a video its owner edits without touching
+// columns that concurrent requests and jobs may have changed
+func (r *VideoRepositoryImpl) UpdateDetails(ctx context.Context, video *entity.Video) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Video{}).
+		Where("video_id = ?", video.VideoID).
+		Updates(map[string]interface{}{
+			"title":          video.Title,
+			"description":    video.Description,
+			"category":       video.Category,
+			"visibility":     video.Visibility,
+			"is_public":      video.IsPublic,
+			"allow_comments": video.AllowComments,
+			"allow_duet":     video.AllowDuet,
+			"allow_stitch":   video.AllowStitch,
+			"allow_download": video.AllowDownload,
+			"updated_at":     gorm.Expr("now()"),
+		}).
+		Error
+}
+
+// Delete deletes a video, and uncounts it from the usage of its hashtags
+// before its video_hashtags rows cascade
 func (r *VideoRepositoryImpl) Delete(ctx context.Context, videoID uuid.UUID) error {
-	return r.db.WithContext(ctx).Where("video_id = ?", videoID).Delete(&entity.Video{}).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locks the video first, as SetVideoHashtags does, so the two cannot deadlock
+		if err := tx.Exec("SELECT 1 FROM videos WHERE video_id = ? FOR UPDATE", videoID).Error; err != nil {
+			return err
+		}
+		err := tx.Exec(`
+			UPDATE hashtags SET usage_count = GREATEST(COALESCE(usage_count, 0) - 1, 0)
+			WHERE hashtag_id IN (SELECT hashtag_id FROM video_hashtags WHERE video_id = ?)`,
+			videoID,
+		).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("video_id = ?", videoID).Delete(&entity.Video{}).Error
+	})
 }
 
-// GetTrending retrieves trending videos
-func (r *VideoRepositoryImpl) GetTrending(ctx context.Context, limit int) ([]*entity.Video, error) {
-	var videos []*entity.Video
+// Like inserts the like unless it exists
+func (r *VideoRepositoryImpl) Like(ctx context.Context, videoID, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.VideoLike{
+		UserID:  userID,
+		VideoID: videoID,
+	})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Unlike deletes the like, if any
+func (r *VideoRepositoryImpl) Unlike(ctx context.Context, videoID, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("video_id = ? AND user_id = ?", videoID, userID).
+		Delete(&entity.VideoLike{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsLiked checks if a user likes a video
+func (r *VideoRepositoryImpl) IsLiked(ctx context.Context, videoID, userID uuid.UUID) (bool, error) {
+	var count int64
 	err := r.db.WithContext(ctx).
-		Where("is_public = ? AND encoding_status = ?", true, "completed").
-		Order("view_count DESC, created_at DESC").
+		Model(&entity.VideoLike{}).
+		Where("video_id = ? AND user_id = ?", videoID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListLikes retrieves a page of the likes of a user
+func (r *VideoRepositoryImpl) ListLikes(ctx context.Context, userID uuid.UUID, after *pagination.Cursor, limit int) ([]*entity.VideoLike, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if after != nil {
+		query = query.Where("(created_at, video_id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var likes []*entity.VideoLike
+	err := query.
+		Order("created_at DESC, video_id DESC").
 		Limit(limit).
-		Find(&videos).Error
-	return videos, err
+		Find(&likes).Error
+	return likes, err
 }
 
-// IncrementViewCount increments video view count
-func (r *VideoRepositoryImpl) IncrementViewCount(ctx context.Context, videoID uuid.UUID) error {
+// CountLikes counts the likes of a user
+func (r *VideoRepositoryImpl) CountLikes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.VideoLike{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateEncodingStatus moves a video from one encoding status to another and
+// records the transition. It reports false, changing nothing, if the video is
+// no longer in the from status.
+func (r *VideoRepositoryImpl) UpdateEncodingStatus(ctx context.Context, videoID uuid.UUID, from, to entity.EncodingStatus, reason string) (bool, error) {
+	updated := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Video{}).
+			Where("video_id = ? AND encoding_status = ?", videoID, from).
+			Updates(map[string]interface{}{
+				"encoding_status": to,
+				"updated_at":      gorm.Expr("now()"),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+
+		return tx.Create(&entity.VideoStatusHistory{
+			VideoID:    videoID,
+			FromStatus: from,
+			ToStatus:   to,
+			Reason:     reason,
+		}).Error
+	})
+	return updated, err
+}
+
+// UpdateRenditions records where the transcoded renditions of a video are stored
+func (r *VideoRepositoryImpl) UpdateRenditions(ctx context.Context, videoID uuid.UUID, renditionsPrefix string) error {
 	return r.db.WithContext(ctx).
 		Model(&entity.Video{}).
 		Where("video_id = ?", videoID).
-		UpdateColumn("view_count", gorm.Expr("view_count + ?", 1)).
+		Update("renditions_prefix", renditionsPrefix).
+		Error
+}
+
+// UpdateDownload records where the watermarked download of a video is stored
+func (r *VideoRepositoryImpl) UpdateDownload(ctx context.Context, videoID uuid.UUID, downloadKey string) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Video{}).
+		Where("video_id = ?", videoID).
+		Update("download_key", downloadKey).
+		Error
+}
+
+// UpdateArtwork saves the thumbnail, cover, preview and storyboard fields of a
+// video without touching columns that concurrent requests may have changed
+func (r *VideoRepositoryImpl) UpdateArtwork(ctx context.Context, video *entity.Video) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Video{}).
+		Where("video_id = ?", video.VideoID).
+		Updates(map[string]interface{}{
+			"thumbnail_key":  video.ThumbnailKey,
+			"thumbnail_url":  video.ThumbnailURL,
+			"cover_prefix":   video.CoverPrefix,
+			"cover_at_ms":    video.CoverAtMs,
+			"preview_key":    video.PreviewKey,
+			"storyboard_key": video.StoryboardKey,
+			"updated_at":     gorm.Expr("now()"),
+		}).
 		Error
 }
 
-// UpdateEncodingStatus updates video encoding status
-func (r *VideoRepositoryImpl) UpdateEncodingStatus(ctx context.Context, videoID uuid.UUID, status string) error {
+// UpdateFingerprint stores the perceptual fingerprint of a video
+func (r *VideoRepositoryImpl) UpdateFingerprint(ctx context.Context, videoID uuid.UUID, fingerprint string) error {
 	return r.db.WithContext(ctx).
 		Model(&entity.Video{}).
 		Where("video_id = ?", videoID).
-		Update("encoding_status", status).
+		Update("fingerprint", fingerprint).
 		Error
 }
+
+// FindByContentSHA256 retrieves other videos uploaded with the same file
+func (r *VideoRepositoryImpl) FindByContentSHA256(ctx context.Context, sha256 string, excludeID uuid.UUID, limit int) ([]*entity.Video, error) {
+	var videos []*entity.Video
+	err := r.db.WithContext(ctx).
+		Where("content_sha256 = ? AND video_id <> ?", sha256, excludeID).
+		Order("created_at").
+		Limit(limit).
+		Find(&videos).Error
+	return videos, err
+}
+
+// FindFingerprinted retrieves other fingerprinted videos of similar duration
+func (r *VideoRepositoryImpl) FindFingerprinted(ctx context.Context, minDuration, maxDuration int, excludeID uuid.UUID, limit int) ([]*entity.Video, error) {
+	var videos []*entity.Video
+	err := r.db.WithContext(ctx).
+		Where("duration_seconds BETWEEN ? AND ? AND fingerprint <> '' AND video_id <> ?", minDuration, maxDuration, excludeID).
+		Order("created_at").
+		Limit(limit).
+		Find(&videos).Error
+	return videos, err
+}
+
+// ListByEncodingStatus retrieves one page of the videos in status, oldest first
+func (r *VideoRepositoryImpl) ListByEncodingStatus(ctx context.Context, status entity.EncodingStatus, after *pagination.Cursor, limit int) ([]*entity.Video, error) {
+	query := r.db.WithContext(ctx).Where("encoding_status = ?", status)
+	if after != nil {
+		query = query.Where("(created_at, video_id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var videos []*entity.Video
+	err := query.
+		Order("created_at, video_id").
+		Limit(limit).
+		Find(&videos).Error
+	return videos, err
+}
+
+// CountByEncodingStatus counts the videos in status
+func (r *VideoRepositoryImpl) CountByEncodingStatus(ctx context.Context, status entity.EncodingStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.Video{}).
+		Where("encoding_status = ?", status).
+		Count(&count).Error
+	return count, err
+}
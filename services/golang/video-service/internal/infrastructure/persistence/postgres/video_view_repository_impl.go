@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"context"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+// VideoViewRepositoryImpl implements VideoViewRepository
+type VideoViewRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewVideoViewRepository creates a new view event repository
+func NewVideoViewRepository(db *gorm.DB) *VideoViewRepositoryImpl {
+	return &VideoViewRepositoryImpl{db: db}
+}
+
+// Create stores a view event
+func (r *VideoViewRepositoryImpl) Create(ctx context.Context, view *entity.VideoView) error {
+	return r.db.WithContext(ctx).Create(view).Error
+}
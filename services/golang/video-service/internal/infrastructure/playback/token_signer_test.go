@@ -0,0 +1,99 @@
+package playback
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBaseURL = "https://cdn.test"
+
+// signedToken signs key for scope and returns the token segment of its URL
+func signedToken(t *testing.T, s *TokenSigner, scope, key string, expires time.Time) string {
+	t.Helper()
+	rest, ok := strings.CutPrefix(s.SignURL(scope, key, expires), testBaseURL+PathPrefix)
+	if !ok {
+		t.Fatalf("SignURL() does not start with %s%s", testBaseURL, PathPrefix)
+	}
+	token, signedKey, _ := strings.Cut(rest, "/")
+	if signedKey != key {
+		t.Fatalf("SignURL() signs key %q, want %q", signedKey, key)
+	}
+	return token
+}
+
+func TestTokenSignerVerify(t *testing.T) {
+	signer := NewTokenSigner(testBaseURL+"/", []byte("secret"))
+	now := time.Unix(1700000000, 0)
+	expires := now.Add(time.Minute)
+
+	// The master playlist is signed for its directory, the thumbnail for itself
+	tree := signedToken(t, signer, "videos/v1/hls/", "videos/v1/hls/master.m3u8", expires)
+	object := signedToken(t, signer, "videos/v1/thumb.jpg", "videos/v1/thumb.jpg", expires)
+	// A scope that is not a prefix of the key falls back to the key
+	fallback := signedToken(t, signer, "videos/v2/", "videos/v1/thumb.jpg", expires)
+	expired := signedToken(t, signer, "videos/v1/hls/", "videos/v1/hls/master.m3u8", now.Add(-time.Second))
+	otherKey := signedToken(t, NewTokenSigner(testBaseURL, []byte("other secret")), "videos/v1/hls/", "videos/v1/hls/master.m3u8", expires)
+
+	exp, depth, signature := splitToken(t, tree)
+
+	tests := []struct {
+		name    string
+		path    string
+		now     time.Time
+		wantKey string
+	}{
+		{"signed key", PathPrefix + tree + "/videos/v1/hls/master.m3u8", now, "videos/v1/hls/master.m3u8"},
+		{"variant playlist in scope", PathPrefix + tree + "/videos/v1/hls/360p/index.m3u8", now, "videos/v1/hls/360p/index.m3u8"},
+		{"segment in scope", PathPrefix + tree + "/videos/v1/hls/360p/segment_000.ts", now, "videos/v1/hls/360p/segment_000.ts"},
+		{"valid until expiry", PathPrefix + tree + "/videos/v1/hls/master.m3u8", expires, "videos/v1/hls/master.m3u8"},
+		{"object scope", PathPrefix + object + "/videos/v1/thumb.jpg", now, "videos/v1/thumb.jpg"},
+		{"fallback scope", PathPrefix + fallback + "/videos/v1/thumb.jpg", now, "videos/v1/thumb.jpg"},
+
+		{"sibling directory", PathPrefix + tree + "/videos/v1/original.mp4", now, ""},
+		{"other video", PathPrefix + tree + "/videos/v2/hls/master.m3u8", now, ""},
+		{"sibling of object", PathPrefix + object + "/videos/v1/other.jpg", now, ""},
+		{"outside fallback scope", PathPrefix + fallback + "/videos/v2/thumb.jpg", now, ""},
+		{"expired", PathPrefix + tree + "/videos/v1/hls/master.m3u8", expires.Add(time.Second), ""},
+		{"expired when signed", PathPrefix + expired + "/videos/v1/hls/master.m3u8", now, ""},
+		{"other key", PathPrefix + otherKey + "/videos/v1/hls/master.m3u8", now, ""},
+
+		{"shallower depth", PathPrefix + exp + ".2." + signature + "/videos/v1/original.mp4", now, ""},
+		{"deeper depth", PathPrefix + exp + ".4." + signature + "/videos/v1/hls/360p/segment_000.ts", now, ""},
+		{"zero depth", PathPrefix + exp + ".0." + signature + "/videos/v1/hls/master.m3u8", now, ""},
+		{"depth past key", PathPrefix + exp + "." + depth + "." + signature + "/videos/v1", now, ""},
+		{"later expiry", PathPrefix + "9999999999." + depth + "." + signature + "/videos/v1/hls/master.m3u8", now, ""},
+
+		{"parent segments", PathPrefix + tree + "/videos/v1/hls/../../v2/hls/master.m3u8", now, ""},
+		{"parent segment at end", PathPrefix + tree + "/videos/v1/hls/360p/..", now, ""},
+		{"current segment", PathPrefix + tree + "/videos/v1/hls/./master.m3u8", now, ""},
+		{"empty segment", PathPrefix + tree + "/videos/v1/hls//master.m3u8", now, ""},
+
+		{"no prefix", "/" + tree + "/videos/v1/hls/master.m3u8", now, ""},
+		{"no key", PathPrefix + tree, now, ""},
+		{"empty key", PathPrefix + tree + "/", now, ""},
+		{"missing signature", PathPrefix + exp + "." + depth + "/videos/v1/hls/master.m3u8", now, ""},
+		{"extra token part", PathPrefix + tree + ".x/videos/v1/hls/master.m3u8", now, ""},
+		{"bad expiry", PathPrefix + "soon." + depth + "." + signature + "/videos/v1/hls/master.m3u8", now, ""},
+		{"bad depth", PathPrefix + exp + ".three." + signature + "/videos/v1/hls/master.m3u8", now, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := signer.Verify(tt.path, tt.now)
+			if ok != (tt.wantKey != "") || key != tt.wantKey {
+				t.Errorf("Verify(%q) = %q, %v, want %q, %v", tt.path, key, ok, tt.wantKey, tt.wantKey != "")
+			}
+		})
+	}
+}
+
+// splitToken returns the expiry, depth and signature of a token
+func splitToken(t *testing.T, token string) (string, string, string) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token %q has %d parts, want 3", token, len(parts))
+	}
+	return parts[0], parts[1], parts[2]
+}
@@ -0,0 +1,497 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/mediatype"
+	"tiktok-clone/video-service/internal/domain/storagekey"
+
+	"github.com/google/uuid"
+)
+
+// multipartDir holds the parts of unfinished multipart uploads under the storage root
+const multipartDir = ".multipart"
+
+// LocalStorage implements StorageService on the local filesystem.
+// It is meant for development and tests: objects are written under a root
+// directory and served by Handler, which also accepts presigned uploads.
+// Like a private bucket, it only serves objects to signed URLs.
+type LocalStorage struct {
+	root       string
+	publicURL  string
+	signingKey []byte
+}
+
+// NewLocalStorage creates a new local filesystem storage service
+func NewLocalStorage(root, publicURL string, signingKey []byte) (*LocalStorage, error) {
+	if err := os.MkdirAll(filepath.Join(root, multipartDir), 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		root:       root,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		signingKey: signingKey,
+	}, nil
+}
+
+// UploadVideo writes video to the local filesystem
+func (s *LocalStorage) UploadVideo(ctx context.Context, videoID uuid.UUID, data []byte, contentType string) (string, error) {
+	key := storagekey.Original(videoID)
+
+	if err := s.writeObject(key, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// UploadVideoStream writes video to the local filesystem while reading it from r
+func (s *LocalStorage) UploadVideoStream(ctx context.Context, videoID uuid.UUID, r io.Reader, contentType string) (string, error) {
+	key := storagekey.Original(videoID)
+
+	if err := s.writeObject(key, r); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// UploadThumbnail writes thumbnail to the local filesystem
+func (s *LocalStorage) UploadThumbnail(ctx context.Context, videoID uuid.UUID, data []byte, contentType string) (string, error) {
+	key := storagekey.Thumbnail(videoID, mediatype.Extension(contentType))
+
+	if err := s.writeObject(key, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Put writes an object to the local filesystem while reading it from r
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	return s.writeObject(key, r)
+}
+
+// Get opens an object stored on the local filesystem; the caller must close it
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filePath, ok := s.objectPath(key)
+	if !ok {
+		return nil, fmt.Errorf("invalid object key: %s", key)
+	}
+	return os.Open(filePath)
+}
+
+// Exists checks if an object is stored on the local filesystem
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	filePath, ok := s.objectPath(key)
+	if !ok {
+		return false, fmt.Errorf("invalid object key: %s", key)
+	}
+
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Delete deletes an object from the local filesystem
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	filePath, ok := s.objectPath(key)
+	if !ok {
+		return fmt.Errorf("invalid object key: %s", key)
+	}
+
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DeletePrefix deletes every object whose key starts with prefix
+func (s *LocalStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	dir := prefix[:strings.LastIndex(prefix, "/")+1]
+	if dir == "" {
+		return fmt.Errorf("invalid object prefix: %s", prefix)
+	}
+	dirPath, ok := s.objectPath(strings.TrimSuffix(dir, "/"))
+	if !ok {
+		return fmt.Errorf("invalid object prefix: %s", prefix)
+	}
+
+	// A directory prefix maps to exactly one directory
+	if prefix == dir {
+		return os.RemoveAll(dirPath)
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(dir+entry.Name(), prefix) {
+			if err := os.RemoveAll(filepath.Join(dirPath, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CreateVideoUpload starts a multipart upload for the original video file
+func (s *LocalStorage) CreateVideoUpload(ctx context.Context, videoID uuid.UUID) (string, error) {
+	uploadID := uuid.New().String()
+
+	if err := os.MkdirAll(s.partDir(uploadID), 0o755); err != nil {
+		return "", err
+	}
+	return uploadID, nil
+}
+
+// UploadVideoPart stores one numbered part of a multipart upload and returns its ETag
+func (s *LocalStorage) UploadVideoPart(ctx context.Context, videoID uuid.UUID, uploadID string, partNumber int, data []byte) (string, error) {
+	return s.writePart(uploadID, partNumber, bytes.NewReader(data))
+}
+
+// CompleteVideoUpload concatenates the uploaded parts into the original video file
+func (s *LocalStorage) CompleteVideoUpload(ctx context.Context, videoID uuid.UUID, uploadID string, parts []*entity.UploadPart) (string, error) {
+	key := storagekey.Original(videoID)
+
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		f, err := os.Open(s.partPath(uploadID, part.PartNumber))
+		if err != nil {
+			return "", fmt.Errorf("part %d: %w", part.PartNumber, err)
+		}
+		defer f.Close()
+
+		etag, err := fileMD5(f)
+		if err != nil {
+			return "", err
+		}
+		if etag != part.ETag {
+			return "", fmt.Errorf("part %d: etag mismatch", part.PartNumber)
+		}
+		readers = append(readers, f)
+	}
+
+	if err := s.writeObject(key, io.MultiReader(readers...)); err != nil {
+		return "", err
+	}
+
+	if err := os.RemoveAll(s.partDir(uploadID)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// AbortVideoUpload removes the parts stored so far
+func (s *LocalStorage) AbortVideoUpload(ctx context.Context, videoID uuid.UUID, uploadID string) error {
+	return os.RemoveAll(s.partDir(uploadID))
+}
+
+// PresignVideoUpload returns a signed PUT URL served by Handler
+func (s *LocalStorage) PresignVideoUpload(ctx context.Context, videoID uuid.UUID, checksumSHA256 string, ttl time.Duration) (string, error) {
+	key := storagekey.Original(videoID)
+
+	params := url.Values{}
+	if checksumSHA256 != "" {
+		params.Set("checksumSHA256", checksumSHA256)
+	}
+	return s.presign(http.MethodPut, key, params, ttl), nil
+}
+
+// PresignVideoPartUpload returns a signed PUT URL for one part of a multipart upload
+func (s *LocalStorage) PresignVideoPartUpload(ctx context.Context, videoID uuid.UUID, uploadID string, partNumber int, ttl time.Duration) (string, error) {
+	key := storagekey.Original(videoID)
+
+	params := url.Values{}
+	params.Set("uploadId", uploadID)
+	params.Set("partNumber", strconv.Itoa(partNumber))
+	return s.presign(http.MethodPut, key, params, ttl), nil
+}
+
+// PresignGetURL returns a GET URL for an object that stops working after ttl
+func (s *LocalStorage) PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if _, ok := s.objectPath(key); !ok {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return s.presign(http.MethodGet, key, url.Values{}, ttl), nil
+}
+
+// StatVideo returns the size and SHA-256 of the stored original video file
+func (s *LocalStorage) StatVideo(ctx context.Context, videoID uuid.UUID) (*entity.ObjectInfo, error) {
+	key := storagekey.Original(videoID)
+
+	filePath, _ := s.objectPath(key)
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.ObjectInfo{
+		Key:            key,
+		URL:            s.URL(key),
+		Size:           size,
+		ContentType:    "video/mp4",
+		ChecksumSHA256: base64.StdEncoding.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// Handler serves stored objects with range-request support to the URLs
+// returned by URL and PresignGetURL, the latter until they expire, and accepts
+// uploads to URLs signed by PresignVideoUpload and PresignVideoPartUpload.
+func (s *LocalStorage) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			if !s.validSignature(http.MethodGet, key, r.URL.Query()) {
+				http.Error(w, "invalid or expired signature", http.StatusForbidden)
+				return
+			}
+			s.serveObject(w, r, key)
+		case http.MethodPut:
+			s.receiveUpload(w, r, key)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// VerifiedHandler serves stored objects without checking signatures, to
+// requests whose access was verified already, such as the path-token URLs
+// checked by playback.TokenSigner.Handler
+func (s *LocalStorage) VerifiedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.serveObject(w, r, strings.TrimPrefix(r.URL.Path, "/"))
+	})
+}
+
+// serveObject writes a stored object, honouring Range and conditional headers
+func (s *LocalStorage) serveObject(w http.ResponseWriter, r *http.Request, key string) {
+	filePath, ok := s.objectPath(key)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// receiveUpload stores the body of a presigned PUT request
+func (s *LocalStorage) receiveUpload(w http.ResponseWriter, r *http.Request, key string) {
+	if _, ok := s.objectPath(key); !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	if !s.validSignature(http.MethodPut, key, query) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	// Part upload of a multipart upload
+	if uploadID := query.Get("uploadId"); uploadID != "" {
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil || partNumber < 1 {
+			http.Error(w, "invalid part number", http.StatusBadRequest)
+			return
+		}
+		if _, err := os.Stat(s.partDir(uploadID)); err != nil {
+			http.Error(w, "no such upload", http.StatusNotFound)
+			return
+		}
+
+		etag, err := s.writePart(uploadID, partNumber, r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Single object upload, verified against the signed checksum if any
+	hash := sha256.New()
+	if err := s.writeObject(key, io.TeeReader(r.Body, hash)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if expected := query.Get("checksumSHA256"); expected != "" {
+		if base64.StdEncoding.EncodeToString(hash.Sum(nil)) != expected {
+			filePath, _ := s.objectPath(key)
+			os.Remove(filePath)
+			http.Error(w, "checksum mismatch", http.StatusBadRequest)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// presign builds a URL whose method and query parameters are covered by an HMAC signature
+func (s *LocalStorage) presign(method, key string, params url.Values, ttl time.Duration) string {
+	params.Set("expires", strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	params.Set("signature", s.sign(method, key, params))
+	return s.objectURL(key) + "?" + params.Encode()
+}
+
+// validSignature checks the signature and expiry of a presigned URL. Only
+// the GET URLs returned by URL do not expire.
+func (s *LocalStorage) validSignature(method, key string, query url.Values) bool {
+	if method != http.MethodGet || query.Has("expires") {
+		expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+		if err != nil || time.Now().Unix() > expires {
+			return false
+		}
+	}
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil {
+		return false
+	}
+	expected, _ := hex.DecodeString(s.sign(method, key, query))
+	return hmac.Equal(signature, expected)
+}
+
+// sign computes the HMAC of the method, object key and every signed parameter
+func (s *LocalStorage) sign(method, key string, params url.Values) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%s\n%s\n", method, key)
+	for _, name := range []string{"uploadId", "partNumber", "checksumSHA256", "expires"} {
+		fmt.Fprintf(mac, "%s=%s\n", name, params.Get(name))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// writeObject atomically writes an object: data goes to a temp file that is
+// renamed into place, so readers never see a partial file
+func (s *LocalStorage) writeObject(key string, r io.Reader) error {
+	filePath, ok := s.objectPath(key)
+	if !ok {
+		return fmt.Errorf("invalid object key: %s", key)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// writePart stores a multipart part and returns its quoted MD5 ETag, like S3
+func (s *LocalStorage) writePart(uploadID string, partNumber int, r io.Reader) (string, error) {
+	f, err := os.Create(s.partPath(uploadID, partNumber))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), r); err != nil {
+		return "", err
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)) + `"`, nil
+}
+
+// objectPath maps an object key to a file path, rejecting keys that escape
+// the root or point into the multipart area
+func (s *LocalStorage) objectPath(key string) (string, bool) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if cleaned == "" || cleaned != key || strings.HasPrefix(cleaned, multipartDir) {
+		return "", false
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), true
+}
+
+func (s *LocalStorage) partDir(uploadID string) string {
+	return filepath.Join(s.root, multipartDir, filepath.Base(uploadID))
+}
+
+func (s *LocalStorage) partPath(uploadID string, partNumber int) string {
+	return filepath.Join(s.partDir(uploadID), strconv.Itoa(partNumber))
+}
+
+// URL returns the URL of an object on the built-in file server, signed
+// without expiry so that only the keys handed out by the service are served
+func (s *LocalStorage) URL(key string) string {
+	return s.objectURL(key) + "?signature=" + s.sign(http.MethodGet, key, url.Values{})
+}
+
+// objectURL returns the unsigned URL of an object on the built-in file server
+func (s *LocalStorage) objectURL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// fileMD5 returns the quoted MD5 ETag of a file and rewinds it
+func fileMD5(f *os.File) (string, error) {
+	hash := md5.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)) + `"`, nil
+}
@@ -4,81 +4,405 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/mediatype"
+	"tiktok-clone/video-service/internal/domain/storagekey"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/google/uuid"
 )
 
+// S3Options configures the connection to AWS S3 or an S3-compatible server such as MinIO
+type S3Options struct {
+	Bucket string
+	Region string
+
+	// Endpoint is the host[:port] of an S3-compatible server; empty means AWS
+	Endpoint       string
+	UseSSL         bool
+	ForcePathStyle bool
+
+	// Static credentials; when empty the default AWS credential chain is used
+	AccessKey string
+	SecretKey string
+
+	// PublicURL is the base URL objects are served from (e.g. a CDN);
+	// when empty it is derived from the endpoint and bucket
+	PublicURL string
+}
+
 // S3Storage implements StorageService using AWS S3
 type S3Storage struct {
 	client     *s3.S3
+	uploader   *s3manager.Uploader
 	bucketName string
 	region     string
+	baseURL    string
 }
 
 // NewS3Storage creates a new S3 storage service
-func NewS3Storage(bucketName, region string) (*S3Storage, error) {
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(region),
-	})
+func NewS3Storage(opts S3Options) (*S3Storage, error) {
+	awsConfig := &aws.Config{
+		Region:           aws.String(opts.Region),
+		S3ForcePathStyle: aws.Bool(opts.ForcePathStyle),
+	}
+	if opts.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(opts.Endpoint)
+		awsConfig.DisableSSL = aws.Bool(!opts.UseSSL)
+	}
+	if opts.AccessKey != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(opts.AccessKey, opts.SecretKey, "")
+	}
+
+	sess, err := session.NewSession(awsConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	client := s3.New(sess)
+
 	return &S3Storage{
-		client:     s3.New(sess),
-		bucketName: bucketName,
-		region:     region,
+		client:     client,
+		uploader:   s3manager.NewUploaderWithClient(client),
+		bucketName: opts.Bucket,
+		region:     opts.Region,
+		baseURL:    objectBaseURL(opts),
 	}, nil
 }
 
+// EnsureBucket creates the bucket if it does not exist yet
+func (s *S3Storage) EnsureBucket(ctx context.Context) error {
+	_, err := s.client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.bucketName),
+	})
+	if err == nil {
+		return nil
+	}
+	if aerr, ok := err.(awserr.Error); !ok || (aerr.Code() != "NotFound" && aerr.Code() != s3.ErrCodeNoSuchBucket) {
+		return err
+	}
+
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(s.bucketName),
+	}
+	// us-east-1 is the default location and must not be sent explicitly
+	if s.region != "" && s.region != "us-east-1" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(s.region),
+		}
+	}
+
+	_, err = s.client.CreateBucketWithContext(ctx, input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeBucketAlreadyOwnedByYou {
+		return nil
+	}
+	return err
+}
+
 // UploadVideo uploads video to S3
-func (s *S3Storage) UploadVideo(ctx context.Context, videoID uuid.UUID, data []byte) (string, error) {
-	key := fmt.Sprintf("videos/%s/original.mp4", videoID.String())
+func (s *S3Storage) UploadVideo(ctx context.Context, videoID uuid.UUID, data []byte, contentType string) (string, error) {
+	key := storagekey.Original(videoID)
 
 	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucketName),
 		Key:         aws.String(key),
 		Body:        bytes.NewReader(data),
-		ContentType: aws.String("video/mp4"),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// UploadVideoStream uploads video to S3 while reading it from r.
+// The uploader sends fixed-size multipart parts, so memory use stays bounded
+// regardless of the video size.
+func (s *S3Storage) UploadVideoStream(ctx context.Context, videoID uuid.UUID, r io.Reader, contentType string) (string, error) {
+	key := storagekey.Original(videoID)
+
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		Body:        r,
+		ContentType: aws.String(contentType),
 	})
 	if err != nil {
 		return "", err
 	}
 
-	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucketName, s.region, key)
-	return url, nil
+	return key, nil
 }
 
 // UploadThumbnail uploads thumbnail to S3
-func (s *S3Storage) UploadThumbnail(ctx context.Context, videoID uuid.UUID, data []byte) (string, error) {
-	key := fmt.Sprintf("videos/%s/thumbnail.jpg", videoID.String())
+func (s *S3Storage) UploadThumbnail(ctx context.Context, videoID uuid.UUID, data []byte, contentType string) (string, error) {
+	key := storagekey.Thumbnail(videoID, mediatype.Extension(contentType))
 
 	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucketName),
 		Key:         aws.String(key),
 		Body:        bytes.NewReader(data),
-		ContentType: aws.String("image/jpeg"),
+		ContentType: aws.String(contentType),
 	})
 	if err != nil {
 		return "", err
 	}
 
-	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucketName, s.region, key)
-	return url, nil
+	return key, nil
 }
 
-// DeleteVideo deletes video from S3
-func (s *S3Storage) DeleteVideo(ctx context.Context, videoURL string) error {
-	// Extract key from URL
-	// This is a simplified implementation
-	key := videoURL // You should parse the URL properly
+// Put uploads an object to S3 while reading it from r
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		Body:        r,
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+// Get opens an object stored in S3; the caller must close it
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// Exists checks if an object is stored in S3
+func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && (aerr.Code() == "NotFound" || aerr.Code() == s3.ErrCodeNoSuchKey) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
 
+// Delete deletes an object from S3
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
 	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucketName),
 		Key:    aws.String(key),
 	})
 	return err
 }
+
+// DeletePrefix deletes every object whose key starts with prefix
+func (s *S3Storage) DeletePrefix(ctx context.Context, prefix string) error {
+	var deleteErr error
+
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		if len(page.Contents) == 0 {
+			return true
+		}
+
+		// A listing page holds at most 1000 keys, the DeleteObjects limit
+		objects := make([]*s3.ObjectIdentifier, len(page.Contents))
+		for i, obj := range page.Contents {
+			objects[i] = &s3.ObjectIdentifier{Key: obj.Key}
+		}
+
+		out, err := s.client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucketName),
+			Delete: &s3.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			deleteErr = err
+			return false
+		}
+		if len(out.Errors) > 0 {
+			deleteErr = fmt.Errorf("delete %s: %s", aws.StringValue(out.Errors[0].Key), aws.StringValue(out.Errors[0].Message))
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return deleteErr
+}
+
+// CreateVideoUpload starts a multipart upload for the original video file
+func (s *S3Storage) CreateVideoUpload(ctx context.Context, videoID uuid.UUID) (string, error) {
+	key := storagekey.Original(videoID)
+
+	out, err := s.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String("video/mp4"),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(out.UploadId), nil
+}
+
+// UploadVideoPart uploads one numbered part of a multipart upload and returns its ETag
+func (s *S3Storage) UploadVideoPart(ctx context.Context, videoID uuid.UUID, uploadID string, partNumber int, data []byte) (string, error) {
+	key := storagekey.Original(videoID)
+
+	out, err := s.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(s.bucketName),
+		Key:        aws.String(key),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int64(int64(partNumber)),
+		Body:       bytes.NewReader(data),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(out.ETag), nil
+}
+
+// CompleteVideoUpload assembles the uploaded parts into the original video file
+func (s *S3Storage) CompleteVideoUpload(ctx context.Context, videoID uuid.UUID, uploadID string, parts []*entity.UploadPart) (string, error) {
+	key := storagekey.Original(videoID)
+
+	completed := make([]*s3.CompletedPart, len(parts))
+	for i, part := range parts {
+		completed[i] = &s3.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int64(int64(part.PartNumber)),
+		}
+	}
+
+	_, err := s.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucketName),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// AbortVideoUpload aborts a multipart upload and frees the parts stored so far
+func (s *S3Storage) AbortVideoUpload(ctx context.Context, videoID uuid.UUID, uploadID string) error {
+	key := storagekey.Original(videoID)
+
+	_, err := s.client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucketName),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	return err
+}
+
+// PresignVideoUpload returns a presigned PUT URL for uploading the original video file in one request.
+// When checksumSHA256 is set, storage rejects a body whose SHA-256 does not match.
+func (s *S3Storage) PresignVideoUpload(ctx context.Context, videoID uuid.UUID, checksumSHA256 string, ttl time.Duration) (string, error) {
+	key := storagekey.Original(videoID)
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String("video/mp4"),
+	}
+	if checksumSHA256 != "" {
+		input.ChecksumSHA256 = aws.String(checksumSHA256)
+	}
+
+	req, _ := s.client.PutObjectRequest(input)
+	req.SetContext(ctx)
+	return req.Presign(ttl)
+}
+
+// PresignVideoPartUpload returns a presigned PUT URL for one part of a multipart upload
+func (s *S3Storage) PresignVideoPartUpload(ctx context.Context, videoID uuid.UUID, uploadID string, partNumber int, ttl time.Duration) (string, error) {
+	key := storagekey.Original(videoID)
+
+	req, _ := s.client.UploadPartRequest(&s3.UploadPartInput{
+		Bucket:     aws.String(s.bucketName),
+		Key:        aws.String(key),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int64(int64(partNumber)),
+	})
+	req.SetContext(ctx)
+	return req.Presign(ttl)
+}
+
+// PresignGetURL returns a presigned GET URL for an object, for private
+// objects that must not be served from the public URL
+func (s *S3Storage) PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	req.SetContext(ctx)
+	return req.Presign(ttl)
+}
+
+// StatVideo returns the size and checksum of the stored original video file
+func (s *S3Storage) StatVideo(ctx context.Context, videoID uuid.UUID) (*entity.ObjectInfo, error) {
+	key := storagekey.Original(videoID)
+
+	out, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(s.bucketName),
+		Key:          aws.String(key),
+		ChecksumMode: aws.String(s3.ChecksumModeEnabled),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.ObjectInfo{
+		Key:            key,
+		URL:            s.URL(key),
+		Size:           aws.Int64Value(out.ContentLength),
+		ContentType:    aws.StringValue(out.ContentType),
+		ChecksumSHA256: aws.StringValue(out.ChecksumSHA256),
+	}, nil
+}
+
+// URL returns the public URL of an object
+func (s *S3Storage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// objectBaseURL returns the URL prefix objects of the bucket are served under
+func objectBaseURL(opts S3Options) string {
+	if opts.PublicURL != "" {
+		return strings.TrimRight(opts.PublicURL, "/")
+	}
+	if opts.Endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com", opts.Bucket, opts.Region)
+	}
+
+	scheme := "http"
+	if opts.UseSSL {
+		scheme = "https"
+	}
+	if opts.ForcePathStyle {
+		return fmt.Sprintf("%s://%s/%s", scheme, opts.Endpoint, opts.Bucket)
+	}
+	return fmt.Sprintf("%s://%s.%s", scheme, opts.Bucket, opts.Endpoint)
+}
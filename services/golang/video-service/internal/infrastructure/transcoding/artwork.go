@@ -0,0 +1,235 @@
+package transcoding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tiktok-clone/video-service/internal/domain/storagekey"
+)
+
+const (
+	// coverSearchWindow bounds how far into the video the automatic cover is looked for
+	coverSearchWindow = 10 * time.Second
+	// coverSkip drops the start of the video, which is often black or fading in
+	coverSkip = 500 * time.Millisecond
+	// sceneThreshold is the ffmpeg scene score above which a frame starts a new shot
+	sceneThreshold = 0.3
+
+	previewSeconds = 3
+	previewWidth   = 320
+	previewFPS     = 12
+
+	spriteTileWidth = 160
+	spriteColumns   = 10
+	spriteMaxTiles  = 100
+)
+
+// FFmpegArtworkRenderer renders cover images, animated previews and scrub
+// sprite sheets by running ffmpeg
+type FFmpegArtworkRenderer struct {
+	ffmpegPath string
+}
+
+// NewFFmpegArtworkRenderer creates a new ffmpeg artwork renderer
+func NewFFmpegArtworkRenderer(ffmpegPath string) *FFmpegArtworkRenderer {
+	return &FFmpegArtworkRenderer{ffmpegPath: ffmpegPath}
+}
+
+// RenderCover writes the frame at `at` into outputDir as one JPEG and one WebP
+// image per storagekey.CoverWidths. A negative `at` picks the frame automatically:
+// the first shot change within the opening seconds, or failing that the most
+// representative frame of that window, which skips black and fading frames.
+// duration is the length of the video in seconds.
+func (r *FFmpegArtworkRenderer) RenderCover(ctx context.Context, inputPath, outputDir string, at time.Duration, duration float64) error {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return err
+	}
+
+	framePath := filepath.Join(outputDir, "frame.png")
+	defer os.Remove(framePath)
+
+	if at >= 0 {
+		if err := r.extractFrameAt(ctx, inputPath, framePath, at); err != nil {
+			return fmt.Errorf("extract frame: %w", err)
+		}
+	} else if err := r.extractAutoFrame(ctx, inputPath, framePath, duration); err != nil {
+		return fmt.Errorf("pick cover frame: %w", err)
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", framePath}
+	for _, width := range storagekey.CoverWidths {
+		// Never upscale: small sources keep their own width
+		scale := fmt.Sprintf("scale='min(%d,iw)':-2", width)
+		args = append(args,
+			"-vf", scale, "-q:v", "3",
+			filepath.Join(outputDir, storagekey.CoverFile(width, storagekey.CoverFormatJPEG)),
+			"-vf", scale, "-c:v", "libwebp", "-quality", "80",
+			filepath.Join(outputDir, storagekey.CoverFile(width, storagekey.CoverFormatWebP)),
+		)
+	}
+	return r.run(ctx, args)
+}
+
+// extractFrameAt writes the frame at the given timestamp
+func (r *FFmpegArtworkRenderer) extractFrameAt(ctx context.Context, inputPath, framePath string, at time.Duration) error {
+	return r.run(ctx, []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-ss", seconds(at), "-i", inputPath,
+		"-frames:v", "1",
+		framePath,
+	})
+}
+
+// extractAutoFrame writes the first frame after a shot change, falling back
+// to ffmpeg's thumbnail filter when the search window has no shot change
+func (r *FFmpegArtworkRenderer) extractAutoFrame(ctx context.Context, inputPath, framePath string, duration float64) error {
+	skip, window := coverSkip, coverSearchWindow
+	if total := time.Duration(duration * float64(time.Second)); total > 0 {
+		if skip > total/4 {
+			skip = 0
+		}
+		if window > total-skip {
+			window = total - skip
+		}
+	}
+
+	input := []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-ss", seconds(skip), "-t", seconds(window), "-i", inputPath,
+	}
+
+	sceneArgs := append(append([]string{}, input...),
+		"-vf", fmt.Sprintf("select='gt(scene,%g)'", sceneThreshold),
+		"-frames:v", "1", "-fps_mode", "vfr",
+		framePath,
+	)
+	if err := r.run(ctx, sceneArgs); err != nil {
+		return err
+	}
+	// ffmpeg writes nothing when no frame passed the filter
+	if _, err := os.Stat(framePath); err == nil {
+		return nil
+	}
+
+	thumbnailArgs := append(append([]string{}, input...),
+		"-vf", "thumbnail=100",
+		"-frames:v", "1",
+		framePath,
+	)
+	return r.run(ctx, thumbnailArgs)
+}
+
+// RenderPreviews writes storagekey.PreviewFile, an animated WebP of a few
+// seconds from early in the video, and storagekey.SpriteFile, a grid of
+// evenly spaced frames indexed by the WebVTT storagekey.StoryboardFile.
+// duration is in seconds; width and height are the display dimensions, 0 if unknown.
+func (r *FFmpegArtworkRenderer) RenderPreviews(ctx context.Context, inputPath, outputDir string, duration float64, width, height int) error {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return err
+	}
+
+	if err := r.renderPreview(ctx, inputPath, filepath.Join(outputDir, storagekey.PreviewFile), duration); err != nil {
+		return fmt.Errorf("animated preview: %w", err)
+	}
+	if err := r.renderStoryboard(ctx, inputPath, outputDir, duration, width, height); err != nil {
+		return fmt.Errorf("storyboard: %w", err)
+	}
+	return nil
+}
+
+// renderPreview encodes a short looping animated WebP
+func (r *FFmpegArtworkRenderer) renderPreview(ctx context.Context, inputPath, outputPath string, duration float64) error {
+	start := 0.0
+	if duration > 2*previewSeconds {
+		start = duration / 4
+	}
+
+	return r.run(ctx, []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-ss", fmt.Sprintf("%.3f", start), "-t", fmt.Sprint(previewSeconds), "-i", inputPath,
+		"-an",
+		"-vf", fmt.Sprintf("fps=%d,scale='min(%d,iw)':-2", previewFPS, previewWidth),
+		"-c:v", "libwebp", "-quality", "60", "-compression_level", "4", "-loop", "0",
+		outputPath,
+	})
+}
+
+// renderStoryboard tiles one frame every interval seconds into a single sprite
+// sheet and writes the WebVTT cues pointing at each tile
+func (r *FFmpegArtworkRenderer) renderStoryboard(ctx context.Context, inputPath, outputDir string, duration float64, width, height int) error {
+	if duration <= 0 {
+		return fmt.Errorf("unknown duration")
+	}
+
+	interval := math.Max(1, math.Ceil(duration/spriteMaxTiles))
+	tiles := int(math.Ceil(duration / interval))
+	rows := (tiles + spriteColumns - 1) / spriteColumns
+
+	tileHeight := spriteTileWidth * 9 / 16
+	if width > 0 && height > 0 {
+		tileHeight = even(spriteTileWidth * height / width)
+	}
+
+	err := r.run(ctx, []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-i", inputPath,
+		"-an",
+		"-vf", fmt.Sprintf("fps=1/%g,scale=%d:%d,tile=%dx%d", interval, spriteTileWidth, tileHeight, spriteColumns, rows),
+		"-frames:v", "1", "-q:v", "5",
+		filepath.Join(outputDir, storagekey.SpriteFile),
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeStoryboard(filepath.Join(outputDir, storagekey.StoryboardFile), tiles, interval, duration, tileHeight)
+}
+
+// writeStoryboard writes the WebVTT index of the sprite sheet. Cue payloads
+// are media fragments relative to the VTT file, so both can move together.
+func writeStoryboard(path string, tiles int, interval, duration float64, tileHeight int) error {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+
+	for i := 0; i < tiles; i++ {
+		start := float64(i) * interval
+		end := math.Min(start+interval, duration)
+		x := (i % spriteColumns) * spriteTileWidth
+		y := (i / spriteColumns) * tileHeight
+
+		fmt.Fprintf(&b, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			vttTimestamp(start), vttTimestamp(end), storagekey.SpriteFile, x, y, spriteTileWidth, tileHeight)
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// vttTimestamp formats seconds as a WebVTT timestamp, hh:mm:ss.ttt
+func vttTimestamp(sec float64) string {
+	ms := int64(math.Round(sec * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// seconds formats a duration as an ffmpeg time argument
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// run executes ffmpeg, returning its error output on failure
+func (r *FFmpegArtworkRenderer) run(ctx context.Context, args []string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.ffmpegPath, args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
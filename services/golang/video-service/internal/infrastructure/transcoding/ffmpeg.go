@@ -0,0 +1,158 @@
+package transcoding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MasterPlaylist is the name of the HLS master playlist written to the output directory
+const MasterPlaylist = "master.m3u8"
+
+// Rendition is one rung of the adaptive-bitrate ladder.
+// Size is the length of the shorter side, so portrait and landscape
+// videos get the same quality at the same rung.
+type Rendition struct {
+	Name         string
+	Size         int
+	VideoBitrate int // kbit/s
+	AudioBitrate int // kbit/s
+}
+
+// DefaultLadder is the HLS ladder produced for every video, from 240p to 1080p
+var DefaultLadder = []Rendition{
+	{Name: "240p", Size: 240, VideoBitrate: 400, AudioBitrate: 64},
+	{Name: "360p", Size: 360, VideoBitrate: 800, AudioBitrate: 96},
+	{Name: "480p", Size: 480, VideoBitrate: 1400, AudioBitrate: 128},
+	{Name: "720p", Size: 720, VideoBitrate: 2800, AudioBitrate: 128},
+	{Name: "1080p", Size: 1080, VideoBitrate: 5000, AudioBitrate: 192},
+}
+
+// FFmpegTranscoder produces HLS renditions by running ffmpeg
+type FFmpegTranscoder struct {
+	ffmpegPath     string
+	segmentSeconds int
+	ladder         []Rendition
+}
+
+// NewFFmpegTranscoder creates a new ffmpeg transcoder using DefaultLadder
+func NewFFmpegTranscoder(ffmpegPath string, segmentSeconds int) *FFmpegTranscoder {
+	return &FFmpegTranscoder{
+		ffmpegPath:     ffmpegPath,
+		segmentSeconds: segmentSeconds,
+		ladder:         DefaultLadder,
+	}
+}
+
+// TranscodeHLS transcodes inputPath into outputDir: one sub-directory with a
+// media playlist and segments per rendition, plus MasterPlaylist referencing
+// them. Renditions larger than the source are skipped, except the smallest.
+// width and height are the source dimensions, 0 if unknown.
+func (t *FFmpegTranscoder) TranscodeHLS(ctx context.Context, inputPath, outputDir string, width, height int) error {
+	renditions := t.renditionsFor(width, height)
+
+	for _, r := range renditions {
+		if err := t.transcodeRendition(ctx, inputPath, outputDir, r); err != nil {
+			return fmt.Errorf("rendition %s: %w", r.Name, err)
+		}
+	}
+
+	return writeMasterPlaylist(filepath.Join(outputDir, MasterPlaylist), renditions, width, height)
+}
+
+// transcodeRendition runs ffmpeg for a single rung of the ladder
+func (t *FFmpegTranscoder) transcodeRendition(ctx context.Context, inputPath, outputDir string, r Rendition) error {
+	dir := filepath.Join(outputDir, r.Name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	// Scale the shorter side to r.Size and keep the aspect ratio (even width/height)
+	scale := fmt.Sprintf("scale=w='if(gt(iw,ih),-2,%d)':h='if(gt(iw,ih),%d,-2)'", r.Size, r.Size)
+	// Keyframes on segment boundaries so every rendition switches cleanly
+	gop := strconv.Itoa(t.segmentSeconds * 30)
+
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-i", inputPath,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-vf", scale,
+		"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "main", "-pix_fmt", "yuv420p",
+		"-b:v", fmt.Sprintf("%dk", r.VideoBitrate),
+		"-maxrate", fmt.Sprintf("%dk", r.VideoBitrate*107/100),
+		"-bufsize", fmt.Sprintf("%dk", r.VideoBitrate*3/2),
+		"-g", gop, "-keyint_min", gop, "-sc_threshold", "0",
+		"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", r.AudioBitrate), "-ac", "2",
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(t.segmentSeconds),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(dir, "segment_%04d.ts"),
+		filepath.Join(dir, "index.m3u8"),
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.ffmpegPath, args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// renditionsFor returns the rungs of the ladder that do not upscale the source
+func (t *FFmpegTranscoder) renditionsFor(width, height int) []Rendition {
+	shortSide := width
+	if height < shortSide {
+		shortSide = height
+	}
+	if shortSide <= 0 {
+		return t.ladder
+	}
+
+	renditions := []Rendition{t.ladder[0]}
+	for _, r := range t.ladder[1:] {
+		if r.Size <= shortSide {
+			renditions = append(renditions, r)
+		}
+	}
+	return renditions
+}
+
+// writeMasterPlaylist writes the HLS master playlist listing every rendition
+func writeMasterPlaylist(path string, renditions []Rendition, width, height int) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+
+	for _, r := range renditions {
+		bandwidth := (r.VideoBitrate + r.AudioBitrate) * 1000
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d", bandwidth)
+		if w, h := scaledSize(width, height, r.Size); w > 0 {
+			fmt.Fprintf(&b, ",RESOLUTION=%dx%d", w, h)
+		}
+		fmt.Fprintf(&b, "\n%s/index.m3u8\n", r.Name)
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// scaledSize mirrors the ffmpeg scale filter: the shorter side becomes size
+// and the other side keeps the aspect ratio, rounded to an even number
+func scaledSize(width, height, size int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	if width > height {
+		return even(width * size / height), size
+	}
+	return size, even(height * size / width)
+}
+
+func even(n int) int {
+	return n &^ 1
+}
@@ -0,0 +1,92 @@
+package transcoding
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// playlistEntries returns the URI lines of an m3u8 playlist
+func playlistEntries(t *testing.T, path string) (string, []string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+
+	var entries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, line)
+		}
+	}
+	return string(data), entries
+}
+
+func TestFFmpegTranscoderTranscodeHLS(t *testing.T) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not found on PATH")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.mp4")
+	generate := exec.CommandContext(ctx, ffmpegPath,
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "testsrc=duration=3:size=640x360:rate=30",
+		"-f", "lavfi", "-i", "sine=frequency=440:duration=3",
+		"-c:v", "libx264", "-pix_fmt", "yuv420p", "-c:a", "aac", "-shortest",
+		inputPath,
+	)
+	if out, err := generate.CombinedOutput(); err != nil {
+		t.Fatalf("generate test clip: %v: %s", err, out)
+	}
+
+	outputDir := filepath.Join(dir, "hls")
+	transcoder := NewFFmpegTranscoder(ffmpegPath, 1)
+	if err := transcoder.TranscodeHLS(ctx, inputPath, outputDir, 640, 360); err != nil {
+		t.Fatalf("TranscodeHLS() error = %v", err)
+	}
+
+	master, renditions := playlistEntries(t, filepath.Join(outputDir, MasterPlaylist))
+	// 640x360 gets the rungs up to 360p and nothing larger
+	wantRenditions := []string{"240p/index.m3u8", "360p/index.m3u8"}
+	if strings.Join(renditions, ",") != strings.Join(wantRenditions, ",") {
+		t.Fatalf("master playlist renditions = %v, want %v", renditions, wantRenditions)
+	}
+	for _, res := range []string{"RESOLUTION=426x240", "RESOLUTION=640x360"} {
+		if !strings.Contains(master, res) {
+			t.Errorf("master playlist missing %s:\n%s", res, master)
+		}
+	}
+
+	for _, rendition := range renditions {
+		playlistPath := filepath.Join(outputDir, rendition)
+		playlist, segments := playlistEntries(t, playlistPath)
+		if !strings.Contains(playlist, "#EXT-X-ENDLIST") {
+			t.Errorf("%s is not a complete VOD playlist:\n%s", rendition, playlist)
+		}
+		if len(segments) < 2 {
+			t.Errorf("%s has %d segments, want at least 2", rendition, len(segments))
+		}
+
+		for _, segment := range segments {
+			info, err := os.Stat(filepath.Join(filepath.Dir(playlistPath), segment))
+			if err != nil {
+				t.Errorf("%s segment %s: %v", rendition, segment, err)
+				continue
+			}
+			if info.Size() == 0 {
+				t.Errorf("%s segment %s is empty", rendition, segment)
+			}
+		}
+	}
+}
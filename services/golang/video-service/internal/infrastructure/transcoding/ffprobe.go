@@ -0,0 +1,155 @@
+package transcoding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+)
+
+// FFprobeProber measures uploaded video files with ffprobe
+type FFprobeProber struct {
+	ffprobePath string
+}
+
+// NewFFprobeProber creates a new ffprobe media prober
+func NewFFprobeProber(ffprobePath string) *FFprobeProber {
+	return &FFprobeProber{ffprobePath: ffprobePath}
+}
+
+// ffprobeOutput is the subset of `ffprobe -print_format json` output we use
+type ffprobeOutput struct {
+	Format struct {
+		FormatName string            `json:"format_name"`
+		Duration   string            `json:"duration"`
+		BitRate    string            `json:"bit_rate"`
+		Tags       map[string]string `json:"tags"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType    string            `json:"codec_type"`
+		CodecName    string            `json:"codec_name"`
+		Width        int               `json:"width"`
+		Height       int               `json:"height"`
+		AvgFrameRate string            `json:"avg_frame_rate"`
+		Tags         map[string]string `json:"tags"`
+		SideDataList []ffprobeSideData `json:"side_data_list"`
+	} `json:"streams"`
+}
+
+type ffprobeSideData struct {
+	Rotation float64 `json:"rotation"`
+}
+
+// Probe reads the container and stream headers of the file at path.
+// Files ffprobe cannot parse, or without a video stream, yield entity.ErrInvalidMedia.
+func (p *FFprobeProber) Probe(ctx context.Context, path string) (*entity.MediaInfo, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.ffprobePath,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format", "-show_streams",
+		path,
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok && ctx.Err() == nil {
+			return nil, fmt.Errorf("%w: %s", entity.ErrInvalidMedia, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("ffprobe: %w", err)
+	}
+
+	var out ffprobeOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+
+	info := &entity.MediaInfo{
+		Container: containerName(out.Format.FormatName, out.Format.Tags["major_brand"]),
+	}
+	info.Duration, _ = strconv.ParseFloat(out.Format.Duration, 64)
+	info.Bitrate, _ = strconv.ParseInt(out.Format.BitRate, 10, 64)
+
+	hasVideo := false
+	for _, stream := range out.Streams {
+		switch stream.CodecType {
+		case "video":
+			if hasVideo {
+				continue
+			}
+			hasVideo = true
+
+			info.VideoCodec = stream.CodecName
+			info.FrameRate = parseRational(stream.AvgFrameRate)
+			info.Rotation = streamRotation(stream.Tags["rotate"], stream.SideDataList)
+			info.Width, info.Height = stream.Width, stream.Height
+			if info.Rotation == 90 || info.Rotation == 270 {
+				info.Width, info.Height = stream.Height, stream.Width
+			}
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = stream.CodecName
+			}
+		}
+	}
+
+	if !hasVideo {
+		return nil, fmt.Errorf("%w: no video stream", entity.ErrInvalidMedia)
+	}
+	return info, nil
+}
+
+// containerName turns ffprobe's demuxer name list into a single container name
+func containerName(formatName, majorBrand string) string {
+	switch {
+	case strings.HasPrefix(formatName, "mov,mp4"):
+		if strings.TrimSpace(majorBrand) == "qt" {
+			return "mov"
+		}
+		return "mp4"
+	case strings.HasPrefix(formatName, "matroska,webm"):
+		return "webm"
+	default:
+		return strings.SplitN(formatName, ",", 2)[0]
+	}
+}
+
+// streamRotation returns the clockwise display rotation of a video stream.
+// Older ffprobe versions report it as a "rotate" tag, newer ones as side data
+// holding the counter-clockwise display matrix rotation.
+func streamRotation(rotateTag string, sideData []ffprobeSideData) int {
+	degrees := 0
+	if rotateTag != "" {
+		degrees, _ = strconv.Atoi(rotateTag)
+	} else {
+		for _, data := range sideData {
+			if data.Rotation != 0 {
+				degrees = -int(data.Rotation)
+				break
+			}
+		}
+	}
+	return ((degrees % 360) + 360) % 360
+}
+
+// parseRational parses an ffprobe rational such as "30000/1001"
+func parseRational(s string) float64 {
+	num, den, ok := strings.Cut(s, "/")
+	if !ok {
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	}
+
+	n, err1 := strconv.ParseFloat(num, 64)
+	d, err2 := strconv.ParseFloat(den, 64)
+	if err1 != nil || err2 != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
@@ -0,0 +1,81 @@
+package transcoding
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+)
+
+// dHash compares each pixel with its right neighbour on a 9x8 grayscale thumbnail
+const (
+	dHashWidth  = 9
+	dHashHeight = 8
+)
+
+// FFmpegFingerprinter computes perceptual video fingerprints by running ffmpeg
+type FFmpegFingerprinter struct {
+	ffmpegPath string
+}
+
+// NewFFmpegFingerprinter creates a new ffmpeg fingerprinter
+func NewFFmpegFingerprinter(ffmpegPath string) *FFmpegFingerprinter {
+	return &FFmpegFingerprinter{ffmpegPath: ffmpegPath}
+}
+
+// Fingerprint samples entity.FingerprintFrames evenly spaced frames of the
+// video and returns their concatenated difference hashes, hex encoded.
+// duration is the length of the video in seconds.
+func (f *FFmpegFingerprinter) Fingerprint(ctx context.Context, inputPath string, duration float64) (string, error) {
+	if duration <= 0 {
+		return "", fmt.Errorf("unknown duration")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, f.ffmpegPath,
+		"-hide_banner", "-loglevel", "error",
+		"-i", inputPath,
+		"-an",
+		"-vf", fmt.Sprintf("fps=%g,scale=%d:%d:flags=area,format=gray",
+			float64(entity.FingerprintFrames)/duration, dHashWidth, dHashHeight),
+		"-frames:v", fmt.Sprint(entity.FingerprintFrames),
+		"-f", "rawvideo", "pipe:1",
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	frameSize := dHashWidth * dHashHeight
+	frames := stdout.Len() / frameSize
+	if frames == 0 {
+		return "", fmt.Errorf("no frames decoded")
+	}
+
+	hash := make([]byte, 0, frames*entity.FingerprintFrameSize)
+	for i := 0; i < frames; i++ {
+		hash = append(hash, dHash(stdout.Bytes()[i*frameSize:(i+1)*frameSize])...)
+	}
+	return hex.EncodeToString(hash), nil
+}
+
+// dHash returns the 64-bit difference hash of a 9x8 grayscale frame:
+// one bit per pixel, set when it is brighter than its right neighbour
+func dHash(frame []byte) []byte {
+	hash := make([]byte, entity.FingerprintFrameSize)
+	for y := 0; y < dHashHeight; y++ {
+		row := frame[y*dHashWidth : (y+1)*dHashWidth]
+		for x := 0; x < dHashWidth-1; x++ {
+			if row[x] > row[x+1] {
+				hash[y] |= 1 << uint(x)
+			}
+		}
+	}
+	return hash
+}
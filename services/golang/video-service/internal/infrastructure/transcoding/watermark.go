@@ -0,0 +1,60 @@
+package transcoding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// FFmpegWatermarker renders downloadable copies of videos with a text
+// watermark by running ffmpeg
+type FFmpegWatermarker struct {
+	ffmpegPath string
+	fontFile   string
+}
+
+// NewFFmpegWatermarker creates a new ffmpeg watermarker. fontFile is the font
+// of the watermark; when empty ffmpeg looks one up with fontconfig.
+func NewFFmpegWatermarker(ffmpegPath, fontFile string) *FFmpegWatermarker {
+	return &FFmpegWatermarker{ffmpegPath: ffmpegPath, fontFile: fontFile}
+}
+
+// Watermark writes inputPath to outputPath as an H.264/AAC MP4 with text in
+// the lower right corner, sized relative to the video so it reads the same
+// on portrait and landscape videos. The text is passed in a file next to
+// outputPath, so it needs no escaping.
+func (w *FFmpegWatermarker) Watermark(ctx context.Context, inputPath, outputPath, text string) error {
+	textPath := outputPath + ".txt"
+	if err := os.WriteFile(textPath, []byte(text), 0o644); err != nil {
+		return err
+	}
+	defer os.Remove(textPath)
+
+	filter := "drawtext=textfile=" + textPath + ":expansion=none" +
+		":fontcolor=white@0.8:shadowcolor=black@0.5:shadowx=2:shadowy=2" +
+		":fontsize=h/28:x=w-tw-w/30:y=h-th-h/30"
+	if w.fontFile != "" {
+		filter += ":fontfile=" + w.fontFile
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, w.ffmpegPath,
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-i", inputPath,
+		"-vf", filter,
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "23",
+		"-c:a", "aac", "-b:a", "128k",
+		// Moves the index to the front, so downloads start playing at once
+		"-movflags", "+faststart",
+		outputPath,
+	)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Package userservice calls the user service, which owns accounts and the follow graph.
+package userservice
+
+import (
+	"context"
+	"time"
+
+	pb "tiktok-clone/shared/proto"
+
+	"github.com/google/uuid"
+)
+
+// GRPCFollowChecker asks the user service whether one user follows another
+type GRPCFollowChecker struct {
+	client  pb.UserServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCFollowChecker creates a follow checker; every call gives up after timeout
+func NewGRPCFollowChecker(client pb.UserServiceClient, timeout time.Duration) *GRPCFollowChecker {
+	return &GRPCFollowChecker{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+// IsFollowing checks if followerID follows followeeID
+func (c *GRPCFollowChecker) IsFollowing(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.IsFollowing(ctx, &pb.IsFollowingRequest{
+		FollowerId:  followerID.String(),
+		FollowingId: followeeID.String(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.IsFollowing, nil
+}
@@ -0,0 +1,39 @@
+package userservice
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/shared/middleware"
+	pb "tiktok-clone/shared/proto"
+
+	"github.com/google/uuid"
+)
+
+// GRPCRegionPreferences reads the content region users choose in their user service settings
+type GRPCRegionPreferences struct {
+	client  pb.UserServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCRegionPreferences creates a region preference reader; every call gives up after timeout
+func NewGRPCRegionPreferences(client pb.UserServiceClient, timeout time.Duration) *GRPCRegionPreferences {
+	return &GRPCRegionPreferences{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+// PreferredRegion returns the country code userID prefers content from, "" if
+// none or not a valid code
+func (p *GRPCRegionPreferences) PreferredRegion(ctx context.Context, userID uuid.UUID) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	resp, err := p.client.GetUserSettings(ctx, &pb.GetUserSettingsRequest{UserId: userID.String()})
+	if err != nil {
+		return "", err
+	}
+	region, _ := middleware.ParseRegion(resp.PreferredContentRegion)
+	return region, nil
+}
@@ -0,0 +1,48 @@
+package userservice
+
+import (
+	"context"
+	"time"
+
+	pb "tiktok-clone/shared/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCUserLookup resolves usernames with the user service
+type GRPCUserLookup struct {
+	client  pb.UserServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCUserLookup creates a user lookup; every call gives up after timeout
+func NewGRPCUserLookup(client pb.UserServiceClient, timeout time.Duration) *GRPCUserLookup {
+	return &GRPCUserLookup{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+// FindByUsername returns the ID of the active user with username
+func (l *GRPCUserLookup) FindByUsername(ctx context.Context, username string) (uuid.UUID, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	resp, err := l.client.GetUser(ctx, &pb.GetUserRequest{Username: username})
+	if status.Code(err) == codes.NotFound {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	if resp.User == nil || !resp.User.IsActive {
+		return uuid.Nil, false, nil
+	}
+	userID, err := uuid.Parse(resp.User.Id)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	return userID, true, nil
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// reconcileBatchSize is how many videos are recounted per query
+const reconcileBatchSize = 500
+
+// CounterStore interface for the write-behind view, like and share counters.
+// Deltas are counted at once and handed to the flusher in batches; a batch
+// stays in the store until it is deleted, so a failed flush is retried.
+type CounterStore interface {
+	Increment(ctx context.Context, videoID uuid.UUID, delta entity.VideoCounts) error
+	// Totals returns the current counts of videos, given the counts stored in
+	// the database, which seed the totals not cached yet
+	Totals(ctx context.Context, stored map[uuid.UUID]entity.VideoCounts) (map[uuid.UUID]entity.VideoCounts, error)
+	// PendingBatches moves the deltas counted so far to a new batch and
+	// returns every batch not deleted yet
+	PendingBatches(ctx context.Context) ([]*CounterBatch, error)
+	DeleteBatch(ctx context.Context, batchID string) error
+	// HasPending returns which videos have deltas not flushed yet
+	HasPending(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	// Forget drops the cached totals of videos
+	Forget(ctx context.Context, videoIDs []uuid.UUID) error
+}
+
+// CounterBatch is a set of counter deltas flushed together
+type CounterBatch struct {
+	ID     string
+	Deltas map[uuid.UUID]entity.VideoCounts
+}
+
+// CounterUseCase flushes the counters to the database and corrects their drift
+type CounterUseCase struct {
+	counterRepo   repository.CounterRepository
+	counterStore  CounterStore
+	counterConfig config.CounterConfig
+}
+
+// NewCounterUseCase creates a new counter use case
+func NewCounterUseCase(counterRepo repository.CounterRepository, counterStore CounterStore, counterConfig config.CounterConfig) *CounterUseCase {
+	return &CounterUseCase{
+		counterRepo:   counterRepo,
+		counterStore:  counterStore,
+		counterConfig: counterConfig,
+	}
+}
+
+// FlushCounters adds the pending counter deltas to the videos. A batch is
+// deleted from the store only once applied, and the database remembers the
+// batches it applied, so a crash between the two neither loses nor doubles
+// deltas. It runs periodically.
+func (uc *CounterUseCase) FlushCounters(ctx context.Context) error {
+	batches, err := uc.counterStore.PendingBatches(ctx)
+	if err != nil {
+		return fmt.Errorf("take counter batches: %w", err)
+	}
+
+	for _, batch := range batches {
+		applied, err := uc.counterRepo.ApplyBatch(ctx, batch.ID, batch.Deltas)
+		if err != nil {
+			return fmt.Errorf("apply counter batch %s: %w", batch.ID, err)
+		}
+		if !applied {
+			logger.ForContext(ctx).Warn("Counter batch was applied before", zap.String("batchID", batch.ID))
+		}
+		if err := uc.counterStore.DeleteBatch(ctx, batch.ID); err != nil {
+			return fmt.Errorf("delete counter batch %s: %w", batch.ID, err)
+		}
+	}
+	return nil
+}
+
+// ReconcileCounters recomputes the counts of every video from its likes,
+// shares and counted view events and corrects the stored ones. Like and share
+// counts are set to the likes and shares; view counts are only raised, since views counted before view
+// events were recorded have no event. Videos with deltas not flushed yet are
+// left to the next run. It runs periodically.
+func (uc *CounterUseCase) ReconcileCounters(ctx context.Context) error {
+	corrected := 0
+
+	afterID := uuid.Nil
+	for {
+		recounts, err := uc.counterRepo.Recount(ctx, afterID, reconcileBatchSize)
+		if err != nil {
+			return fmt.Errorf("recount videos: %w", err)
+		}
+		if len(recounts) == 0 {
+			break
+		}
+		afterID = recounts[len(recounts)-1].VideoID
+
+		var drifted []*entity.VideoRecount
+		for _, recount := range recounts {
+			if recount.LikeCount != recount.Likes || recount.ShareCount != recount.Shares || recount.ViewCount < recount.CountedViews {
+				drifted = append(drifted, recount)
+			}
+		}
+		if len(drifted) == 0 {
+			continue
+		}
+
+		videoIDs := make([]uuid.UUID, len(drifted))
+		for i, recount := range drifted {
+			videoIDs[i] = recount.VideoID
+		}
+		pending, err := uc.counterStore.HasPending(ctx, videoIDs)
+		if err != nil {
+			return fmt.Errorf("check pending counters: %w", err)
+		}
+
+		var fixed []uuid.UUID
+		for _, recount := range drifted {
+			if pending[recount.VideoID] {
+				continue
+			}
+			counts := entity.VideoCounts{Views: recount.ViewCount, Likes: recount.Likes, Shares: recount.Shares}
+			if counts.Views < recount.CountedViews {
+				counts.Views = recount.CountedViews
+			}
+			if err := uc.counterRepo.SetCounts(ctx, recount.VideoID, counts); err != nil {
+				return fmt.Errorf("correct counts of video %s: %w", recount.VideoID, err)
+			}
+			fixed = append(fixed, recount.VideoID)
+		}
+		// The cached totals are seeded again from the corrected counts
+		if err := uc.counterStore.Forget(ctx, fixed); err != nil {
+			return fmt.Errorf("forget cached counters: %w", err)
+		}
+		corrected += len(fixed)
+	}
+
+	pruned, err := uc.counterRepo.PruneBatches(ctx, time.Now().Add(-uc.counterConfig.BatchRetention))
+	if err != nil {
+		return fmt.Errorf("prune counter batches: %w", err)
+	}
+
+	logger.ForContext(ctx).Info("Counters reconciled", zap.Int("corrected", corrected), zap.Int64("prunedBatches", pruned))
+	return nil
+}
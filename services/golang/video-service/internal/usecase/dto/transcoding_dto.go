@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// TranscodingJobResponse represents the state of a transcoding or cover job
+type TranscodingJobResponse struct {
+	JobID       string    `json:"job_id"`
+	VideoID     string    `json:"video_id"`
+	Kind        string    `json:"kind"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"max_attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextRunAt   time.Time `json:"next_run_at,omitempty"` // set while a pending job waits to run or retry
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
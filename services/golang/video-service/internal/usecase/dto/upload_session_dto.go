@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateUploadSessionRequest represents a request to start a resumable upload
+type CreateUploadSessionRequest struct {
+	UserID          uuid.UUID
+	Title           string
+	Description     string
+	DurationSeconds int
+	Width           int
+	Height          int
+	FileSize        int64
+}
+
+// UploadChunkRequest represents one numbered part of a resumable upload
+type UploadChunkRequest struct {
+	UserID     uuid.UUID
+	SessionID  uuid.UUID
+	PartNumber int
+	Data       []byte
+}
+
+// CompleteUploadRequest represents a request to finish a resumable upload
+type CompleteUploadRequest struct {
+	UserID        uuid.UUID
+	Region        string // country the video is uploaded from, "" if unknown
+	SessionID     uuid.UUID
+	ThumbnailData []byte
+}
+
+// UploadSessionResponse represents the state of a resumable upload
+type UploadSessionResponse struct {
+	UploadID      string    `json:"upload_id"`
+	VideoID       string    `json:"video_id"`
+	ChunkSize     int64     `json:"chunk_size"`
+	TotalParts    int       `json:"total_parts"`
+	ReceivedParts []int     `json:"received_parts"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// RequestUploadURLRequest represents a request for presigned direct-to-storage upload URLs
+type RequestUploadURLRequest struct {
+	UserID          uuid.UUID
+	Region          string // country the video is uploaded from, "" if unknown
+	Title           string
+	Description     string
+	DurationSeconds int
+	Width           int
+	Height          int
+	FileSize        int64
+	ChecksumSHA256  string // base64 encoded SHA-256 of the whole file, optional
+}
+
+// PresignedPart represents the upload URL of one multipart part
+type PresignedPart struct {
+	PartNumber int    `json:"part_number"`
+	URL        string `json:"url"`
+}
+
+// UploadURLResponse represents presigned upload URLs for a pending video.
+// Exactly one of UploadURL (single PUT) or Parts (multipart) is set.
+type UploadURLResponse struct {
+	VideoID   string            `json:"video_id"`
+	UploadID  string            `json:"upload_id"`
+	UploadURL string            `json:"upload_url,omitempty"`
+	Parts     []PresignedPart   `json:"parts,omitempty"`
+	ChunkSize int64             `json:"chunk_size"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// CompletedPart represents a part the client uploaded with a presigned URL
+type CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// ConfirmUploadRequest represents a request to confirm a presigned upload
+type ConfirmUploadRequest struct {
+	UserID  uuid.UUID
+	VideoID uuid.UUID
+	Parts   []CompletedPart // required for multipart uploads
+}
@@ -8,33 +8,117 @@ import (
 
 // UploadVideoRequest represents video upload request
 type UploadVideoRequest struct {
-	UserID          uuid.UUID
-	Title           string
-	Description     string
-	VideoData       []byte
-	ThumbnailData   []byte
-	DurationSeconds int
-	Width           int
-	Height          int
+	UserID        uuid.UUID
+	Region        string // country the video is uploaded from, "" if unknown
+	Title         string
+	Description   string
+	Tags          []string // hashtags added to those of the description, with or without '#'
+	VideoData     []byte
+	ThumbnailData []byte
+}
+
+// UploadVideoStreamRequest represents the metadata of a streamed video upload
+type UploadVideoStreamRequest struct {
+	UserID        uuid.UUID
+	Region        string
+	Title         string
+	Description   string
+	Tags          []string
+	ThumbnailData []byte
+	FileSize      int64
 }
 
 // VideoResponse represents video response
 type VideoResponse struct {
-	VideoID         string    `json:"video_id"`
-	UserID          string    `json:"user_id"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	VideoURL        string    `json:"video_url"`
-	ThumbnailURL    string    `json:"thumbnail_url"`
-	DurationSeconds int       `json:"duration_seconds"`
-	Width           int       `json:"width"`
-	Height          int       `json:"height"`
-	EncodingStatus  string    `json:"encoding_status"`
-	ViewCount       int64     `json:"view_count"`
-	LikeCount       int64     `json:"like_count"`
-	CommentCount    int64     `json:"comment_count"`
-	ShareCount      int64     `json:"share_count"`
-	CreatedAt       time.Time `json:"created_at"`
+	VideoID         string        `json:"video_id"`
+	UserID          string        `json:"user_id"`
+	Title           string        `json:"title"`
+	Description     string        `json:"description"`
+	VideoURL        string        `json:"video_url"`
+	ThumbnailURL    string        `json:"thumbnail_url"`
+	PlaylistURL     string        `json:"playlist_url,omitempty"` // HLS master playlist, set once transcoded
+	Covers          []CoverImage  `json:"covers,omitempty"`
+	PreviewURL      string        `json:"preview_url,omitempty"`    // Animated WebP preview
+	StoryboardURL   string        `json:"storyboard_url,omitempty"` // WebVTT index of the scrub sprite sheet
+	URLsExpireAt    *time.Time    `json:"urls_expire_at,omitempty"` // Set when the URLs are signed, for private videos
+	DurationSeconds int           `json:"duration_seconds"`
+	Width           int           `json:"width"`
+	Height          int           `json:"height"`
+	EncodingStatus  string        `json:"encoding_status"`
+	Visibility      string        `json:"visibility"`
+	IsPinned        bool          `json:"is_pinned"`
+	Category        string        `json:"category"`
+	Hashtags        []string      `json:"hashtags,omitempty"`
+	Mentions        []MentionSpan `json:"mentions,omitempty"`
+	IsLiked         bool          `json:"is_liked"` // whether the caller likes the video
+	AllowDownload   bool          `json:"allow_download"`
+	ViewCount       int64         `json:"view_count"`
+	LikeCount       int64         `json:"like_count"`
+	CommentCount    int64         `json:"comment_count"`
+	ShareCount      int64         `json:"share_count"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+// VideoListResponse is one page of a video list
+type VideoListResponse struct {
+	Videos     []*VideoResponse `json:"videos"`
+	Total      int64            `json:"total"`                 // size of the whole list
+	NextCursor string           `json:"next_cursor,omitempty"` // pass back to get the next page
+	HasMore    bool             `json:"has_more"`
+}
+
+// ListMyVideosRequest filters and orders the caller's own videos; empty fields match every video
+type ListMyVideosRequest struct {
+	Visibilities []string
+	Statuses     []string   // encoding statuses
+	CreatedFrom  *time.Time // inclusive
+	CreatedTo    *time.Time // exclusive
+	Sort         string     // newest (default), most_viewed or most_liked
+	Cursor       string
+	Limit        int
+}
+
+// TrendingVideosRequest selects a trending list. Hashtag and Category are
+// exclusive; without either the list of Region is served, else of ClientRegion.
+type TrendingVideosRequest struct {
+	Region       string // requested ISO country code, "" for the caller's own
+	ClientRegion string // country the request came from, used without a preferred region
+	Category     string
+	Hashtag      string
+	Window       string // hour, day or week; "" for the default ranking
+	Cursor       string
+	Limit        int
+}
+
+// TrendingVideosResponse is one page of a trending list
+type TrendingVideosResponse struct {
+	Videos     []*VideoResponse `json:"videos"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	HasMore    bool             `json:"has_more"`
+	Region     string           `json:"region,omitempty"` // region of the list served, "" for the global one
+}
+
+// MentionSpan is an @username in the description, at rune offsets Start to
+// End (exclusive) covering the '@'
+type MentionSpan struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+// CoverImage is one size and format of the video cover
+type CoverImage struct {
+	Width  int    `json:"width"`
+	Format string `json:"format"` // jpg or webp
+	URL    string `json:"url"`
+}
+
+// DuplicateVideoResponse represents a video matching another one
+type DuplicateVideoResponse struct {
+	Video    *VideoResponse `json:"video"`
+	Match    string         `json:"match"`              // exact (same file) or similar (close fingerprint)
+	Distance int            `json:"distance,omitempty"` // mean differing fingerprint bits per frame, 0-64
 }
 
 // UpdateVideoRequest represents video update request
@@ -43,7 +127,78 @@ type UpdateVideoRequest struct {
 	Title         *string
 	Description   *string
 	IsPublic      *bool
+	Visibility    *string // public, followers or private; takes precedence over IsPublic
 	AllowComments *bool
 	AllowDuet     *bool
 	AllowStitch   *bool
+	Category      *string // one of the fixed categories, "" to clear
+	AllowDownload *bool
+	// Tags replaces the hashtags set besides the description; nil keeps them
+	Tags *[]string
+}
+
+// LikeResponse is the like state of a video after a like or unlike
+type LikeResponse struct {
+	IsLiked   bool  `json:"is_liked"`
+	LikeCount int64 `json:"like_count"`
+}
+
+// VideoStatsResponse represents the counts of a video
+type VideoStatsResponse struct {
+	ViewCount    int64 `json:"view_count"`
+	LikeCount    int64 `json:"like_count"`
+	CommentCount int64 `json:"comment_count"`
+	ShareCount   int64 `json:"share_count"`
+	IsLiked      bool  `json:"is_liked"`
+}
+
+// RecordViewRequest represents a view event reported by a player
+type RecordViewRequest struct {
+	VideoID        uuid.UUID
+	WatchDuration  time.Duration
+	CompletionRate float64 // percent of the video watched; above 100 for replays
+	DeviceType     string
+	SessionID      string // player session, identifies anonymous viewers
+	ClientIP       string
+	UserAgent      string
+}
+
+// ShareVideoRequest represents a share of a video
+type ShareVideoRequest struct {
+	VideoID  uuid.UUID
+	Channel  string // copy_link, message or external_app
+	Download bool   // whether the share includes the watermarked file
+}
+
+// ShareResponse is the short link of a share
+type ShareResponse struct {
+	Code       string `json:"code"`
+	ShareURL   string `json:"share_url"`
+	ShareCount int64  `json:"share_count"`
+	// DownloadURL is the watermarked file, for shares that asked for it. It is
+	// empty while the file is rendered for the first time, with DownloadPending set.
+	DownloadURL     string `json:"download_url,omitempty"`
+	DownloadPending bool   `json:"download_pending,omitempty"`
+}
+
+// ShareLinkResponse is where a share link leads
+type ShareLinkResponse struct {
+	VideoID     string `json:"video_id"`
+	RedirectURL string `json:"redirect_url"`
+	ClickCount  int64  `json:"click_count"`
+}
+
+// HashtagResponse represents a hashtag page header
+type HashtagResponse struct {
+	Name       string    `json:"name"`
+	UsageCount int64     `json:"usage_count"` // videos tagged with it
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HashtagVideosRequest selects a page of the videos of a hashtag
+type HashtagVideosRequest struct {
+	Hashtag string // with or without '#'
+	Sort    string // recent (default) or popular
+	Cursor  string
+	Limit   int
 }
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// similarFingerprintDistance is the largest mean number of differing dHash
+	// bits per frame (out of 64) for two videos to count as near duplicates
+	similarFingerprintDistance = 10
+	// similarDurationTolerance bounds the duration difference, in seconds, of
+	// near-duplicate candidates; fingerprints sample frames relative to the duration
+	similarDurationTolerance = 1
+
+	maxExactDuplicates     = 100
+	maxSimilarCandidates   = 500
+	maxDuplicatesResponses = 100
+)
+
+// Duplicate match kinds
+const (
+	DuplicateMatchExact   = "exact"
+	DuplicateMatchSimilar = "similar"
+)
+
+// FindDuplicates lists videos with the same file as videoID, then videos
+// whose perceptual fingerprint is close to it, most similar first.
+// Only moderators may call it, as it exposes videos of every user.
+func (uc *VideoUseCase) FindDuplicates(ctx context.Context, videoID uuid.UUID, viewer Viewer) ([]*dto.DuplicateVideoResponse, error) {
+	if err := uc.policy.AuthorizeModeration(viewer); err != nil {
+		return nil, err
+	}
+
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	log := logger.ForContext(ctx).With(zap.String("videoID", videoID.String()))
+	duplicates := []*dto.DuplicateVideoResponse{}
+	seen := map[uuid.UUID]bool{}
+
+	if video.ContentSHA256 != "" {
+		exact, err := uc.videoRepo.FindByContentSHA256(ctx, video.ContentSHA256, videoID, maxExactDuplicates)
+		if err != nil {
+			log.Error("Failed to find exact duplicates", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+		for _, v := range exact {
+			seen[v.VideoID] = true
+			duplicates = append(duplicates, &dto.DuplicateVideoResponse{
+				Video: uc.toVideoResponse(ctx, v),
+				Match: DuplicateMatchExact,
+			})
+		}
+	}
+
+	if video.Fingerprint != "" {
+		candidates, err := uc.videoRepo.FindFingerprinted(ctx,
+			video.DurationSeconds-similarDurationTolerance, video.DurationSeconds+similarDurationTolerance,
+			videoID, maxSimilarCandidates)
+		if err != nil {
+			log.Error("Failed to find similar videos", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+
+		var similar []*dto.DuplicateVideoResponse
+		for _, v := range candidates {
+			if seen[v.VideoID] {
+				continue
+			}
+			distance, ok := entity.FingerprintDistance(video.Fingerprint, v.Fingerprint)
+			if !ok || distance > similarFingerprintDistance {
+				continue
+			}
+			similar = append(similar, &dto.DuplicateVideoResponse{
+				Video:    uc.toVideoResponse(ctx, v),
+				Match:    DuplicateMatchSimilar,
+				Distance: distance,
+			})
+		}
+		sort.SliceStable(similar, func(i, j int) bool {
+			return similar[i].Distance < similar[j].Distance
+		})
+		duplicates = append(duplicates, similar...)
+	}
+
+	if len(duplicates) > maxDuplicatesResponses {
+		duplicates = duplicates[:maxDuplicatesResponses]
+	}
+	return duplicates, nil
+}
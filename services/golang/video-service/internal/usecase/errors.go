@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"net/http"
+
+	"tiktok-clone/shared/common/errors"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Video service specific errors
+var (
+	ErrVideoTooLarge        = errors.NewAppError(2001, "Video exceeds the maximum upload size", http.StatusRequestEntityTooLarge, codes.InvalidArgument)
+	ErrUploadSessionExpired = errors.NewAppError(2002, "Upload session has expired", http.StatusGone, codes.FailedPrecondition)
+	ErrUploadSessionClosed  = errors.NewAppError(2003, "Upload session is no longer accepting parts", http.StatusConflict, codes.FailedPrecondition)
+	ErrUploadIncomplete     = errors.NewAppError(2004, "Upload is missing parts", http.StatusConflict, codes.FailedPrecondition)
+	ErrUploadMismatch       = errors.NewAppError(2005, "Uploaded file does not match the declared size or checksum", http.StatusUnprocessableEntity, codes.InvalidArgument)
+	ErrInvalidStatusChange  = errors.NewAppError(2006, "Video cannot move to the requested encoding status", http.StatusConflict, codes.FailedPrecondition)
+	ErrVideoNotReady        = errors.NewAppError(2007, "Video is still processing", http.StatusConflict, codes.FailedPrecondition)
+	ErrUnsupportedMedia     = errors.NewAppError(2008, "File type is not supported", http.StatusUnsupportedMediaType, codes.InvalidArgument)
+	ErrInvalidDuration      = errors.NewAppError(2009, "Video duration is outside the allowed range", http.StatusUnprocessableEntity, codes.InvalidArgument)
+	ErrThumbnailTooLarge    = errors.NewAppError(2010, "Thumbnail exceeds the maximum size", http.StatusRequestEntityTooLarge, codes.InvalidArgument)
+	ErrUploadQuotaExceeded  = errors.NewAppError(2011, "Daily upload quota exceeded", http.StatusTooManyRequests, codes.ResourceExhausted)
+	ErrPinLimitReached      = errors.NewAppError(2012, "Too many pinned videos", http.StatusConflict, codes.FailedPrecondition)
+	ErrVideoNotPinnable     = errors.NewAppError(2013, "Only uploaded videos that were not removed can be pinned", http.StatusConflict, codes.FailedPrecondition)
+	ErrDownloadNotAllowed   = errors.NewAppError(2014, "The creator does not allow downloading this video", http.StatusForbidden, codes.PermissionDenied)
+)
+
+// Names of the upload rules reported in error violations
+const (
+	RuleMaxFileSize      = "max_file_size"
+	RuleMaxThumbnailSize = "max_thumbnail_size"
+	RuleVideoType        = "video_type"
+	RuleThumbnailType    = "thumbnail_type"
+	RuleMedia            = "media"
+	RuleMinDuration      = "min_duration"
+	RuleMaxDuration      = "max_duration"
+	RuleDailyUploadCount = "daily_upload_count"
+	RuleDailyUploadBytes = "daily_upload_bytes"
+)
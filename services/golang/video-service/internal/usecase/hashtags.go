@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Hashtag suggestion limits
+const (
+	DefaultHashtagSuggestions = 10
+	MaxHashtagSuggestions     = 20
+)
+
+// Orders of a hashtag video list
+const (
+	HashtagSortRecent  = "recent"
+	HashtagSortPopular = "popular"
+)
+
+// GetHashtag retrieves a hashtag by name, with or without the leading '#'
+func (uc *VideoUseCase) GetHashtag(ctx context.Context, name string) (*dto.HashtagResponse, error) {
+	name, ok := entity.NormalizeHashtag(name)
+	if !ok {
+		return nil, errors.ErrInvalidParam
+	}
+	hashtag, err := uc.hashtagRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return toHashtagResponse(hashtag), nil
+}
+
+// ListVideosByHashtag retrieves one page of the public videos of a hashtag,
+// most recent or most viewed first
+func (uc *VideoUseCase) ListVideosByHashtag(ctx context.Context, req *dto.HashtagVideosRequest) (*dto.VideoListResponse, error) {
+	name, ok := entity.NormalizeHashtag(req.Hashtag)
+	if !ok {
+		return nil, errors.ErrInvalidParam
+	}
+	var sort repository.VideoSort
+	switch req.Sort {
+	case "", HashtagSortRecent:
+		sort = repository.VideoSortNewest
+	case HashtagSortPopular:
+		sort = repository.VideoSortMostViewed
+	default:
+		return nil, errors.ErrInvalidParam
+	}
+	limit := pagination.NormalizeLimit(req.Limit)
+
+	scope := "hashtag-videos:" + name + ":" + string(sort)
+	after, err := uc.cursorCodec.Decode(scope, req.Cursor)
+	if err != nil {
+		return nil, errors.ErrInvalidParam
+	}
+
+	hashtag, err := uc.hashtagRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	videos, err := uc.hashtagRepo.ListVideos(ctx, hashtag.HashtagID, sort, after, limit+1)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to list hashtag videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	total, err := uc.hashtagVideoTotal(ctx, hashtag.HashtagID)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to count hashtag videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	return uc.videoPage(ctx, videos, limit, total, func(last *entity.Video) string {
+		cursor := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.VideoID.String()}
+		if sort == repository.VideoSortMostViewed {
+			cursor = pagination.Cursor{Score: last.ViewCount, ID: last.VideoID.String()}
+		}
+		return uc.cursorCodec.Encode(scope, cursor)
+	}), nil
+}
+
+// hashtagVideoTotal returns the number of listed videos of a hashtag, from
+// the cache when possible. Tagging does not invalidate it, so it lags by up
+// to the cache TTL.
+func (uc *VideoUseCase) hashtagVideoTotal(ctx context.Context, hashtagID uuid.UUID) (int64, error) {
+	log := logger.ForContext(ctx)
+	key := "hashtag-videos-total:" + hashtagID.String()
+
+	total, ok, err := uc.countCache.GetCount(ctx, key)
+	if err != nil {
+		log.Warn("Failed to read cached hashtag total", zap.Error(err))
+	}
+	if ok {
+		return total, nil
+	}
+
+	total, err = uc.hashtagRepo.CountVideos(ctx, hashtagID)
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.countCache.SetCount(ctx, key, total, uc.paginationConfig.TotalCacheTTL); err != nil {
+		log.Warn("Failed to cache hashtag total", zap.Error(err))
+	}
+	return total, nil
+}
+
+// SuggestHashtags lists the used hashtags starting with prefix, most used
+// first, to autocomplete what a user is typing
+func (uc *VideoUseCase) SuggestHashtags(ctx context.Context, prefix string, limit int) ([]*dto.HashtagResponse, error) {
+	prefix, ok := entity.NormalizeHashtagPrefix(prefix)
+	if !ok {
+		return nil, errors.ErrInvalidParam
+	}
+	if limit <= 0 {
+		limit = DefaultHashtagSuggestions
+	}
+	if limit > MaxHashtagSuggestions {
+		limit = MaxHashtagSuggestions
+	}
+
+	hashtags, err := uc.hashtagRepo.Suggest(ctx, prefix, limit)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to suggest hashtags", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	responses := make([]*dto.HashtagResponse, len(hashtags))
+	for i, hashtag := range hashtags {
+		responses[i] = toHashtagResponse(hashtag)
+	}
+	return responses, nil
+}
+
+// checkTags validates the tags set explicitly on an upload or update
+func checkTags(tags []string) error {
+	if len(tags) > entity.MaxVideoHashtags {
+		return errors.ErrInvalidParam
+	}
+	for _, tag := range tags {
+		if _, ok := entity.NormalizeHashtag(tag); !ok {
+			return errors.ErrInvalidParam
+		}
+	}
+	return nil
+}
+
+// tagVideo stores the hashtags of a new video, from its description and the
+// explicit tags checked by checkTags. The video is saved already, so failing
+// to tag it is only logged.
+func (uc *VideoUseCase) tagVideo(ctx context.Context, video *entity.Video, explicit []string) {
+	tags, _ := entity.VideoTags(video.VideoID, video.Description, explicit)
+	if err := uc.hashtagRepo.SetVideoHashtags(ctx, video.VideoID, tags); err != nil {
+		logger.ForContext(ctx).Error("Failed to tag video",
+			zap.String("videoID", video.VideoID.String()), zap.Error(err))
+		return
+	}
+	video.Hashtags = tagNames(tags)
+}
+
+// explicitTags returns the tags set explicitly on a video
+func (uc *VideoUseCase) explicitTags(ctx context.Context, videoID uuid.UUID) ([]string, error) {
+	byVideo, err := uc.hashtagRepo.ListByVideos(ctx, []uuid.UUID{videoID})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, tag := range byVideo[videoID] {
+		if tag.Explicit {
+			names = append(names, tag.Name)
+		}
+	}
+	return names, nil
+}
+
+// loadHashtags fills in the hashtags of videos. They are decoration, so
+// videos are returned without them if they cannot be loaded.
+func (uc *VideoUseCase) loadHashtags(ctx context.Context, videos ...*entity.Video) {
+	if len(videos) == 0 {
+		return
+	}
+	ids := make([]uuid.UUID, len(videos))
+	for i, video := range videos {
+		ids[i] = video.VideoID
+	}
+
+	byVideo, err := uc.hashtagRepo.ListByVideos(ctx, ids)
+	if err != nil {
+		logger.ForContext(ctx).Warn("Failed to load hashtags", zap.Error(err))
+		return
+	}
+	for _, video := range videos {
+		video.Hashtags = tagNames(byVideo[video.VideoID])
+	}
+}
+
+// tagNames returns the names of tags
+func tagNames(tags []*entity.VideoTag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// toHashtagResponse converts entity to DTO
+func toHashtagResponse(hashtag *entity.Hashtag) *dto.HashtagResponse {
+	return &dto.HashtagResponse{
+		Name:       hashtag.Name,
+		UsageCount: hashtag.UsageCount,
+		CreatedAt:  hashtag.CreatedAt,
+	}
+}
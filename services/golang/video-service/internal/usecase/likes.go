@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// LikeVideo makes the viewer like a video they may watch. Liking a liked
+// video changes nothing.
+func (uc *VideoUseCase) LikeVideo(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*dto.LikeResponse, error) {
+	if viewer.IsAnonymous() {
+		return nil, errors.ErrUnauthorized
+	}
+
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := uc.policy.AuthorizeView(ctx, viewer, video); err != nil {
+		return nil, err
+	}
+	if !video.IsReady() {
+		return nil, ErrVideoNotReady
+	}
+
+	liked, err := uc.videoRepo.Like(ctx, videoID, viewer.UserID)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to like video", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	if liked {
+		uc.countLike(ctx, videoID, 1)
+	}
+	uc.loadCounts(ctx, video)
+	return &dto.LikeResponse{IsLiked: true, LikeCount: video.LikeCount}, nil
+}
+
+// UnlikeVideo removes the viewer's like of a video, if any. It works on
+// videos the viewer may no longer watch, so they can take likes back.
+func (uc *VideoUseCase) UnlikeVideo(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*dto.LikeResponse, error) {
+	if viewer.IsAnonymous() {
+		return nil, errors.ErrUnauthorized
+	}
+
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	unliked, err := uc.videoRepo.Unlike(ctx, videoID, viewer.UserID)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to unlike video", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	if unliked {
+		uc.countLike(ctx, videoID, -1)
+	}
+	uc.loadCounts(ctx, video)
+	return &dto.LikeResponse{IsLiked: false, LikeCount: video.LikeCount}, nil
+}
+
+// GetVideoStats retrieves the counts of a video and whether the viewer likes it
+func (uc *VideoUseCase) GetVideoStats(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*dto.VideoStatsResponse, error) {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := uc.policy.AuthorizeView(ctx, viewer, video); err != nil {
+		return nil, err
+	}
+	uc.loadCounts(ctx, video)
+
+	return &dto.VideoStatsResponse{
+		ViewCount:    video.ViewCount,
+		LikeCount:    video.LikeCount,
+		CommentCount: video.CommentCount,
+		ShareCount:   video.ShareCount,
+		IsLiked:      uc.isLiked(ctx, videoID, viewer),
+	}, nil
+}
+
+// ListLikedVideos retrieves one page of the videos the viewer liked, most
+// recently liked first. Liked videos the viewer may no longer watch, or that
+// were taken down, are left out of the page but still in the total.
+func (uc *VideoUseCase) ListLikedVideos(ctx context.Context, cursor string, limit int, viewer Viewer) (*dto.VideoListResponse, error) {
+	if viewer.IsAnonymous() {
+		return nil, errors.ErrUnauthorized
+	}
+	limit = pagination.NormalizeLimit(limit)
+	scope := "liked-videos:" + viewer.UserID.String()
+
+	after, err := uc.cursorCodec.Decode(scope, cursor)
+	if err != nil {
+		return nil, errors.ErrInvalidParam
+	}
+
+	// One extra like tells whether another page follows
+	likes, err := uc.videoRepo.ListLikes(ctx, viewer.UserID, after, limit+1)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to list liked videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	total, err := uc.videoRepo.CountLikes(ctx, viewer.UserID)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to count liked videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	response := &dto.VideoListResponse{
+		Videos: []*dto.VideoResponse{},
+		Total:  total,
+	}
+	if len(likes) > limit {
+		likes = likes[:limit]
+		last := likes[len(likes)-1]
+		response.HasMore = true
+		response.NextCursor = uc.cursorCodec.Encode(scope, pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.VideoID.String()})
+	}
+
+	videoIDs := make([]uuid.UUID, len(likes))
+	for i, like := range likes {
+		videoIDs[i] = like.VideoID
+	}
+	videos, err := uc.videoRepo.GetByIDs(ctx, videoIDs)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to load liked videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	byID := make(map[uuid.UUID]*entity.Video, len(videos))
+	for _, video := range videos {
+		byID[video.VideoID] = video
+	}
+	uc.loadCounts(ctx, videos...)
+	uc.loadAnnotations(ctx, videos...)
+	for _, videoID := range videoIDs {
+		video, ok := byID[videoID]
+		if !ok || !video.IsReady() || !uc.policy.CanView(ctx, viewer, video) {
+			continue
+		}
+		videoResponse := uc.toVideoResponse(ctx, video)
+		videoResponse.IsLiked = true
+		response.Videos = append(response.Videos, videoResponse)
+	}
+
+	return response, nil
+}
+
+// isLiked checks if the viewer likes a video; anonymous viewers like nothing.
+// The flag is decoration, so it is false if the check fails.
+func (uc *VideoUseCase) isLiked(ctx context.Context, videoID uuid.UUID, viewer Viewer) bool {
+	if viewer.IsAnonymous() {
+		return false
+	}
+	liked, err := uc.videoRepo.IsLiked(ctx, videoID, viewer.UserID)
+	if err != nil {
+		logger.ForContext(ctx).Warn("Failed to check like", zap.Error(err))
+		return false
+	}
+	return liked
+}
+
+// countLike adds a like or unlike to the like counter. The like row is the
+// source of truth, so if counting fails the reconciliation corrects the count.
+func (uc *VideoUseCase) countLike(ctx context.Context, videoID uuid.UUID, delta int64) {
+	if err := uc.counterStore.Increment(ctx, videoID, entity.VideoCounts{Likes: delta}); err != nil {
+		logger.ForContext(ctx).Warn("Failed to count like", zap.Error(err))
+	}
+}
@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"io"
+)
+
+// limitedReader reads from r until more than limit bytes have been read,
+// then fails with ErrVideoTooLarge so the storage upload is aborted
+type limitedReader struct {
+	r        io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		l.exceeded = true
+		return n, ErrVideoTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CountCache interface for caching the totals of lists
+type CountCache interface {
+	// GetCount returns a cached count, and false if it is not cached
+	GetCount(ctx context.Context, key string) (int64, bool, error)
+	SetCount(ctx context.Context, key string, count int64, ttl time.Duration) error
+	DeleteCounts(ctx context.Context, keys ...string) error
+}
+
+// userVideoListViews are the visibility levels and statuses VideoPolicy
+// returns for the viewers of a user's videos, whose totals are cached separately
+var userVideoListViews = []struct {
+	visibilities []entity.Visibility
+	statuses     []entity.EncodingStatus
+}{
+	{[]entity.Visibility{entity.VisibilityPublic}, readyStatuses},
+	{[]entity.Visibility{entity.VisibilityPublic, entity.VisibilityFollowers}, readyStatuses},
+	{[]entity.Visibility{entity.VisibilityPublic, entity.VisibilityFollowers, entity.VisibilityPrivate}, listedStatuses},
+}
+
+// userVideoTotalKey is the cache key of the number of videos of userID with
+// one of the visibility levels and statuses
+func userVideoTotalKey(userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus) string {
+	levels := make([]string, len(visibilities))
+	for i, v := range visibilities {
+		levels[i] = string(v)
+	}
+	states := make([]string, len(statuses))
+	for i, s := range statuses {
+		states[i] = string(s)
+	}
+	return fmt.Sprintf("user-videos-total:%s:%s:%s", userID, strings.Join(levels, ","), strings.Join(states, ","))
+}
+
+// userVideoTotal returns the number of listed videos of userID with one of
+// the visibility levels and statuses, from the cache when possible
+func (uc *VideoUseCase) userVideoTotal(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus) (int64, error) {
+	log := logger.ForContext(ctx)
+	key := userVideoTotalKey(userID, visibilities, statuses)
+
+	total, ok, err := uc.countCache.GetCount(ctx, key)
+	if err != nil {
+		log.Warn("Failed to read cached video total", zap.Error(err))
+	}
+	if ok {
+		return total, nil
+	}
+
+	total, err = uc.videoRepo.CountByUserID(ctx, userID, visibilities, statuses)
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.countCache.SetCount(ctx, key, total, uc.paginationConfig.TotalCacheTTL); err != nil {
+		log.Warn("Failed to cache video total", zap.Error(err))
+	}
+	return total, nil
+}
+
+// invalidateUserVideoTotals drops the cached totals of userID after one of
+// their videos was listed, unlisted or changed visibility
+func (uc *VideoUseCase) invalidateUserVideoTotals(ctx context.Context, userID uuid.UUID) {
+	keys := make([]string, len(userVideoListViews))
+	for i, view := range userVideoListViews {
+		keys[i] = userVideoTotalKey(userID, view.visibilities, view.statuses)
+	}
+	if err := uc.countCache.DeleteCounts(ctx, keys...); err != nil {
+		logger.ForContext(ctx).Warn("Failed to invalidate cached video totals",
+			zap.String("userID", userID.String()), zap.Error(err))
+	}
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/mediatype"
+	"tiktok-clone/video-service/internal/domain/storagekey"
+
+	"go.uber.org/zap"
+)
+
+// MediaProber interface for measuring uploaded video files
+type MediaProber interface {
+	// Probe returns entity.ErrInvalidMedia for corrupt or unreadable files
+	Probe(ctx context.Context, path string) (*entity.MediaInfo, error)
+}
+
+// Containers and video codecs the transcoder accepts
+var (
+	supportedContainers  = map[string]bool{"mp4": true, "mov": true, "webm": true}
+	supportedVideoCodecs = map[string]bool{"h264": true, "hevc": true, "vp8": true, "vp9": true, "av1": true}
+)
+
+// probeUpload measures the stored original file and writes the real duration,
+// dimensions and codecs onto video, replacing anything the client declared,
+// then moves the file into deduplicated content storage.
+// Files of an unsupported type, corrupt, or outside the duration limits are
+// deleted from storage and rejected with an error naming the violated rule.
+// On success the video holds a content reference, released by releaseContent.
+func (uc *VideoUseCase) probeUpload(ctx context.Context, video *entity.Video) error {
+	log := logger.ForContext(ctx).With(zap.String("videoID", video.VideoID.String()))
+
+	workDir, err := os.MkdirTemp(uc.uploadConfig.WorkDir, "probe-")
+	if err != nil {
+		log.Error("Failed to create probe directory", zap.Error(err))
+		return errors.ErrInternal
+	}
+	defer os.RemoveAll(workDir)
+
+	path := filepath.Join(workDir, "original")
+	sum, err := downloadObjectSHA256(ctx, uc.storageService, video.VideoKey, path)
+	if err != nil {
+		log.Error("Failed to download uploaded video", zap.Error(err))
+		return errors.ErrInternal
+	}
+
+	reject := func(err error) error {
+		log.Warn("Rejected uploaded video", zap.Error(err))
+		if err := uc.storageService.DeletePrefix(ctx, storagekey.VideoPrefix(video.VideoID)); err != nil {
+			log.Warn("Failed to delete rejected video from storage", zap.Error(err))
+		}
+		return err
+	}
+
+	header, err := readHeader(path)
+	if err != nil {
+		log.Error("Failed to read uploaded video", zap.Error(err))
+		return errors.ErrInternal
+	}
+	contentType, err := sniffVideo(header)
+	if err != nil {
+		return reject(err)
+	}
+
+	info, err := uc.mediaProber.Probe(ctx, path)
+	if err == nil {
+		err = validateMediaInfo(info)
+	}
+	if err != nil {
+		if !stderrors.Is(err, entity.ErrInvalidMedia) {
+			log.Error("Failed to probe uploaded video", zap.Error(err))
+			return errors.ErrInternal
+		}
+		return reject(errors.ErrInvalidParam.WithViolation(RuleMedia, err.Error()))
+	}
+	if err := uc.checkDuration(info); err != nil {
+		return reject(err)
+	}
+
+	video.ApplyMediaInfo(info)
+	video.ContentType = contentType
+	video.ContentSHA256 = sum
+
+	if err := uc.storeContent(ctx, video, path); err != nil {
+		log.Error("Failed to store deduplicated content", zap.Error(err))
+		return errors.ErrInternal
+	}
+	return nil
+}
+
+// storeContent points the video at the shared copy of its content, storing
+// the local file at path as that copy if it is not stored yet: on the first
+// upload of the content, or when the upload that created it failed or
+// crashed before storing it. The per-video upload is deleted either way.
+func (uc *VideoUseCase) storeContent(ctx context.Context, video *entity.Video, path string) error {
+	content, err := uc.contentRepo.Acquire(ctx, video.ContentSHA256, storagekey.ContentOriginal(video.ContentSHA256))
+	if err != nil {
+		return fmt.Errorf("acquire content: %w", err)
+	}
+
+	stored := false
+	if content.RefCount > 1 {
+		// Concurrent first uploads may both store it; they write the same bytes
+		if stored, err = uc.storageService.Exists(ctx, content.OriginalKey); err != nil {
+			uc.releaseContent(ctx, video.ContentSHA256)
+			return fmt.Errorf("check original: %w", err)
+		}
+	}
+	if !stored {
+		if err := uc.putFile(ctx, content.OriginalKey, path, video.ContentType); err != nil {
+			uc.releaseContent(ctx, video.ContentSHA256)
+			return fmt.Errorf("store original: %w", err)
+		}
+	} else {
+		logger.ForContext(ctx).Info("Uploaded video duplicates stored content",
+			zap.String("videoID", video.VideoID.String()),
+			zap.String("sha256", video.ContentSHA256))
+	}
+
+	if err := uc.storageService.Delete(ctx, video.VideoKey); err != nil {
+		logger.ForContext(ctx).Warn("Failed to delete per-video upload", zap.Error(err))
+	}
+	video.VideoKey = content.OriginalKey
+	video.VideoURL = uc.storageService.URL(content.OriginalKey)
+	return nil
+}
+
+// releaseContent drops the reference of a video to its shared content and
+// deletes the content from storage once no video uses it
+func (uc *VideoUseCase) releaseContent(ctx context.Context, sha256 string) {
+	if sha256 == "" {
+		return
+	}
+	log := logger.ForContext(ctx).With(zap.String("sha256", sha256))
+
+	removed, err := uc.contentRepo.Release(ctx, sha256)
+	if err != nil {
+		log.Error("Failed to release content", zap.Error(err))
+		return
+	}
+	if !removed {
+		return
+	}
+	if err := uc.storageService.DeletePrefix(ctx, storagekey.Content(sha256)); err != nil {
+		log.Warn("Failed to delete unused content from storage", zap.Error(err))
+	}
+}
+
+// putFile uploads a local file
+func (uc *VideoUseCase) putFile(ctx context.Context, key, path, contentType string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return uc.storageService.Put(ctx, key, f, contentType)
+}
+
+// readHeader returns the first bytes of a local file, for sniffing its type
+func readHeader(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, mediatype.SniffLen)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return header[:n], nil
+}
+
+// validateMediaInfo checks that a probed file can be transcoded and played
+func validateMediaInfo(info *entity.MediaInfo) error {
+	switch {
+	case !supportedContainers[info.Container]:
+		return fmt.Errorf("%w: unsupported container %q", entity.ErrInvalidMedia, info.Container)
+	case !supportedVideoCodecs[info.VideoCodec]:
+		return fmt.Errorf("%w: unsupported video codec %q", entity.ErrInvalidMedia, info.VideoCodec)
+	case info.Duration <= 0:
+		return fmt.Errorf("%w: no duration", entity.ErrInvalidMedia)
+	case info.Width <= 0 || info.Height <= 0:
+		return fmt.Errorf("%w: no picture size", entity.ErrInvalidMedia)
+	}
+	return nil
+}
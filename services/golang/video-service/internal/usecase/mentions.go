@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// UserLookup interface for resolving usernames with the user service
+type UserLookup interface {
+	// FindByUsername returns the ID of the active user with username, and
+	// false if there is none
+	FindByUsername(ctx context.Context, username string) (uuid.UUID, bool, error)
+}
+
+// MentionPublisher interface for telling the notification service about mentions
+type MentionPublisher interface {
+	PublishMention(ctx context.Context, event *entity.MentionEvent) error
+}
+
+// MentionResolver keeps the @username mentions of video descriptions
+type MentionResolver struct {
+	mentionRepo repository.MentionRepository
+	users       UserLookup
+	publisher   MentionPublisher
+}
+
+// NewMentionResolver creates a new mention resolver. users may be nil, in
+// which case mentions are left as plain text.
+func NewMentionResolver(mentionRepo repository.MentionRepository, users UserLookup, publisher MentionPublisher) *MentionResolver {
+	return &MentionResolver{
+		mentionRepo: mentionRepo,
+		users:       users,
+		publisher:   publisher,
+	}
+}
+
+// Update resolves the mentions in the description of a saved video, stores
+// them and notifies the users mentioned for the first time, except the author
+// and, for private videos that only the author may watch, anyone. Mentions
+// are decoration, so failures are logged and the stored mentions kept.
+func (m *MentionResolver) Update(ctx context.Context, video *entity.Video) {
+	if m.users == nil {
+		return
+	}
+	log := logger.ForContext(ctx).With(zap.String("videoID", video.VideoID.String()))
+
+	var mentions []*entity.VideoMention
+	resolved := map[string]uuid.UUID{}
+	for _, span := range entity.ExtractMentions(video.Description) {
+		userID, ok := resolved[span.Username]
+		if !ok {
+			var err error
+			userID, ok, err = m.users.FindByUsername(ctx, span.Username)
+			if err != nil {
+				log.Warn("Failed to resolve mention", zap.String("username", span.Username), zap.Error(err))
+				return
+			}
+			if !ok {
+				userID = uuid.Nil
+			}
+			resolved[span.Username] = userID
+		}
+		if userID == uuid.Nil {
+			continue
+		}
+		mentions = append(mentions, &entity.VideoMention{
+			VideoID:     video.VideoID,
+			UserID:      userID,
+			Username:    span.Username,
+			StartOffset: span.Start,
+			EndOffset:   span.End,
+		})
+	}
+
+	added, err := m.mentionRepo.SetVideoMentions(ctx, video.VideoID, mentions)
+	if err != nil {
+		log.Error("Failed to store mentions", zap.Error(err))
+		return
+	}
+	video.Mentions = mentions
+
+	if video.Visibility == entity.VisibilityPrivate {
+		return
+	}
+	usernames := make(map[uuid.UUID]string, len(mentions))
+	for _, mention := range mentions {
+		usernames[mention.UserID] = mention.Username
+	}
+	for _, userID := range added {
+		if userID == video.UserID {
+			continue
+		}
+		event := &entity.MentionEvent{
+			VideoID:         video.VideoID,
+			AuthorID:        video.UserID,
+			MentionedUserID: userID,
+			Username:        usernames[userID],
+			CreatedAt:       time.Now(),
+		}
+		if err := m.publisher.PublishMention(ctx, event); err != nil {
+			log.Warn("Failed to publish mention", zap.String("userID", userID.String()), zap.Error(err))
+		}
+	}
+}
+
+// Load fills in the mentions of videos, leaving them out if they cannot be loaded
+func (m *MentionResolver) Load(ctx context.Context, videos ...*entity.Video) {
+	if len(videos) == 0 {
+		return
+	}
+	ids := make([]uuid.UUID, len(videos))
+	for i, video := range videos {
+		ids[i] = video.VideoID
+	}
+
+	byVideo, err := m.mentionRepo.ListByVideos(ctx, ids)
+	if err != nil {
+		logger.ForContext(ctx).Warn("Failed to load mentions", zap.Error(err))
+		return
+	}
+	for _, video := range videos {
+		video.Mentions = byVideo[video.VideoID]
+	}
+}
@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"context"
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// fakeUserLookup resolves usernames from an in-memory directory
+type fakeUserLookup struct {
+	users   map[string]uuid.UUID
+	err     error
+	lookups []string
+}
+
+func (f *fakeUserLookup) FindByUsername(ctx context.Context, username string) (uuid.UUID, bool, error) {
+	f.lookups = append(f.lookups, username)
+	if f.err != nil {
+		return uuid.Nil, false, f.err
+	}
+	userID, ok := f.users[username]
+	return userID, ok, nil
+}
+
+// recordingMentionPublisher keeps every published mention event
+type recordingMentionPublisher struct {
+	events []*entity.MentionEvent
+}
+
+func (p *recordingMentionPublisher) PublishMention(ctx context.Context, event *entity.MentionEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+// mentionedUsers returns the mentioned user of every event, in order
+func (p *recordingMentionPublisher) mentionedUsers() []uuid.UUID {
+	var users []uuid.UUID
+	for _, event := range p.events {
+		users = append(users, event.MentionedUserID)
+	}
+	return users
+}
+
+// fakeMentionRepository stores mentions in memory, reporting added users like
+// the postgres repository does
+type fakeMentionRepository struct {
+	byVideo map[uuid.UUID][]*entity.VideoMention
+}
+
+func newFakeMentionRepository() *fakeMentionRepository {
+	return &fakeMentionRepository{byVideo: map[uuid.UUID][]*entity.VideoMention{}}
+}
+
+func (r *fakeMentionRepository) SetVideoMentions(ctx context.Context, videoID uuid.UUID, mentions []*entity.VideoMention) ([]uuid.UUID, error) {
+	known := map[uuid.UUID]bool{}
+	for _, mention := range r.byVideo[videoID] {
+		known[mention.UserID] = true
+	}
+	var added []uuid.UUID
+	for _, mention := range mentions {
+		if !known[mention.UserID] {
+			known[mention.UserID] = true
+			added = append(added, mention.UserID)
+		}
+	}
+	r.byVideo[videoID] = mentions
+	return added, nil
+}
+
+func (r *fakeMentionRepository) ListByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID][]*entity.VideoMention, error) {
+	byVideo := make(map[uuid.UUID][]*entity.VideoMention, len(videoIDs))
+	for _, id := range videoIDs {
+		if mentions, ok := r.byVideo[id]; ok {
+			byVideo[id] = mentions
+		}
+	}
+	return byVideo, nil
+}
+
+func TestMentionResolverUpdate(t *testing.T) {
+	authorID := uuid.New()
+	aliceID := uuid.New()
+	bobID := uuid.New()
+	users := map[string]uuid.UUID{"author": authorID, "alice": aliceID, "bob": bobID}
+
+	tests := []struct {
+		name         string
+		description  string
+		visibility   entity.Visibility
+		wantMentions []entity.VideoMention
+		wantEvents   []uuid.UUID
+	}{
+		{
+			name:        "resolves offsets",
+			description: "hi @alice and @bob",
+			wantMentions: []entity.VideoMention{
+				{UserID: aliceID, Username: "alice", StartOffset: 3, EndOffset: 9},
+				{UserID: bobID, Username: "bob", StartOffset: 14, EndOffset: 18},
+			},
+			wantEvents: []uuid.UUID{aliceID, bobID},
+		},
+		{
+			name:         "skips unknown usernames",
+			description:  "@nobody meets @alice",
+			wantMentions: []entity.VideoMention{{UserID: aliceID, Username: "alice", StartOffset: 14, EndOffset: 20}},
+			wantEvents:   []uuid.UUID{aliceID},
+		},
+		{
+			name:        "notifies repeated mentions once",
+			description: "@alice @alice",
+			wantMentions: []entity.VideoMention{
+				{UserID: aliceID, Username: "alice", StartOffset: 0, EndOffset: 6},
+				{UserID: aliceID, Username: "alice", StartOffset: 7, EndOffset: 13},
+			},
+			wantEvents: []uuid.UUID{aliceID},
+		},
+		{
+			name:        "does not notify the author",
+			description: "me @author with @alice",
+			wantMentions: []entity.VideoMention{
+				{UserID: authorID, Username: "author", StartOffset: 3, EndOffset: 10},
+				{UserID: aliceID, Username: "alice", StartOffset: 16, EndOffset: 22},
+			},
+			wantEvents: []uuid.UUID{aliceID},
+		},
+		{
+			name:         "does not notify for private videos",
+			description:  "@alice",
+			visibility:   entity.VisibilityPrivate,
+			wantMentions: []entity.VideoMention{{UserID: aliceID, Username: "alice", StartOffset: 0, EndOffset: 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup := &fakeUserLookup{users: users}
+			publisher := &recordingMentionPublisher{}
+			resolver := NewMentionResolver(newFakeMentionRepository(), lookup, publisher)
+
+			visibility := tt.visibility
+			if visibility == "" {
+				visibility = entity.VisibilityPublic
+			}
+			video := &entity.Video{VideoID: uuid.New(), UserID: authorID, Description: tt.description, Visibility: visibility}
+			resolver.Update(testContext(), video)
+
+			var got []entity.VideoMention
+			for _, mention := range video.Mentions {
+				if mention.VideoID != video.VideoID {
+					t.Errorf("mention of video %s, want %s", mention.VideoID, video.VideoID)
+				}
+				got = append(got, entity.VideoMention{
+					UserID:      mention.UserID,
+					Username:    mention.Username,
+					StartOffset: mention.StartOffset,
+					EndOffset:   mention.EndOffset,
+				})
+			}
+			if !reflect.DeepEqual(got, tt.wantMentions) {
+				t.Errorf("mentions = %+v, want %+v", got, tt.wantMentions)
+			}
+			if events := publisher.mentionedUsers(); !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("events for %v, want %v", events, tt.wantEvents)
+			}
+			for _, event := range publisher.events {
+				if event.VideoID != video.VideoID || event.AuthorID != authorID {
+					t.Errorf("event = %+v, want video %s by %s", event, video.VideoID, authorID)
+				}
+			}
+		})
+	}
+}
+
+func TestMentionResolverUpdateLooksUpUsernamesOnce(t *testing.T) {
+	lookup := &fakeUserLookup{users: map[string]uuid.UUID{"alice": uuid.New()}}
+	resolver := NewMentionResolver(newFakeMentionRepository(), lookup, &recordingMentionPublisher{})
+
+	video := &entity.Video{VideoID: uuid.New(), UserID: uuid.New(), Description: "@alice @alice @nobody @nobody", Visibility: entity.VisibilityPublic}
+	resolver.Update(testContext(), video)
+
+	if want := []string{"alice", "nobody"}; !reflect.DeepEqual(lookup.lookups, want) {
+		t.Errorf("lookups = %v, want %v", lookup.lookups, want)
+	}
+}
+
+func TestMentionResolverUpdateNotifiesOnlyNewMentionsOnEdit(t *testing.T) {
+	aliceID := uuid.New()
+	bobID := uuid.New()
+	carolID := uuid.New()
+	lookup := &fakeUserLookup{users: map[string]uuid.UUID{"alice": aliceID, "bob": bobID, "carol": carolID}}
+	publisher := &recordingMentionPublisher{}
+	resolver := NewMentionResolver(newFakeMentionRepository(), lookup, publisher)
+
+	video := &entity.Video{VideoID: uuid.New(), UserID: uuid.New(), Description: "@alice @bob", Visibility: entity.VisibilityPublic}
+	resolver.Update(testContext(), video)
+
+	publisher.events = nil
+	video.Description = "@bob and now @carol, @alice again"
+	resolver.Update(testContext(), video)
+
+	if events, want := publisher.mentionedUsers(), []uuid.UUID{carolID}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events for %v, want %v", events, want)
+	}
+	if len(video.Mentions) != 3 {
+		t.Errorf("got %d mentions, want 3", len(video.Mentions))
+	}
+}
+
+func TestMentionResolverUpdateKeepsMentionsOnLookupError(t *testing.T) {
+	aliceID := uuid.New()
+	repo := newFakeMentionRepository()
+	lookup := &fakeUserLookup{users: map[string]uuid.UUID{"alice": aliceID}}
+	publisher := &recordingMentionPublisher{}
+	resolver := NewMentionResolver(repo, lookup, publisher)
+
+	video := &entity.Video{VideoID: uuid.New(), UserID: uuid.New(), Description: "@alice", Visibility: entity.VisibilityPublic}
+	resolver.Update(testContext(), video)
+
+	lookup.err = stderrors.New("user service unavailable")
+	publisher.events = nil
+	video.Description = "@bob instead"
+	resolver.Update(testContext(), video)
+
+	if mentions := repo.byVideo[video.VideoID]; len(mentions) != 1 || mentions[0].UserID != aliceID {
+		t.Errorf("stored mentions = %+v, want the mention of alice kept", mentions)
+	}
+	if len(publisher.events) != 0 {
+		t.Errorf("got %d events, want none", len(publisher.events))
+	}
+}
+
+func TestMentionResolverUpdateWithoutUserLookup(t *testing.T) {
+	repo := newFakeMentionRepository()
+	publisher := &recordingMentionPublisher{}
+	resolver := NewMentionResolver(repo, nil, publisher)
+
+	video := &entity.Video{VideoID: uuid.New(), UserID: uuid.New(), Description: "@alice", Visibility: entity.VisibilityPublic}
+	resolver.Update(testContext(), video)
+
+	if len(repo.byVideo) != 0 || len(publisher.events) != 0 || video.Mentions != nil {
+		t.Errorf("mentions resolved without a user lookup")
+	}
+}
+
+func TestMentionResolverLoad(t *testing.T) {
+	repo := newFakeMentionRepository()
+	mentioned := &entity.Video{VideoID: uuid.New()}
+	plain := &entity.Video{VideoID: uuid.New()}
+	repo.byVideo[mentioned.VideoID] = []*entity.VideoMention{{VideoID: mentioned.VideoID, UserID: uuid.New(), Username: "alice"}}
+
+	NewMentionResolver(repo, nil, nil).Load(testContext(), mentioned, plain)
+
+	if len(mentioned.Mentions) != 1 || mentioned.Mentions[0].Username != "alice" {
+		t.Errorf("mentions = %+v, want the mention of alice", mentioned.Mentions)
+	}
+	if plain.Mentions != nil {
+		t.Errorf("mentions = %+v, want none", plain.Mentions)
+	}
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// MaxPinnedVideos is how many videos a user may pin to the top of their grid
+const MaxPinnedVideos = 3
+
+// ListMyVideos retrieves one page of the viewer's own videos, including
+// private, not yet uploaded, processing and removed ones
+func (uc *VideoUseCase) ListMyVideos(ctx context.Context, req *dto.ListMyVideosRequest, viewer Viewer) (*dto.VideoListResponse, error) {
+	if viewer.IsAnonymous() {
+		return nil, errors.ErrUnauthorized
+	}
+
+	filter, err := ownerVideoFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	limit := pagination.NormalizeLimit(req.Limit)
+
+	// Binding the cursor to the filter keeps it from being replayed in another order
+	filterKey, _ := json.Marshal(filter)
+	scope := "my-videos:" + viewer.UserID.String() + ":" + string(filterKey)
+	after, err := uc.cursorCodec.Decode(scope, req.Cursor)
+	if err != nil {
+		return nil, errors.ErrInvalidParam
+	}
+
+	videos, err := uc.videoRepo.ListByOwner(ctx, viewer.UserID, filter, after, limit+1)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to list own videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	total, err := uc.videoRepo.CountByOwner(ctx, viewer.UserID, filter)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to count own videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	return uc.videoPage(ctx, videos, limit, total, func(last *entity.Video) string {
+		cursor := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.VideoID.String()}
+		switch filter.Sort {
+		case repository.VideoSortMostViewed:
+			cursor = pagination.Cursor{Score: last.ViewCount, ID: last.VideoID.String()}
+		case repository.VideoSortMostLiked:
+			cursor = pagination.Cursor{Score: last.LikeCount, ID: last.VideoID.String()}
+		}
+		return uc.cursorCodec.Encode(scope, cursor)
+	}), nil
+}
+
+// ownerVideoFilter validates the filters of a ListMyVideos request
+func ownerVideoFilter(req *dto.ListMyVideosRequest) (repository.OwnerVideoFilter, error) {
+	filter := repository.OwnerVideoFilter{
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+	}
+
+	for _, value := range req.Visibilities {
+		visibility, err := entity.ParseVisibility(value)
+		if err != nil {
+			return filter, errors.ErrInvalidParam
+		}
+		filter.Visibilities = append(filter.Visibilities, visibility)
+	}
+	for _, value := range req.Statuses {
+		status, err := entity.ParseEncodingStatus(value)
+		if err != nil {
+			return filter, errors.ErrInvalidParam
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return filter, errors.ErrInvalidParam
+	}
+
+	switch sort := repository.VideoSort(req.Sort); sort {
+	case "":
+		filter.Sort = repository.VideoSortNewest
+	case repository.VideoSortNewest, repository.VideoSortMostViewed, repository.VideoSortMostLiked:
+		filter.Sort = sort
+	default:
+		return filter, errors.ErrInvalidParam
+	}
+	return filter, nil
+}
+
+// PinVideo pins a video to the top of its owner's grid; the owner and admins may.
+// At most MaxPinnedVideos videos of a user are pinned at a time.
+func (uc *VideoUseCase) PinVideo(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*dto.VideoResponse, error) {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := uc.policy.AuthorizeEdit(ctx, viewer, video); err != nil {
+		return nil, err
+	}
+	// Such videos are left out of the grid
+	if video.IsPendingUpload() || video.EncodingStatus == entity.EncodingStatusRemoved {
+		return nil, ErrVideoNotPinnable
+	}
+
+	pinned, err := uc.videoRepo.Pin(ctx, video.VideoID, video.UserID, MaxPinnedVideos)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to pin video", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	if !pinned {
+		return nil, ErrPinLimitReached
+	}
+
+	return uc.GetVideo(ctx, videoID, viewer)
+}
+
+// UnpinVideo moves a pinned video back to its place in its owner's grid; the owner and admins may
+func (uc *VideoUseCase) UnpinVideo(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*dto.VideoResponse, error) {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := uc.policy.AuthorizeEdit(ctx, viewer, video); err != nil {
+		return nil, err
+	}
+
+	if video.PinnedAt != nil {
+		if err := uc.videoRepo.Unpin(ctx, video.VideoID); err != nil {
+			logger.ForContext(ctx).Error("Failed to unpin video", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+		video.PinnedAt = nil
+	}
+	uc.loadCounts(ctx, video)
+	uc.loadAnnotations(ctx, video)
+
+	return uc.toVideoResponse(ctx, video), nil
+}
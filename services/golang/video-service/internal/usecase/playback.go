@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// PlaybackURLSigner interface for issuing expiring CDN URLs
+type PlaybackURLSigner interface {
+	// SignURL returns the URL of key, valid until expires for every object
+	// under scope, so URLs resolved relative to it (HLS variant playlists and
+	// segments) work too. scope is key itself or a directory prefix of it.
+	SignURL(scope, key string, expires time.Time) string
+}
+
+// videoURLs builds the URLs handed out for the stored objects of one video.
+// Public videos get plain cacheable URLs; private videos get signed URLs that
+// expire after the configured TTL.
+type videoURLs struct {
+	ctx     context.Context
+	uc      *VideoUseCase
+	signed  bool
+	expires time.Time
+}
+
+// urlsFor returns the URL builder for video
+func (uc *VideoUseCase) urlsFor(ctx context.Context, video *entity.Video) *videoURLs {
+	urls := &videoURLs{ctx: ctx, uc: uc, signed: !video.IsPublic}
+	if urls.signed {
+		urls.expires = time.Now().Add(uc.playbackConfig.URLTTL)
+	}
+	return urls
+}
+
+// object returns the URL of a single object
+func (u *videoURLs) object(key string) string {
+	switch {
+	case !u.signed:
+		return u.uc.storageService.URL(key)
+	case u.uc.urlSigner != nil:
+		return u.uc.urlSigner.SignURL(key, key, u.expires)
+	}
+
+	url, err := u.uc.storageService.PresignGetURL(u.ctx, key, time.Until(u.expires))
+	if err != nil {
+		logger.ForContext(u.ctx).Warn("Failed to presign object URL", zap.String("key", key), zap.Error(err))
+		return ""
+	}
+	return url
+}
+
+// tree returns the URL of key, also valid for the URLs resolved relative to it
+// under prefix. Presigned storage URLs cannot cover those, so without a signer
+// private videos get "" instead; the server refuses to start without one.
+func (u *videoURLs) tree(prefix, key string) string {
+	switch {
+	case !u.signed:
+		return u.uc.storageService.URL(key)
+	case u.uc.urlSigner != nil:
+		return u.uc.urlSigner.SignURL(prefix, key, u.expires)
+	default:
+		return ""
+	}
+}
+
+// expiresAt returns when the signed URLs stop working, nil if they do not
+func (u *videoURLs) expiresAt() *time.Time {
+	if !u.signed {
+		return nil
+	}
+	expires := u.expires
+	return &expires
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Roles granted by the API gateway
+const (
+	// RoleAdmin may see, edit and delete every video
+	RoleAdmin = "admin"
+	// RoleModerator may see and take down every video and use the moderation RPCs
+	RoleModerator = "moderator"
+)
+
+// Viewer is the caller a request is authorized for
+type Viewer struct {
+	UserID uuid.UUID // uuid.Nil for anonymous callers
+	Roles  []string
+}
+
+// IsAnonymous checks if the caller is not signed in
+func (v Viewer) IsAnonymous() bool {
+	return v.UserID == uuid.Nil
+}
+
+// HasRole checks if the caller was granted role
+func (v Viewer) HasRole(role string) bool {
+	for _, r := range v.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// FollowChecker interface for the follow graph kept by the user service
+type FollowChecker interface {
+	IsFollowing(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error)
+}
+
+// VideoPolicy decides what a viewer may do with a video.
+//
+// A viewer who may not watch a video gets ErrNotFound for every action on it,
+// so private videos do not reveal that they exist. A viewer who may watch it
+// but not perform the action gets ErrForbidden.
+type VideoPolicy struct {
+	follows          FollowChecker
+	moderationConfig config.ModerationConfig
+}
+
+// NewVideoPolicy creates a new video policy. follows may be nil, in which case
+// followers-only videos are visible to their owner and staff only.
+func NewVideoPolicy(follows FollowChecker, moderationConfig config.ModerationConfig) *VideoPolicy {
+	return &VideoPolicy{
+		follows:          follows,
+		moderationConfig: moderationConfig,
+	}
+}
+
+// isAdmin checks if viewer may do anything
+func (p *VideoPolicy) isAdmin(viewer Viewer) bool {
+	return viewer.HasRole(RoleAdmin)
+}
+
+// isModerator checks if viewer may review videos of every user.
+// MODERATOR_USER_IDS still grants the role to users the gateway does not tag.
+func (p *VideoPolicy) isModerator(viewer Viewer) bool {
+	if viewer.IsAnonymous() {
+		return false
+	}
+	return p.isAdmin(viewer) || viewer.HasRole(RoleModerator) || p.moderationConfig.IsModerator(viewer.UserID.String())
+}
+
+// isOwner checks if viewer uploaded video
+func isOwner(viewer Viewer, video *entity.Video) bool {
+	return !viewer.IsAnonymous() && video.UserID == viewer.UserID
+}
+
+// CanView checks if viewer may watch video
+func (p *VideoPolicy) CanView(ctx context.Context, viewer Viewer, video *entity.Video) bool {
+	if isOwner(viewer, video) || p.isModerator(viewer) {
+		return true
+	}
+	// Videos not yet uploaded, still processing or waiting for review, failed
+	// and taken down are only shown to their owner and staff
+	if !video.IsReady() {
+		return false
+	}
+
+	switch video.Visibility {
+	case entity.VisibilityPublic:
+		return true
+	case entity.VisibilityFollowers:
+		return p.isFollowing(ctx, viewer, video.UserID)
+	default:
+		return false
+	}
+}
+
+// isFollowing checks if viewer follows ownerID, failing closed when unknown
+func (p *VideoPolicy) isFollowing(ctx context.Context, viewer Viewer, ownerID uuid.UUID) bool {
+	if viewer.IsAnonymous() || p.follows == nil {
+		return false
+	}
+
+	following, err := p.follows.IsFollowing(ctx, viewer.UserID, ownerID)
+	if err != nil {
+		logger.ForContext(ctx).Warn("Failed to check follow, hiding followers-only video",
+			zap.String("ownerID", ownerID.String()), zap.Error(err))
+		return false
+	}
+	return following
+}
+
+// VisibleLevels returns the visibility levels of the videos of ownerID that
+// viewer may list
+func (p *VideoPolicy) VisibleLevels(ctx context.Context, viewer Viewer, ownerID uuid.UUID) []entity.Visibility {
+	if (!viewer.IsAnonymous() && viewer.UserID == ownerID) || p.isModerator(viewer) {
+		return []entity.Visibility{entity.VisibilityPublic, entity.VisibilityFollowers, entity.VisibilityPrivate}
+	}
+	if p.isFollowing(ctx, viewer, ownerID) {
+		return []entity.Visibility{entity.VisibilityPublic, entity.VisibilityFollowers}
+	}
+	return []entity.Visibility{entity.VisibilityPublic}
+}
+
+// listedStatuses are the statuses of the videos listed to their owner and
+// staff: every video with a file that was not taken down
+var listedStatuses = []entity.EncodingStatus{
+	entity.EncodingStatusUploaded,
+	entity.EncodingStatusQueued,
+	entity.EncodingStatusTranscoding,
+	entity.EncodingStatusModerating,
+	entity.EncodingStatusReady,
+	entity.EncodingStatusFailed,
+}
+
+// readyStatuses are the statuses of the videos listed to everyone else
+var readyStatuses = []entity.EncodingStatus{entity.EncodingStatusReady}
+
+// VisibleStatuses returns the encoding statuses of the videos of ownerID that
+// viewer may list, matching CanView
+func (p *VideoPolicy) VisibleStatuses(viewer Viewer, ownerID uuid.UUID) []entity.EncodingStatus {
+	if (!viewer.IsAnonymous() && viewer.UserID == ownerID) || p.isModerator(viewer) {
+		return listedStatuses
+	}
+	return readyStatuses
+}
+
+// AuthorizeView returns ErrNotFound unless viewer may watch video
+func (p *VideoPolicy) AuthorizeView(ctx context.Context, viewer Viewer, video *entity.Video) error {
+	if !p.CanView(ctx, viewer, video) {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// AuthorizeEdit allows the owner and admins to change a video and its artwork
+func (p *VideoPolicy) AuthorizeEdit(ctx context.Context, viewer Viewer, video *entity.Video) error {
+	if err := p.AuthorizeView(ctx, viewer, video); err != nil {
+		return err
+	}
+	if !isOwner(viewer, video) && !p.isAdmin(viewer) {
+		return errors.ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeDelete allows the owner, admins and moderators to delete a video
+func (p *VideoPolicy) AuthorizeDelete(ctx context.Context, viewer Viewer, video *entity.Video) error {
+	if err := p.AuthorizeView(ctx, viewer, video); err != nil {
+		return err
+	}
+	if !isOwner(viewer, video) && !p.isModerator(viewer) {
+		return errors.ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeModeration allows admins and moderators to use the moderation RPCs
+func (p *VideoPolicy) AuthorizeModeration(viewer Viewer) error {
+	if !p.isModerator(viewer) {
+		return errors.ErrForbidden
+	}
+	return nil
+}
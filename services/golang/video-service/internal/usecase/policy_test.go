@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// testContext returns a context carrying a no-op logger
+func testContext() context.Context {
+	return context.WithValue(context.Background(), logger.LoggerKey, zap.NewNop())
+}
+
+// fakeFollowChecker answers IsFollowing from an in-memory follow graph
+type fakeFollowChecker struct {
+	follows map[[2]uuid.UUID]bool
+	err     error
+}
+
+func (f *fakeFollowChecker) IsFollowing(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	return f.follows[[2]uuid.UUID{followerID, followeeID}], nil
+}
+
+func TestVideoPolicy(t *testing.T) {
+	var (
+		ownerID        = uuid.New()
+		followerID     = uuid.New()
+		strangerID     = uuid.New()
+		listedModID    = uuid.New()
+		followChecker  = &fakeFollowChecker{follows: map[[2]uuid.UUID]bool{{followerID, ownerID}: true}}
+		policy         = NewVideoPolicy(followChecker, config.ModerationConfig{ModeratorIDs: []string{listedModID.String()}})
+		notFound       = errors.ErrNotFound
+		forbidden      = errors.ErrForbidden
+		ownerViewer    = Viewer{UserID: ownerID}
+		followerViewer = Viewer{UserID: followerID}
+		strangerViewer = Viewer{UserID: strangerID}
+		anonViewer     = Viewer{}
+		adminViewer    = Viewer{UserID: uuid.New(), Roles: []string{RoleAdmin}}
+		modViewer      = Viewer{UserID: uuid.New(), Roles: []string{RoleModerator}}
+		listedModView  = Viewer{UserID: listedModID}
+	)
+
+	videos := map[string]*entity.Video{
+		"public":    {UserID: ownerID, Visibility: entity.VisibilityPublic, EncodingStatus: entity.EncodingStatusReady},
+		"followers": {UserID: ownerID, Visibility: entity.VisibilityFollowers, EncodingStatus: entity.EncodingStatusReady},
+		"private":   {UserID: ownerID, Visibility: entity.VisibilityPrivate, EncodingStatus: entity.EncodingStatusReady},
+		"removed":   {UserID: ownerID, Visibility: entity.VisibilityPublic, EncodingStatus: entity.EncodingStatusRemoved},
+		"pending":   {UserID: ownerID, Visibility: entity.VisibilityPublic, EncodingStatus: entity.EncodingStatusPendingUpload},
+		// Public videos are hidden until they are ready
+		"transcoding": {UserID: ownerID, Visibility: entity.VisibilityPublic, EncodingStatus: entity.EncodingStatusTranscoding},
+		"moderating":  {UserID: ownerID, Visibility: entity.VisibilityPublic, EncodingStatus: entity.EncodingStatusModerating},
+		"failed":      {UserID: ownerID, Visibility: entity.VisibilityPublic, EncodingStatus: entity.EncodingStatusFailed},
+	}
+
+	tests := []struct {
+		viewerName string
+		viewer     Viewer
+		video      string
+		wantView   error
+		wantEdit   error
+		wantDelete error
+	}{
+		{"owner", ownerViewer, "public", nil, nil, nil},
+		{"owner", ownerViewer, "followers", nil, nil, nil},
+		{"owner", ownerViewer, "private", nil, nil, nil},
+		{"owner", ownerViewer, "removed", nil, nil, nil},
+		{"owner", ownerViewer, "pending", nil, nil, nil},
+		{"owner", ownerViewer, "transcoding", nil, nil, nil},
+		{"owner", ownerViewer, "moderating", nil, nil, nil},
+		{"owner", ownerViewer, "failed", nil, nil, nil},
+
+		{"follower", followerViewer, "public", nil, forbidden, forbidden},
+		{"follower", followerViewer, "followers", nil, forbidden, forbidden},
+		{"follower", followerViewer, "private", notFound, notFound, notFound},
+		{"follower", followerViewer, "removed", notFound, notFound, notFound},
+		{"follower", followerViewer, "pending", notFound, notFound, notFound},
+		{"follower", followerViewer, "transcoding", notFound, notFound, notFound},
+		{"follower", followerViewer, "moderating", notFound, notFound, notFound},
+		{"follower", followerViewer, "failed", notFound, notFound, notFound},
+
+		{"non-follower", strangerViewer, "public", nil, forbidden, forbidden},
+		{"non-follower", strangerViewer, "followers", notFound, notFound, notFound},
+		{"non-follower", strangerViewer, "private", notFound, notFound, notFound},
+		{"non-follower", strangerViewer, "removed", notFound, notFound, notFound},
+		{"non-follower", strangerViewer, "pending", notFound, notFound, notFound},
+		{"non-follower", strangerViewer, "transcoding", notFound, notFound, notFound},
+		{"non-follower", strangerViewer, "moderating", notFound, notFound, notFound},
+		{"non-follower", strangerViewer, "failed", notFound, notFound, notFound},
+
+		{"anonymous", anonViewer, "public", nil, forbidden, forbidden},
+		{"anonymous", anonViewer, "followers", notFound, notFound, notFound},
+		{"anonymous", anonViewer, "private", notFound, notFound, notFound},
+		{"anonymous", anonViewer, "removed", notFound, notFound, notFound},
+		{"anonymous", anonViewer, "pending", notFound, notFound, notFound},
+		{"anonymous", anonViewer, "transcoding", notFound, notFound, notFound},
+		{"anonymous", anonViewer, "moderating", notFound, notFound, notFound},
+		{"anonymous", anonViewer, "failed", notFound, notFound, notFound},
+
+		{"admin", adminViewer, "public", nil, nil, nil},
+		{"admin", adminViewer, "followers", nil, nil, nil},
+		{"admin", adminViewer, "private", nil, nil, nil},
+		{"admin", adminViewer, "removed", nil, nil, nil},
+		{"admin", adminViewer, "pending", nil, nil, nil},
+		{"admin", adminViewer, "transcoding", nil, nil, nil},
+		{"admin", adminViewer, "moderating", nil, nil, nil},
+		{"admin", adminViewer, "failed", nil, nil, nil},
+
+		{"moderator", modViewer, "public", nil, forbidden, nil},
+		{"moderator", modViewer, "followers", nil, forbidden, nil},
+		{"moderator", modViewer, "private", nil, forbidden, nil},
+		{"moderator", modViewer, "removed", nil, forbidden, nil},
+		{"moderator", modViewer, "pending", nil, forbidden, nil},
+		{"moderator", modViewer, "transcoding", nil, forbidden, nil},
+		{"moderator", modViewer, "moderating", nil, forbidden, nil},
+		{"moderator", modViewer, "failed", nil, forbidden, nil},
+
+		{"listed moderator", listedModView, "private", nil, forbidden, nil},
+		{"listed moderator", listedModView, "removed", nil, forbidden, nil},
+		{"listed moderator", listedModView, "moderating", nil, forbidden, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.viewerName+"/"+tt.video, func(t *testing.T) {
+			ctx := testContext()
+			video := videos[tt.video]
+
+			if err := policy.AuthorizeView(ctx, tt.viewer, video); err != tt.wantView {
+				t.Errorf("AuthorizeView() = %v, want %v", err, tt.wantView)
+			}
+			if err := policy.AuthorizeEdit(ctx, tt.viewer, video); err != tt.wantEdit {
+				t.Errorf("AuthorizeEdit() = %v, want %v", err, tt.wantEdit)
+			}
+			if err := policy.AuthorizeDelete(ctx, tt.viewer, video); err != tt.wantDelete {
+				t.Errorf("AuthorizeDelete() = %v, want %v", err, tt.wantDelete)
+			}
+		})
+	}
+}
+
+func TestVideoPolicyModeration(t *testing.T) {
+	listedModID := uuid.New()
+	policy := NewVideoPolicy(nil, config.ModerationConfig{ModeratorIDs: []string{listedModID.String()}})
+
+	tests := []struct {
+		name   string
+		viewer Viewer
+		want   error
+	}{
+		{"admin", Viewer{UserID: uuid.New(), Roles: []string{RoleAdmin}}, nil},
+		{"moderator", Viewer{UserID: uuid.New(), Roles: []string{RoleModerator}}, nil},
+		{"listed moderator", Viewer{UserID: listedModID}, nil},
+		{"user", Viewer{UserID: uuid.New()}, errors.ErrForbidden},
+		{"anonymous", Viewer{}, errors.ErrForbidden},
+		{"anonymous with role", Viewer{Roles: []string{RoleModerator}}, errors.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := policy.AuthorizeModeration(tt.viewer); err != tt.want {
+				t.Errorf("AuthorizeModeration() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVideoPolicyFollowCheckFailsClosed(t *testing.T) {
+	ownerID := uuid.New()
+	followerID := uuid.New()
+	video := &entity.Video{UserID: ownerID, Visibility: entity.VisibilityFollowers, EncodingStatus: entity.EncodingStatusReady}
+	viewer := Viewer{UserID: followerID}
+
+	tests := []struct {
+		name    string
+		follows FollowChecker
+	}{
+		{"checker error", &fakeFollowChecker{
+			follows: map[[2]uuid.UUID]bool{{followerID, ownerID}: true},
+			err:     stderrors.New("user service unavailable"),
+		}},
+		{"no checker", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testContext()
+			policy := NewVideoPolicy(tt.follows, config.ModerationConfig{})
+
+			if err := policy.AuthorizeView(ctx, viewer, video); err != errors.ErrNotFound {
+				t.Errorf("AuthorizeView() = %v, want %v", err, errors.ErrNotFound)
+			}
+			levels := policy.VisibleLevels(ctx, viewer, ownerID)
+			if len(levels) != 1 || levels[0] != entity.VisibilityPublic {
+				t.Errorf("VisibleLevels() = %v, want [public]", levels)
+			}
+		})
+	}
+}
+
+func TestVideoPolicyVisibleStatuses(t *testing.T) {
+	ownerID := uuid.New()
+	policy := NewVideoPolicy(nil, config.ModerationConfig{})
+
+	tests := []struct {
+		name   string
+		viewer Viewer
+		want   []entity.EncodingStatus
+	}{
+		{"owner", Viewer{UserID: ownerID}, listedStatuses},
+		{"moderator", Viewer{UserID: uuid.New(), Roles: []string{RoleModerator}}, listedStatuses},
+		{"user", Viewer{UserID: uuid.New()}, readyStatuses},
+		{"anonymous", Viewer{}, readyStatuses},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.VisibleStatuses(tt.viewer, ownerID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VisibleStatuses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
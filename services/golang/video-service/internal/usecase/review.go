@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ListVideosForReview retrieves one page of the videos waiting for content
+// review, oldest first; moderators only
+func (uc *VideoUseCase) ListVideosForReview(ctx context.Context, cursor string, limit int, viewer Viewer) (*dto.VideoListResponse, error) {
+	if err := uc.policy.AuthorizeModeration(viewer); err != nil {
+		return nil, err
+	}
+	limit = pagination.NormalizeLimit(limit)
+	scope := "review-queue"
+
+	after, err := uc.cursorCodec.Decode(scope, cursor)
+	if err != nil {
+		return nil, errors.ErrInvalidParam
+	}
+
+	videos, err := uc.videoRepo.ListByEncodingStatus(ctx, entity.EncodingStatusModerating, after, limit+1)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to list videos for review", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	total, err := uc.videoRepo.CountByEncodingStatus(ctx, entity.EncodingStatusModerating)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to count videos for review", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	return uc.videoPage(ctx, videos, limit, total, func(last *entity.Video) string {
+		return uc.cursorCodec.Encode(scope, pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.VideoID.String()})
+	}), nil
+}
+
+// ReviewVideo approves a video waiting for content review, making it ready,
+// or rejects it, removing it; moderators only
+func (uc *VideoUseCase) ReviewVideo(ctx context.Context, videoID uuid.UUID, approve bool, reason string, viewer Viewer) (*dto.VideoResponse, error) {
+	if err := uc.policy.AuthorizeModeration(viewer); err != nil {
+		return nil, err
+	}
+
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	// A second decision on a reviewed video is rejected, not applied over the first
+	if video.EncodingStatus != entity.EncodingStatusModerating {
+		return nil, ErrInvalidStatusChange
+	}
+
+	status, verdict := entity.EncodingStatusReady, "approved"
+	if !approve {
+		status, verdict = entity.EncodingStatusRemoved, "rejected"
+	}
+	// The moderator is kept in the status history
+	note := fmt.Sprintf("%s by %s", verdict, viewer.UserID)
+	if reason != "" {
+		note += ": " + reason
+	}
+	if err := uc.transitionVideo(ctx, video, status, note); err != nil {
+		return nil, err
+	}
+
+	uc.loadCounts(ctx, video)
+	uc.loadAnnotations(ctx, video)
+	return uc.toVideoResponse(ctx, video), nil
+}
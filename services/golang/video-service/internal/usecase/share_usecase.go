@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	stderrors "errors"
+	"math/big"
+	"strings"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// shareCodeAlphabet is URL safe and case sensitive; 8 characters give 62^8 codes
+	shareCodeAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	shareCodeLength   = 8
+	// shareCodeAttempts is how many random codes are tried before giving up on collisions
+	shareCodeAttempts = 5
+)
+
+// DownloadRenderer interface for queueing the rendering of watermarked downloads
+type DownloadRenderer interface {
+	StartWatermark(ctx context.Context, videoID uuid.UUID) error
+}
+
+// ShareUseCase records shares of videos behind short links
+type ShareUseCase struct {
+	shareRepo        repository.ShareLinkRepository
+	videoRepo        repository.VideoRepository
+	counterStore     CounterStore
+	downloadRenderer DownloadRenderer
+	videoUseCase     *VideoUseCase
+	shareConfig      config.ShareConfig
+}
+
+// NewShareUseCase creates a new share use case
+func NewShareUseCase(
+	shareRepo repository.ShareLinkRepository,
+	videoRepo repository.VideoRepository,
+	counterStore CounterStore,
+	downloadRenderer DownloadRenderer,
+	videoUseCase *VideoUseCase,
+	shareConfig config.ShareConfig,
+) *ShareUseCase {
+	return &ShareUseCase{
+		shareRepo:        shareRepo,
+		videoRepo:        videoRepo,
+		counterStore:     counterStore,
+		downloadRenderer: downloadRenderer,
+		videoUseCase:     videoUseCase,
+		shareConfig:      shareConfig,
+	}
+}
+
+// ShareVideo records a share of a video the viewer may watch and returns its
+// short link. Shares asking for the watermarked file need the video to allow
+// downloads, unless the viewer owns it; the file is rendered on the first
+// such share, which gets no URL yet.
+func (uc *ShareUseCase) ShareVideo(ctx context.Context, req *dto.ShareVideoRequest, viewer Viewer) (*dto.ShareResponse, error) {
+	log := logger.ForContext(ctx)
+
+	channel, err := entity.ParseShareChannel(req.Channel)
+	if err != nil {
+		return nil, errors.ErrInvalidParam
+	}
+
+	video, err := uc.videoRepo.GetByID(ctx, req.VideoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := uc.videoUseCase.policy.AuthorizeView(ctx, viewer, video); err != nil {
+		return nil, err
+	}
+	if !video.IsReady() {
+		return nil, ErrVideoNotReady
+	}
+	if req.Download && !video.AllowDownload && video.UserID != viewer.UserID {
+		return nil, ErrDownloadNotAllowed
+	}
+
+	// The render is queued before anything is stored, so a share that fails
+	// here leaves no link or count behind and can be retried. Queueing skips
+	// videos with a render in progress.
+	downloadPending := req.Download && video.DownloadKey == ""
+	if downloadPending {
+		if err := uc.downloadRenderer.StartWatermark(ctx, video.VideoID); err != nil {
+			log.Error("Failed to queue watermark job", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+	}
+
+	link := &entity.ShareLink{
+		VideoID:  video.VideoID,
+		Channel:  channel,
+		Download: req.Download,
+	}
+	if !viewer.IsAnonymous() {
+		link.UserID = &viewer.UserID
+	}
+	if err := uc.createLink(ctx, link); err != nil {
+		log.Error("Failed to create share link", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	// The link is the source of truth, so if counting fails the
+	// reconciliation corrects the count
+	if err := uc.counterStore.Increment(ctx, video.VideoID, entity.VideoCounts{Shares: 1}); err != nil {
+		log.Warn("Failed to count share", zap.Error(err))
+	}
+	uc.videoUseCase.loadCounts(ctx, video)
+
+	response := &dto.ShareResponse{
+		Code:            link.Code,
+		ShareURL:        strings.TrimSuffix(uc.shareConfig.LinkBaseURL, "/") + "/" + link.Code,
+		ShareCount:      video.ShareCount,
+		DownloadPending: downloadPending,
+	}
+	if req.Download && !downloadPending {
+		response.DownloadURL = uc.videoUseCase.urlsFor(ctx, video).object(video.DownloadKey)
+	}
+	return response, nil
+}
+
+// createLink stores a link under a new random code
+func (uc *ShareUseCase) createLink(ctx context.Context, link *entity.ShareLink) error {
+	for attempt := 0; attempt < shareCodeAttempts; attempt++ {
+		code, err := newShareCode()
+		if err != nil {
+			return err
+		}
+		link.Code = code
+
+		created, err := uc.shareRepo.Create(ctx, link)
+		if err != nil || created {
+			return err
+		}
+	}
+	return stderrors.New("no free share code found")
+}
+
+// ResolveShareLink counts a click on a share link and returns the video it
+// leads to. Whether the visitor may watch the video is checked when they
+// open it.
+func (uc *ShareUseCase) ResolveShareLink(ctx context.Context, code string) (*dto.ShareLinkResponse, error) {
+	if !isShareCode(code) {
+		return nil, errors.ErrNotFound
+	}
+
+	link, err := uc.shareRepo.Click(ctx, code)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to resolve share link", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	if link == nil {
+		return nil, errors.ErrNotFound
+	}
+
+	return &dto.ShareLinkResponse{
+		VideoID:     link.VideoID.String(),
+		RedirectURL: strings.TrimSuffix(uc.shareConfig.VideoBaseURL, "/") + "/" + link.VideoID.String(),
+		ClickCount:  link.ClickCount,
+	}, nil
+}
+
+// newShareCode returns a random share code
+func newShareCode() (string, error) {
+	max := big.NewInt(int64(len(shareCodeAlphabet)))
+	code := make([]byte, shareCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = shareCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// isShareCode checks if code could have been issued, sparing the database lookup otherwise
+func isShareCode(code string) bool {
+	if len(code) != shareCodeLength {
+		return false
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(shareCodeAlphabet, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+)
+
+// fakeShareRepository keeps share links in memory
+type fakeShareRepository struct {
+	links map[string]*entity.ShareLink
+}
+
+func (r *fakeShareRepository) Create(ctx context.Context, link *entity.ShareLink) (bool, error) {
+	if _, taken := r.links[link.Code]; taken {
+		return false, nil
+	}
+	stored := *link
+	r.links[link.Code] = &stored
+	return true, nil
+}
+
+func (r *fakeShareRepository) Click(ctx context.Context, code string) (*entity.ShareLink, error) {
+	link, ok := r.links[code]
+	if !ok {
+		return nil, nil
+	}
+	link.ClickCount++
+	return link, nil
+}
+
+// recordingDownloadRenderer keeps the videos whose watermark was queued
+type recordingDownloadRenderer struct {
+	queued []uuid.UUID
+	err    error
+}
+
+func (r *recordingDownloadRenderer) StartWatermark(ctx context.Context, videoID uuid.UUID) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.queued = append(r.queued, videoID)
+	return nil
+}
+
+// shareUseCase returns a ShareUseCase over the fixture videos
+func (f *videoFixture) shareUseCase(renderer DownloadRenderer) (*ShareUseCase, *fakeShareRepository) {
+	videoUseCase, repo, _ := f.useCase()
+	shares := &fakeShareRepository{links: map[string]*entity.ShareLink{}}
+	uc := NewShareUseCase(shares, repo, videoUseCase.counterStore, renderer, videoUseCase,
+		config.ShareConfig{LinkBaseURL: "https://share.test/"})
+	return uc, shares
+}
+
+func TestShareUseCaseShareVideo(t *testing.T) {
+	f := newVideoFixture()
+	tests := []accessCase{
+		{"stranger/public", f.stranger, "public", nil},
+		{"anonymous/public", f.anon, "public", nil},
+		{"follower/followers", f.follower, "followers", nil},
+		{"stranger/followers", f.stranger, "followers", errors.ErrNotFound},
+		{"stranger/private", f.stranger, "private", errors.ErrNotFound},
+		{"stranger/moderating", f.stranger, "moderating", errors.ErrNotFound},
+		{"owner/moderating", f.owner, "moderating", ErrVideoNotReady},
+		{"stranger/missing", f.stranger, "missing", errors.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, shares := f.shareUseCase(&recordingDownloadRenderer{})
+			got, err := uc.ShareVideo(testContext(), &dto.ShareVideoRequest{VideoID: f.videoID(tt.video), Channel: "copy_link"}, tt.viewer)
+			if err != tt.wantErr {
+				t.Fatalf("ShareVideo() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (got.ShareCount != 1 || got.ShareURL != "https://share.test/"+got.Code) {
+				t.Errorf("ShareVideo() = %+v, want one share behind its link", got)
+			}
+			if created := len(shares.links) == 1; created != (tt.wantErr == nil) {
+				t.Errorf("link created = %v, want %v", created, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestShareUseCaseShareVideoDownload(t *testing.T) {
+	f := newVideoFixture()
+	tests := []accessCase{
+		{"owner", f.owner, "public", nil},
+		{"stranger", f.stranger, "public", ErrDownloadNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := &recordingDownloadRenderer{}
+			uc, shares := f.shareUseCase(renderer)
+			got, err := uc.ShareVideo(testContext(), &dto.ShareVideoRequest{VideoID: f.videoID(tt.video), Channel: "copy_link", Download: true}, tt.viewer)
+			if err != tt.wantErr {
+				t.Fatalf("ShareVideo() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(shares.links) != 0 || len(renderer.queued) != 0 {
+					t.Errorf("download shared without permission")
+				}
+				return
+			}
+			// The file is rendered on the first download share
+			if !got.DownloadPending || len(renderer.queued) != 1 {
+				t.Errorf("ShareVideo() = %+v with %d queued renders, want one pending render", got, len(renderer.queued))
+			}
+		})
+	}
+}
+
+func TestShareUseCaseShareVideoDownloadQueueFailure(t *testing.T) {
+	f := newVideoFixture()
+	renderer := &recordingDownloadRenderer{err: stderrors.New("queue unavailable")}
+	uc, shares := f.shareUseCase(renderer)
+	req := &dto.ShareVideoRequest{VideoID: f.videoID("public"), Channel: "copy_link", Download: true}
+
+	if _, err := uc.ShareVideo(testContext(), req, f.owner); err != errors.ErrInternal {
+		t.Fatalf("ShareVideo() error = %v, want ErrInternal", err)
+	}
+	// Nothing is stored, so the retry records the only share
+	if len(shares.links) != 0 {
+		t.Fatalf("got %d links after a failed share, want none", len(shares.links))
+	}
+
+	renderer.err = nil
+	got, err := uc.ShareVideo(testContext(), req, f.owner)
+	if err != nil {
+		t.Fatalf("ShareVideo() retry error = %v", err)
+	}
+	if got.ShareCount != 1 || len(shares.links) != 1 || !got.DownloadPending {
+		t.Errorf("ShareVideo() retry = %+v with %d links, want one pending share", got, len(shares.links))
+	}
+}
+
+func TestShareUseCaseShareVideoRenderedDownload(t *testing.T) {
+	f := newVideoFixture()
+	f.videos["public"].DownloadKey = "videos/v/download.mp4"
+	renderer := &recordingDownloadRenderer{}
+	uc, _ := f.shareUseCase(renderer)
+
+	got, err := uc.ShareVideo(testContext(), &dto.ShareVideoRequest{VideoID: f.videoID("public"), Channel: "message", Download: true}, f.owner)
+	if err != nil {
+		t.Fatalf("ShareVideo() error = %v", err)
+	}
+	if got.DownloadPending || got.DownloadURL != "https://storage.test/videos/v/download.mp4" || len(renderer.queued) != 0 {
+		t.Errorf("ShareVideo() = %+v with %d queued renders, want the rendered file", got, len(renderer.queued))
+	}
+}
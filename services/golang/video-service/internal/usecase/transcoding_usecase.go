@@ -0,0 +1,679 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/domain/storagekey"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Transcoder interface for converting an uploaded video into HLS renditions
+type Transcoder interface {
+	// TranscodeHLS writes renditions and a master.m3u8 playlist into outputDir.
+	// width and height are the source dimensions, 0 if unknown.
+	TranscodeHLS(ctx context.Context, inputPath, outputDir string, width, height int) error
+}
+
+// ArtworkRenderer interface for rendering images from a video file
+type ArtworkRenderer interface {
+	// RenderCover writes the cover images of the frame at `at` into outputDir,
+	// named by storagekey.CoverFile. A negative `at` picks the frame automatically.
+	RenderCover(ctx context.Context, inputPath, outputDir string, at time.Duration, duration float64) error
+	// RenderPreviews writes the animated preview, the sprite sheet and its
+	// storyboard into outputDir, named by the storagekey artwork file names
+	RenderPreviews(ctx context.Context, inputPath, outputDir string, duration float64, width, height int) error
+}
+
+// Fingerprinter interface for computing perceptual video fingerprints
+type Fingerprinter interface {
+	// Fingerprint returns a fingerprint comparable with entity.FingerprintDistance
+	Fingerprint(ctx context.Context, inputPath string, duration float64) (string, error)
+}
+
+// Watermarker interface for rendering the downloadable copy of a video
+type Watermarker interface {
+	// Watermark writes a copy of the video at inputPath with text over it to outputPath
+	Watermark(ctx context.Context, inputPath, outputPath, text string) error
+}
+
+// errNotTranscodable is returned when a job's video is no longer waiting for transcoding,
+// e.g. it was removed after the job was queued
+var errNotTranscodable = stderrors.New("video is not waiting for transcoding")
+
+// TranscodingJobQueue implements TranscodingService by persisting a transcoding job
+type TranscodingJobQueue struct {
+	jobRepo     repository.TranscodingJobRepository
+	maxAttempts int
+}
+
+// NewTranscodingJobQueue creates a new transcoding job queue
+func NewTranscodingJobQueue(jobRepo repository.TranscodingJobRepository, maxAttempts int) *TranscodingJobQueue {
+	return &TranscodingJobQueue{
+		jobRepo:     jobRepo,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// StartTranscoding queues a video for the transcoding workers
+func (q *TranscodingJobQueue) StartTranscoding(ctx context.Context, videoID uuid.UUID, videoKey string) error {
+	return q.jobRepo.Create(ctx, &entity.TranscodingJob{
+		VideoID:     videoID,
+		Kind:        entity.TranscodingJobKindTranscode,
+		Status:      entity.TranscodingJobPending,
+		MaxAttempts: q.maxAttempts,
+		RunAfter:    time.Now(),
+	})
+}
+
+// StartWatermark queues the rendering of the watermarked download of a video,
+// unless it is queued already
+func (q *TranscodingJobQueue) StartWatermark(ctx context.Context, videoID uuid.UUID) error {
+	active, err := q.jobRepo.HasActive(ctx, videoID, entity.TranscodingJobKindWatermark)
+	if err != nil || active {
+		return err
+	}
+	return q.jobRepo.Create(ctx, &entity.TranscodingJob{
+		VideoID:     videoID,
+		Kind:        entity.TranscodingJobKindWatermark,
+		Status:      entity.TranscodingJobPending,
+		MaxAttempts: q.maxAttempts,
+		RunAfter:    time.Now(),
+	})
+}
+
+// TranscodingUseCase runs queued transcoding, cover and watermark jobs
+type TranscodingUseCase struct {
+	videoRepo        repository.VideoRepository
+	jobRepo          repository.TranscodingJobRepository
+	contentRepo      repository.ContentObjectRepository
+	storageService   StorageService
+	transcoder       Transcoder
+	artworkRenderer  ArtworkRenderer
+	fingerprinter    Fingerprinter
+	watermarker      Watermarker
+	videoUseCase     *VideoUseCase
+	config           config.TranscodingConfig
+	moderationConfig config.ModerationConfig
+}
+
+// NewTranscodingUseCase creates a new transcoding use case
+func NewTranscodingUseCase(
+	videoRepo repository.VideoRepository,
+	jobRepo repository.TranscodingJobRepository,
+	contentRepo repository.ContentObjectRepository,
+	storageService StorageService,
+	transcoder Transcoder,
+	artworkRenderer ArtworkRenderer,
+	fingerprinter Fingerprinter,
+	watermarker Watermarker,
+	videoUseCase *VideoUseCase,
+	transcodingConfig config.TranscodingConfig,
+	moderationConfig config.ModerationConfig,
+) *TranscodingUseCase {
+	return &TranscodingUseCase{
+		videoRepo:        videoRepo,
+		jobRepo:          jobRepo,
+		contentRepo:      contentRepo,
+		storageService:   storageService,
+		transcoder:       transcoder,
+		artworkRenderer:  artworkRenderer,
+		fingerprinter:    fingerprinter,
+		watermarker:      watermarker,
+		videoUseCase:     videoUseCase,
+		config:           transcodingConfig,
+		moderationConfig: moderationConfig,
+	}
+}
+
+// ProcessNextJob claims and runs one transcoding, cover or watermark job.
+// It reports false when no job was ready to run.
+func (uc *TranscodingUseCase) ProcessNextJob(ctx context.Context, workerID string) (bool, error) {
+	job, err := uc.jobRepo.Claim(ctx, workerID, uc.config.LeaseDuration)
+	if err != nil {
+		return false, fmt.Errorf("claim job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	log := logger.ForContext(ctx).With(
+		zap.String("jobID", job.JobID.String()),
+		zap.String("videoID", job.VideoID.String()),
+		zap.String("kind", job.Kind),
+		zap.Int("attempt", job.Attempts))
+
+	// The last attempt was reclaimed after its worker died without recording a result
+	if job.Attempts > job.MaxAttempts {
+		log.Error("Transcoding job exhausted its attempts")
+		uc.failJob(ctx, job, workerID, "lease expired on the last attempt")
+		return true, nil
+	}
+
+	log.Info("Starting transcoding job")
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	leaseLost := uc.keepLease(jobCtx, cancel, job, workerID)
+
+	switch job.Kind {
+	case entity.TranscodingJobKindCover:
+		err = uc.renderCover(jobCtx, job)
+	case entity.TranscodingJobKindWatermark:
+		err = uc.renderDownload(jobCtx, job)
+	default:
+		err = uc.transcode(jobCtx, job.VideoID)
+	}
+	cancel()
+	// Wait for the heartbeat goroutine to stop so the lease is no longer touched
+	lost := <-leaseLost
+
+	switch {
+	case ctx.Err() != nil:
+		// Shutting down: the lease expires and another worker retries the job
+		return true, ctx.Err()
+	case lost:
+		log.Warn("Transcoding job lease lost, leaving it to its new owner")
+		return true, nil
+	case stderrors.Is(err, errNotTranscodable):
+		log.Warn("Dropping transcoding job", zap.Error(err))
+		if err := uc.jobRepo.Complete(ctx, job.JobID, workerID); err != nil {
+			return true, fmt.Errorf("complete job: %w", err)
+		}
+		return true, nil
+	case err != nil:
+		log.Error("Transcoding failed", zap.Error(err))
+		uc.failJob(ctx, job, workerID, err.Error())
+		return true, nil
+	}
+
+	// The video is marked before the job completes, so a crash in between
+	// leaves the job to be retried, which then finds the video done
+	if job.Kind == entity.TranscodingJobKindTranscode {
+		if err := uc.markTranscoded(ctx, job.VideoID); err != nil {
+			log.Error("Failed to mark video transcoded", zap.Error(err))
+			uc.failJob(ctx, job, workerID, err.Error())
+			return true, nil
+		}
+	}
+	if err := uc.jobRepo.Complete(ctx, job.JobID, workerID); err != nil {
+		return true, fmt.Errorf("complete job: %w", err)
+	}
+
+	log.Info("Transcoding job completed")
+	return true, nil
+}
+
+// markTranscoded moves a video whose renditions are stored to ready, or to
+// moderating when uploads are reviewed. A video no longer transcoding is left
+// as it is.
+func (uc *TranscodingUseCase) markTranscoded(ctx context.Context, videoID uuid.UUID) error {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return fmt.Errorf("load video: %w", err)
+	}
+	if video.EncodingStatus != entity.EncodingStatusTranscoding {
+		return nil
+	}
+	status := entity.EncodingStatusReady
+	if uc.moderationConfig.ReviewUploads {
+		status = entity.EncodingStatusModerating
+	}
+	if err := uc.videoUseCase.transitionVideo(ctx, video, status, "transcoding completed"); err != nil {
+		return fmt.Errorf("mark %s: %w", status, err)
+	}
+	return nil
+}
+
+// keepLease renews the job lease until ctx is done. If the lease is lost the
+// job is cancelled and the returned channel yields true.
+func (uc *TranscodingUseCase) keepLease(ctx context.Context, cancel context.CancelFunc, job *entity.TranscodingJob, workerID string) <-chan bool {
+	lost := make(chan bool, 1)
+
+	go func() {
+		ticker := time.NewTicker(uc.config.LeaseDuration / 3)
+		defer ticker.Stop()
+		defer close(lost)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				held, err := uc.jobRepo.Heartbeat(ctx, job.JobID, workerID, uc.config.LeaseDuration)
+				if err != nil {
+					// Keep working; the lease only expires after several missed heartbeats
+					logger.ForContext(ctx).Warn("Transcoding job heartbeat failed", zap.Error(err))
+					continue
+				}
+				if !held {
+					lost <- true
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return lost
+}
+
+// failJob schedules a retry with exponential backoff, or marks the job dead
+// and the video failed once no attempts are left. A failed cover or
+// watermark job leaves the video as it is.
+func (uc *TranscodingUseCase) failJob(ctx context.Context, job *entity.TranscodingJob, workerID, errMsg string) {
+	log := logger.ForContext(ctx).With(zap.String("jobID", job.JobID.String()))
+	transcodeJob := job.Kind == entity.TranscodingJobKindTranscode
+
+	if job.HasAttemptsLeft() {
+		retryAt := time.Now().Add(uc.retryDelay(job.Attempts))
+		if err := uc.jobRepo.Fail(ctx, job.JobID, workerID, errMsg, &retryAt); err != nil {
+			log.Error("Failed to schedule transcoding retry", zap.Error(err))
+		}
+		// Still queued if the attempt failed before transcoding started
+		if transcodeJob {
+			if err := uc.videoUseCase.UpdateEncodingStatus(ctx, job.VideoID, entity.EncodingStatusQueued, errMsg); err != nil {
+				log.Debug("Video not moved back to queued", zap.Error(err))
+			}
+		}
+		return
+	}
+
+	if err := uc.jobRepo.Fail(ctx, job.JobID, workerID, errMsg, nil); err != nil {
+		log.Error("Failed to mark transcoding job dead", zap.Error(err))
+	}
+	if !transcodeJob {
+		return
+	}
+	if err := uc.videoUseCase.UpdateEncodingStatus(ctx, job.VideoID, entity.EncodingStatusFailed, errMsg); err != nil {
+		log.Error("Failed to mark video as failed", zap.Error(err))
+	}
+}
+
+// retryDelay returns the backoff before the next attempt: RetryBaseDelay
+// doubled for every attempt made so far, capped at RetryMaxDelay
+func (uc *TranscodingUseCase) retryDelay(attempts int) time.Duration {
+	delay := uc.config.RetryBaseDelay
+	for i := 1; i < attempts && delay < uc.config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > uc.config.RetryMaxDelay {
+		delay = uc.config.RetryMaxDelay
+	}
+	return delay
+}
+
+// GetTranscodingJob returns the latest transcoding or cover job of a video
+// the viewer may edit
+func (uc *TranscodingUseCase) GetTranscodingJob(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*dto.TranscodingJobResponse, error) {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := uc.videoUseCase.policy.AuthorizeEdit(ctx, viewer, video); err != nil {
+		return nil, err
+	}
+
+	job, err := uc.jobRepo.GetLatestByVideoID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	return toTranscodingJobResponse(job), nil
+}
+
+// SetCoverFrame queues a job rendering the cover of a ready video the viewer
+// may edit from the frame at `at`
+func (uc *TranscodingUseCase) SetCoverFrame(ctx context.Context, videoID uuid.UUID, viewer Viewer, at time.Duration) (*dto.TranscodingJobResponse, error) {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := uc.videoUseCase.policy.AuthorizeEdit(ctx, viewer, video); err != nil {
+		return nil, err
+	}
+	if !video.IsReady() {
+		return nil, ErrVideoNotReady
+	}
+	if at < 0 || at > time.Duration(video.DurationSeconds)*time.Second {
+		return nil, errors.ErrInvalidParam
+	}
+
+	coverAtMs := at.Milliseconds()
+	job := &entity.TranscodingJob{
+		VideoID:     videoID,
+		Kind:        entity.TranscodingJobKindCover,
+		CoverAtMs:   &coverAtMs,
+		Status:      entity.TranscodingJobPending,
+		MaxAttempts: uc.config.MaxAttempts,
+		RunAfter:    time.Now(),
+	}
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		logger.ForContext(ctx).Error("Failed to queue cover job", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	return toTranscodingJobResponse(job), nil
+}
+
+// toTranscodingJobResponse converts entity to DTO
+func toTranscodingJobResponse(job *entity.TranscodingJob) *dto.TranscodingJobResponse {
+	response := &dto.TranscodingJobResponse{
+		JobID:       job.JobID.String(),
+		VideoID:     job.VideoID.String(),
+		Kind:        job.Kind,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+	if job.Status == entity.TranscodingJobPending {
+		response.NextRunAt = job.RunAfter
+	}
+	return response
+}
+
+// transcode runs the pipeline in a scratch directory removed afterwards
+func (uc *TranscodingUseCase) transcode(ctx context.Context, videoID uuid.UUID) error {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return fmt.Errorf("load video: %w", err)
+	}
+
+	switch video.EncodingStatus {
+	case entity.EncodingStatusQueued:
+		if err := uc.videoUseCase.transitionVideo(ctx, video, entity.EncodingStatusTranscoding, "transcoding started"); err != nil {
+			return fmt.Errorf("mark transcoding: %w", err)
+		}
+	case entity.EncodingStatusTranscoding:
+		// Taken over from a worker that lost its lease
+	default:
+		return fmt.Errorf("%w: status %s", errNotTranscodable, video.EncodingStatus)
+	}
+
+	workDir, err := os.MkdirTemp(uc.config.WorkDir, "transcode-"+videoID.String()+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	inputPath := filepath.Join(workDir, "original")
+	if err := downloadObject(ctx, uc.storageService, video.VideoKey, inputPath); err != nil {
+		return fmt.Errorf("download original: %w", err)
+	}
+
+	// Artwork first: it is quick, and a failure here does not waste the renditions
+	if err := uc.generateArtwork(ctx, video, inputPath, workDir); err != nil {
+		return err
+	}
+
+	fingerprint, err := uc.fingerprinter.Fingerprint(ctx, inputPath, float64(video.DurationSeconds))
+	if err != nil {
+		return fmt.Errorf("fingerprint: %w", err)
+	}
+	if err := uc.videoRepo.UpdateFingerprint(ctx, videoID, fingerprint); err != nil {
+		return fmt.Errorf("save fingerprint: %w", err)
+	}
+
+	// Videos uploaded before deduplication keep their renditions to themselves
+	if video.ContentSHA256 == "" {
+		prefix := storagekey.Renditions(videoID)
+		if err := uc.transcodeTo(ctx, video, inputPath, workDir, prefix); err != nil {
+			return err
+		}
+		return uc.videoRepo.UpdateRenditions(ctx, videoID, prefix)
+	}
+
+	content, err := uc.contentRepo.GetBySHA256(ctx, video.ContentSHA256)
+	if err != nil {
+		return fmt.Errorf("load content: %w", err)
+	}
+	if content.IsTranscoded() {
+		logger.ForContext(ctx).Info("Reusing renditions of identical content",
+			zap.String("videoID", videoID.String()),
+			zap.String("renditionsPrefix", content.RenditionsPrefix))
+		return uc.videoRepo.UpdateRenditions(ctx, videoID, content.RenditionsPrefix)
+	}
+
+	// Concurrent uploads of the same content write identical objects to the same keys
+	prefix := storagekey.ContentRenditions(video.ContentSHA256)
+	if err := uc.transcodeTo(ctx, video, inputPath, workDir, prefix); err != nil {
+		return err
+	}
+	if err := uc.contentRepo.UpdateRenditions(ctx, video.ContentSHA256, prefix); err != nil {
+		return fmt.Errorf("save content renditions: %w", err)
+	}
+	return uc.videoRepo.UpdateRenditions(ctx, videoID, prefix)
+}
+
+// transcodeTo produces the HLS renditions of the original and uploads them under prefix
+func (uc *TranscodingUseCase) transcodeTo(ctx context.Context, video *entity.Video, inputPath, workDir, prefix string) error {
+	outputDir := filepath.Join(workDir, "hls")
+	if err := uc.transcoder.TranscodeHLS(ctx, inputPath, outputDir, video.Width, video.Height); err != nil {
+		return err
+	}
+
+	if err := uc.uploadDir(ctx, outputDir, prefix); err != nil {
+		return fmt.Errorf("upload renditions: %w", err)
+	}
+	return nil
+}
+
+// generateArtwork renders the automatic cover, the animated preview and the
+// scrub storyboard. The cover becomes the thumbnail unless the creator uploaded one.
+func (uc *TranscodingUseCase) generateArtwork(ctx context.Context, video *entity.Video, inputPath, workDir string) error {
+	duration := float64(video.DurationSeconds)
+
+	coverDir := filepath.Join(workDir, "cover")
+	if err := uc.artworkRenderer.RenderCover(ctx, inputPath, coverDir, -1, duration); err != nil {
+		return fmt.Errorf("render cover: %w", err)
+	}
+	artworkDir := filepath.Join(workDir, "artwork")
+	if err := uc.artworkRenderer.RenderPreviews(ctx, inputPath, artworkDir, duration, video.Width, video.Height); err != nil {
+		return fmt.Errorf("render previews: %w", err)
+	}
+
+	coverPrefix := storagekey.CoverSet(video.VideoID, "auto")
+	if err := uc.uploadDir(ctx, coverDir, coverPrefix); err != nil {
+		return fmt.Errorf("upload cover: %w", err)
+	}
+	if err := uc.uploadDir(ctx, artworkDir, storagekey.Artwork(video.VideoID)); err != nil {
+		return fmt.Errorf("upload previews: %w", err)
+	}
+
+	video.CoverPrefix = coverPrefix
+	video.CoverAtMs = nil
+	video.PreviewKey = storagekey.Preview(video.VideoID)
+	video.StoryboardKey = storagekey.Storyboard(video.VideoID)
+	if video.ThumbnailKey == "" {
+		video.ThumbnailKey = coverPrefix + defaultCoverFile()
+		video.ThumbnailURL = uc.storageService.URL(video.ThumbnailKey)
+	}
+	return uc.videoRepo.UpdateArtwork(ctx, video)
+}
+
+// renderCover runs a cover job: the frame picked by the creator replaces the
+// cover images and the thumbnail, including an uploaded thumbnail
+func (uc *TranscodingUseCase) renderCover(ctx context.Context, job *entity.TranscodingJob) error {
+	video, err := uc.videoRepo.GetByID(ctx, job.VideoID)
+	if err != nil {
+		return fmt.Errorf("load video: %w", err)
+	}
+	if !video.IsReady() || job.CoverAtMs == nil {
+		return fmt.Errorf("%w: status %s", errNotTranscodable, video.EncodingStatus)
+	}
+
+	workDir, err := os.MkdirTemp(uc.config.WorkDir, "cover-"+video.VideoID.String()+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	inputPath := filepath.Join(workDir, "original")
+	if err := downloadObject(ctx, uc.storageService, video.VideoKey, inputPath); err != nil {
+		return fmt.Errorf("download original: %w", err)
+	}
+
+	at := time.Duration(*job.CoverAtMs) * time.Millisecond
+	coverDir := filepath.Join(workDir, "cover")
+	if err := uc.artworkRenderer.RenderCover(ctx, inputPath, coverDir, at, float64(video.DurationSeconds)); err != nil {
+		return fmt.Errorf("render cover: %w", err)
+	}
+
+	coverPrefix := storagekey.CoverSet(video.VideoID, strconv.FormatInt(*job.CoverAtMs, 10))
+	if err := uc.uploadDir(ctx, coverDir, coverPrefix); err != nil {
+		return fmt.Errorf("upload cover: %w", err)
+	}
+
+	video.CoverPrefix = coverPrefix
+	video.CoverAtMs = job.CoverAtMs
+	video.ThumbnailKey = coverPrefix + defaultCoverFile()
+	video.ThumbnailURL = uc.storageService.URL(video.ThumbnailKey)
+	return uc.videoRepo.UpdateArtwork(ctx, video)
+}
+
+// renderDownload runs a watermark job: the original is rendered with the
+// watermark and stored as the download of the video
+func (uc *TranscodingUseCase) renderDownload(ctx context.Context, job *entity.TranscodingJob) error {
+	video, err := uc.videoRepo.GetByID(ctx, job.VideoID)
+	if err != nil {
+		return fmt.Errorf("load video: %w", err)
+	}
+	if !video.IsReady() {
+		return fmt.Errorf("%w: status %s", errNotTranscodable, video.EncodingStatus)
+	}
+
+	workDir, err := os.MkdirTemp(uc.config.WorkDir, "watermark-"+video.VideoID.String()+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	inputPath := filepath.Join(workDir, "original")
+	if err := downloadObject(ctx, uc.storageService, video.VideoKey, inputPath); err != nil {
+		return fmt.Errorf("download original: %w", err)
+	}
+
+	outputPath := filepath.Join(workDir, "download.mp4")
+	if err := uc.watermarker.Watermark(ctx, inputPath, outputPath, uc.config.WatermarkText); err != nil {
+		return fmt.Errorf("watermark: %w", err)
+	}
+
+	f, err := os.Open(outputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	key := storagekey.Download(video.VideoID)
+	if err := uc.storageService.Put(ctx, key, f, "video/mp4"); err != nil {
+		return fmt.Errorf("upload download: %w", err)
+	}
+	return uc.videoRepo.UpdateDownload(ctx, video.VideoID, key)
+}
+
+// defaultCoverFile is the cover image used as the video thumbnail: the largest JPEG
+func defaultCoverFile() string {
+	return storagekey.CoverFile(storagekey.CoverWidths[len(storagekey.CoverWidths)-1], storagekey.CoverFormatJPEG)
+}
+
+// uploadDir uploads every file under dir, keyed by its path relative to dir
+func (uc *TranscodingUseCase) uploadDir(ctx context.Context, dir, prefix string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return uc.storageService.Put(ctx, prefix+filepath.ToSlash(rel), f, hlsContentType(path))
+	})
+}
+
+// downloadObject copies a stored object to a local file
+func downloadObject(ctx context.Context, storageService StorageService, key, path string) error {
+	r, err := storageService.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// downloadObjectSHA256 copies a stored object to a local file and returns
+// the hex-encoded SHA-256 of its content
+func downloadObjectSHA256(ctx context.Context, storageService StorageService, key, path string) (string, error) {
+	r, err := storageService.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), r); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hlsContentType returns the content type of an HLS or artwork output file
+func hlsContentType(path string) string {
+	switch filepath.Ext(path) {
+	case ".m3u8":
+		return "application/vnd.apple.mpegurl"
+	case ".ts":
+		return "video/mp2t"
+	case ".jpg":
+		return "image/jpeg"
+	case ".webp":
+		return "image/webp"
+	case ".vtt":
+		return "text/vtt"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"math"
+	"time"
+
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+)
+
+// minScoreInterval keeps two runs in quick succession from dividing by almost nothing
+const minScoreInterval = time.Minute
+
+// scoreCandidate computes the trending score of a candidate at now, from its
+// previous snapshot or nil for the first one.
+//
+// The view and engagement velocities are the growth of the counts per hour
+// since the previous snapshot, smoothed with an exponential moving average
+// over the velocity window. Their weighted sum is boosted by the completion
+// rate and halved every half-life of video age, so old videos drop out however
+// many views they collected.
+func scoreCandidate(cfg config.TrendingConfig, c *entity.TrendingCandidate, prev *entity.TrendingScore, now time.Time) *entity.TrendingScore {
+	w := cfg.Weights
+
+	since := c.CreatedAt
+	var base entity.TrendingScore
+	if prev != nil {
+		since = prev.ComputedAt
+		base = *prev
+	}
+	elapsed := now.Sub(since)
+	if prev == nil && elapsed < cfg.VelocityWindow {
+		// A video minutes old would otherwise jump to the top with its first views
+		elapsed = cfg.VelocityWindow
+	}
+	hours := elapsed.Hours()
+	if elapsed < minScoreInterval {
+		hours = minScoreInterval.Hours()
+	}
+
+	viewVelocity := growth(c.ViewCount, base.ViewCount) / hours
+	engagementRate := (w.Like*growth(c.LikeCount, base.LikeCount) +
+		w.Comment*growth(c.CommentCount, base.CommentCount) +
+		w.Share*growth(c.ShareCount, base.ShareCount)) / hours
+	if prev != nil {
+		alpha := 1 - math.Exp(-math.Max(elapsed.Hours(), 0)/cfg.VelocityWindow.Hours())
+		viewVelocity = prev.ViewVelocity + alpha*(viewVelocity-prev.ViewVelocity)
+		engagementRate = prev.EngagementRate + alpha*(engagementRate-prev.EngagementRate)
+	}
+
+	age := math.Max(now.Sub(c.CreatedAt).Hours(), 0)
+	decay := math.Exp(-math.Ln2 * age / cfg.HalfLife.Hours())
+	completion := math.Min(math.Max(c.CompletionRate, 0), 1)
+
+	return &entity.TrendingScore{
+		VideoID:        c.VideoID,
+		ComputedAt:     now,
+		Region:         c.Region,
+		ViewCount:      c.ViewCount,
+		LikeCount:      c.LikeCount,
+		CommentCount:   c.CommentCount,
+		ShareCount:     c.ShareCount,
+		ViewVelocity:   viewVelocity,
+		EngagementRate: engagementRate,
+		CompletionRate: completion,
+		TrendingScore:  (w.View*viewVelocity + engagementRate) * (1 + w.Completion*completion) * decay,
+	}
+}
+
+// windowScore ranks a candidate within the window from windowStart to now: the
+// weighted growth of its counts since baseline, its snapshot at the start of
+// the window, boosted by the completion rate like the trending score. Without
+// an exact baseline, the growth since the closest snapshot, or since upload,
+// is scaled to the length of the window.
+func windowScore(cfg config.TrendingConfig, c *entity.TrendingCandidate, baseline *entity.TrendingScore, windowStart, now time.Time) float64 {
+	w := cfg.Weights
+
+	since := c.CreatedAt
+	var base entity.TrendingScore
+	if baseline != nil && c.CreatedAt.Before(windowStart) {
+		since = baseline.ComputedAt
+		base = *baseline
+	}
+
+	gained := w.View*growth(c.ViewCount, base.ViewCount) +
+		w.Like*growth(c.LikeCount, base.LikeCount) +
+		w.Comment*growth(c.CommentCount, base.CommentCount) +
+		w.Share*growth(c.ShareCount, base.ShareCount)
+	if c.CreatedAt.Before(windowStart) {
+		elapsed := now.Sub(since)
+		if elapsed < minScoreInterval {
+			elapsed = minScoreInterval
+		}
+		gained *= now.Sub(windowStart).Hours() / elapsed.Hours()
+	}
+
+	completion := math.Min(math.Max(c.CompletionRate, 0), 1)
+	return gained * (1 + w.Completion*completion)
+}
+
+// growth returns how much a count grew, ignoring decreases such as unlikes
+func growth(current, previous int64) float64 {
+	if current <= previous {
+		return 0
+	}
+	return float64(current - previous)
+}
@@ -0,0 +1,424 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TrendingListGlobal names the trending list of every region. Regional lists
+// are named by their country code, and category and hashtag lists by
+// "category:" and "hashtag:" followed by the category or hashtag. Lists of a
+// time window end with ":" and the window, as in "VN:day".
+const TrendingListGlobal = "global"
+
+// trendingBatchSize is how many candidates are scored at once
+const trendingBatchSize = 1000
+
+// TrendingWindow is the period a trending list ranks growth over
+type TrendingWindow string
+
+// Trending windows
+const (
+	// TrendingWindowDefault ranks by the decayed trending score
+	TrendingWindowDefault TrendingWindow = ""
+	TrendingWindowHour    TrendingWindow = "hour"
+	TrendingWindowDay     TrendingWindow = "day"
+	TrendingWindowWeek    TrendingWindow = "week"
+)
+
+// trendingWindows maps every time window to its length
+var trendingWindows = map[TrendingWindow]time.Duration{
+	TrendingWindowHour: time.Hour,
+	TrendingWindowDay:  24 * time.Hour,
+	TrendingWindowWeek: 7 * 24 * time.Hour,
+}
+
+// ParseTrendingWindow validates a time window sent by a client
+func ParseTrendingWindow(value string) (TrendingWindow, error) {
+	window := TrendingWindow(value)
+	if _, ok := trendingWindows[window]; !ok && window != TrendingWindowDefault {
+		return "", fmt.Errorf("unknown trending window %q", value)
+	}
+	return window, nil
+}
+
+// RankedVideo is one entry of a trending list
+type RankedVideo struct {
+	VideoID uuid.UUID
+	Score   float64
+}
+
+// TrendingStore interface for the ranked trending lists served to clients.
+// Every run of the trending job publishes a new generation of each list.
+type TrendingStore interface {
+	// ReplaceRanking publishes a generation of a list, which expires after ttl
+	ReplaceRanking(ctx context.Context, list, generation string, ranking []RankedVideo, ttl time.Duration) error
+	// Ranking returns the video IDs of a generation of a list from offset,
+	// best first, and the generation; "" reads the current one. It returns
+	// false if the list or generation does not exist.
+	Ranking(ctx context.Context, list, generation string, offset, limit int) ([]uuid.UUID, string, bool, error)
+}
+
+// RegionPreferences interface for the content region users choose in their settings
+type RegionPreferences interface {
+	// PreferredRegion returns the country code a user chose, "" if none
+	PreferredRegion(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// TrendingUseCase scores recent videos and serves the trending lists
+type TrendingUseCase struct {
+	trendingRepo      repository.TrendingRepository
+	videoRepo         repository.VideoRepository
+	store             TrendingStore
+	regionPreferences RegionPreferences
+	videoUseCase      *VideoUseCase
+	cursorCodec       *pagination.Codec
+	trendingConfig    config.TrendingConfig
+}
+
+// NewTrendingUseCase creates a new trending use case. regionPreferences may be
+// nil, in which case callers get the list of the region they call from.
+func NewTrendingUseCase(
+	trendingRepo repository.TrendingRepository,
+	videoRepo repository.VideoRepository,
+	store TrendingStore,
+	regionPreferences RegionPreferences,
+	videoUseCase *VideoUseCase,
+	cursorCodec *pagination.Codec,
+	trendingConfig config.TrendingConfig,
+) *TrendingUseCase {
+	return &TrendingUseCase{
+		trendingRepo:      trendingRepo,
+		videoRepo:         videoRepo,
+		store:             store,
+		regionPreferences: regionPreferences,
+		videoUseCase:      videoUseCase,
+		cursorCodec:       cursorCodec,
+		trendingConfig:    trendingConfig,
+	}
+}
+
+// trendingList identifies a trending list: what it ranks, such as a region,
+// and over which window
+type trendingList struct {
+	scope  string
+	window TrendingWindow
+}
+
+// name returns the name the list is stored under
+func (l trendingList) name() string {
+	if l.window == TrendingWindowDefault {
+		return l.scope
+	}
+	return l.scope + ":" + string(l.window)
+}
+
+// RecomputeTrending scores every candidate video, stores the scores as a
+// snapshot and publishes the global, regional, category and hashtag lists of
+// every window. It runs periodically.
+func (uc *TrendingUseCase) RecomputeTrending(ctx context.Context) error {
+	cfg := uc.trendingConfig
+	now := time.Now()
+	createdAfter := now.Add(-cfg.CandidateAge)
+	viewsSince := now.Add(-cfg.HalfLife)
+
+	rankings := map[trendingList][]RankedVideo{}
+	regionCandidates := map[string]int{}
+	hashtagCandidates := map[string]int{}
+	scored := 0
+	afterID := uuid.Nil
+	for {
+		candidates, err := uc.trendingRepo.ListCandidates(ctx, createdAfter, viewsSince, afterID, trendingBatchSize)
+		if err != nil {
+			return fmt.Errorf("list trending candidates: %w", err)
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		videoIDs := make([]uuid.UUID, len(candidates))
+		for i, c := range candidates {
+			videoIDs[i] = c.VideoID
+		}
+		previous, err := uc.trendingRepo.ScoresAt(ctx, videoIDs, now)
+		if err != nil {
+			return fmt.Errorf("load previous trending scores: %w", err)
+		}
+		baselines := make(map[TrendingWindow]map[uuid.UUID]*entity.TrendingScore, len(trendingWindows))
+		for window, length := range trendingWindows {
+			if baselines[window], err = uc.trendingRepo.ScoresAt(ctx, videoIDs, now.Add(-length)); err != nil {
+				return fmt.Errorf("load %s trending baselines: %w", window, err)
+			}
+		}
+
+		scores := make([]*entity.TrendingScore, len(candidates))
+		for i, c := range candidates {
+			scores[i] = scoreCandidate(cfg, c, previous[c.VideoID], now)
+
+			scopes := []string{TrendingListGlobal}
+			if c.Region != "" {
+				scopes = append(scopes, c.Region)
+				regionCandidates[c.Region]++
+			}
+			if c.Category != entity.CategoryNone {
+				scopes = append(scopes, "category:"+string(c.Category))
+			}
+			for _, hashtag := range c.Hashtags {
+				scopes = append(scopes, "hashtag:"+hashtag)
+				hashtagCandidates["hashtag:"+hashtag]++
+			}
+
+			windowScores := make(map[TrendingWindow]float64, len(trendingWindows))
+			for window, length := range trendingWindows {
+				windowScores[window] = windowScore(cfg, c, baselines[window][c.VideoID], now.Add(-length), now)
+			}
+			for _, scope := range scopes {
+				list := trendingList{scope: scope}
+				rankings[list] = append(rankings[list], RankedVideo{VideoID: c.VideoID, Score: scores[i].TrendingScore})
+				for window, score := range windowScores {
+					// Videos that gained nothing in the window are not trending in it
+					if score > 0 {
+						list := trendingList{scope: scope, window: window}
+						rankings[list] = append(rankings[list], RankedVideo{VideoID: c.VideoID, Score: score})
+					}
+				}
+			}
+		}
+		if err := uc.trendingRepo.SaveScores(ctx, scores); err != nil {
+			return fmt.Errorf("save trending scores: %w", err)
+		}
+
+		scored += len(candidates)
+		if len(candidates) < trendingBatchSize {
+			break
+		}
+		afterID = candidates[len(candidates)-1].VideoID
+	}
+
+	// Small regions get no list and their callers the global one; only the
+	// most used hashtags get lists
+	skipped := map[string]bool{}
+	for region, count := range regionCandidates {
+		if count < cfg.MinRegionCandidates {
+			skipped[region] = true
+		}
+	}
+	hashtags := make([]string, 0, len(hashtagCandidates))
+	for hashtag := range hashtagCandidates {
+		hashtags = append(hashtags, hashtag)
+	}
+	sort.Slice(hashtags, func(i, j int) bool {
+		if hashtagCandidates[hashtags[i]] != hashtagCandidates[hashtags[j]] {
+			return hashtagCandidates[hashtags[i]] > hashtagCandidates[hashtags[j]]
+		}
+		return hashtags[i] < hashtags[j]
+	})
+	if len(hashtags) > cfg.MaxHashtagLists {
+		for _, hashtag := range hashtags[cfg.MaxHashtagLists:] {
+			skipped[hashtag] = true
+		}
+	}
+
+	// Lists outlive a few failed runs, then the snapshot in the database is
+	// served instead. A generation stays readable that long after it was
+	// replaced, for clients paging through it.
+	ttl := 3 * cfg.Interval
+	generation := strconv.FormatInt(now.UnixNano(), 36)
+	published := 0
+	for list, ranking := range rankings {
+		if skipped[list.scope] {
+			continue
+		}
+		sort.Slice(ranking, func(i, j int) bool { return ranking[i].Score > ranking[j].Score })
+		if len(ranking) > cfg.ListSize {
+			ranking = ranking[:cfg.ListSize]
+		}
+		if err := uc.store.ReplaceRanking(ctx, list.name(), generation, ranking, ttl); err != nil {
+			return fmt.Errorf("publish trending list %s: %w", list.name(), err)
+		}
+		published++
+	}
+
+	pruned, err := uc.trendingRepo.PruneScores(ctx, now.Add(-cfg.SnapshotThinAfter), now.Add(-cfg.SnapshotRetention))
+	if err != nil {
+		return fmt.Errorf("prune trending scores: %w", err)
+	}
+
+	logger.ForContext(ctx).Info("Trending scores recomputed",
+		zap.Int("videos", scored), zap.Int("lists", published), zap.Int64("prunedSnapshots", pruned))
+	return nil
+}
+
+// GetTrendingVideos retrieves a page of the trending list of a hashtag, a
+// category or a region. Without an explicit region the viewer's preferred
+// region is used, else the one the request came from; a region without a list
+// of its own falls back to the global list.
+func (uc *TrendingUseCase) GetTrendingVideos(ctx context.Context, req *dto.TrendingVideosRequest, viewer Viewer) (*dto.TrendingVideosResponse, error) {
+	limit := pagination.NormalizeLimit(req.Limit)
+
+	window, err := ParseTrendingWindow(req.Window)
+	if err != nil {
+		return nil, errors.ErrInvalidParam
+	}
+
+	var lists []trendingList
+	region := ""
+	switch {
+	case req.Hashtag != "" && req.Category != "":
+		return nil, errors.ErrInvalidParam
+	case req.Hashtag != "":
+		hashtag, ok := entity.NormalizeHashtag(req.Hashtag)
+		if !ok {
+			return nil, errors.ErrInvalidParam
+		}
+		lists = []trendingList{{scope: "hashtag:" + hashtag, window: window}}
+	case req.Category != "":
+		category, err := entity.ParseCategory(req.Category)
+		if err != nil {
+			return nil, errors.ErrInvalidParam
+		}
+		lists = []trendingList{{scope: "category:" + string(category), window: window}}
+	default:
+		if region = uc.viewerRegion(ctx, req, viewer); region != "" {
+			lists = append(lists, trendingList{scope: region, window: window})
+		}
+		lists = append(lists, trendingList{scope: TrendingListGlobal, window: window})
+	}
+
+	scope := "trending:" + lists[0].name()
+	after, err := uc.cursorCodec.Decode(scope, req.Cursor)
+	if err != nil {
+		return nil, errors.ErrInvalidParam
+	}
+	var generation string
+	offset := 0
+	if after != nil {
+		generation = after.ID
+		offset = int(after.Score)
+	}
+
+	// One extra entry tells whether another page follows
+	list, videoIDs, generation := uc.rankedVideoIDs(ctx, lists, generation, offset, limit+1)
+	if list == nil && offset == 0 && window == TrendingWindowDefault && req.Hashtag == "" && req.Category == "" {
+		// Without lists in the store, e.g. before the first run after a Redis
+		// restart, the last snapshot in the database is served as one page
+		if list, videoIDs, err = uc.snapshotVideoIDs(ctx, lists, limit); err != nil {
+			logger.ForContext(ctx).Error("Failed to load trending snapshot", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+		generation = ""
+	}
+
+	response := &dto.TrendingVideosResponse{
+		Videos: []*dto.VideoResponse{},
+	}
+	if list != nil && region != "" && list.scope == region {
+		response.Region = region
+	}
+	if len(videoIDs) > limit {
+		videoIDs = videoIDs[:limit]
+		if generation != "" {
+			response.HasMore = true
+			response.NextCursor = uc.cursorCodec.Encode(scope, pagination.Cursor{ID: generation, Score: int64(offset + limit)})
+		}
+	}
+
+	videos, err := uc.videoRepo.GetByIDs(ctx, videoIDs)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to load trending videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	byID := make(map[uuid.UUID]*entity.Video, len(videos))
+	for _, video := range videos {
+		byID[video.VideoID] = video
+	}
+	uc.videoUseCase.loadCounts(ctx, videos...)
+	uc.videoUseCase.loadAnnotations(ctx, videos...)
+	for _, videoID := range videoIDs {
+		video, ok := byID[videoID]
+		// Made private or taken down since the list was computed
+		if !ok || video.Visibility != entity.VisibilityPublic || !video.IsReady() {
+			continue
+		}
+		response.Videos = append(response.Videos, uc.videoUseCase.toVideoResponse(ctx, video))
+	}
+
+	return response, nil
+}
+
+// viewerRegion returns the region whose list a caller gets
+func (uc *TrendingUseCase) viewerRegion(ctx context.Context, req *dto.TrendingVideosRequest, viewer Viewer) string {
+	if req.Region != "" {
+		return req.Region
+	}
+	if uc.regionPreferences != nil && !viewer.IsAnonymous() {
+		region, err := uc.regionPreferences.PreferredRegion(ctx, viewer.UserID)
+		if err != nil {
+			logger.ForContext(ctx).Warn("Failed to load preferred region", zap.Error(err))
+		} else if region != "" {
+			return region
+		}
+	}
+	return req.ClientRegion
+}
+
+// rankedVideoIDs returns a page of the first of lists that exists, and the
+// list, or nil if none does. A generation that expired since the previous page
+// is replaced by the current one.
+func (uc *TrendingUseCase) rankedVideoIDs(ctx context.Context, lists []trendingList, generation string, offset, limit int) (*trendingList, []uuid.UUID, string) {
+	for {
+		for i, list := range lists {
+			videoIDs, listGeneration, ok, err := uc.store.Ranking(ctx, list.name(), generation, offset, limit)
+			if err != nil {
+				logger.ForContext(ctx).Warn("Failed to read trending list", zap.String("list", list.name()), zap.Error(err))
+				return nil, nil, ""
+			}
+			if ok {
+				return &lists[i], videoIDs, listGeneration
+			}
+		}
+		if generation == "" {
+			return nil, nil, ""
+		}
+		generation = ""
+	}
+}
+
+// snapshotVideoIDs returns the best videos of the last snapshot of the
+// regional list, else of the global one, and the list they come from
+func (uc *TrendingUseCase) snapshotVideoIDs(ctx context.Context, lists []trendingList, limit int) (*trendingList, []uuid.UUID, error) {
+	for i, list := range lists {
+		region := list.scope
+		if region == TrendingListGlobal {
+			region = ""
+		}
+		scores, err := uc.trendingRepo.TopScores(ctx, region, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(scores) == 0 {
+			continue
+		}
+
+		videoIDs := make([]uuid.UUID, len(scores))
+		for j, score := range scores {
+			videoIDs[j] = score.VideoID
+		}
+		return &lists[i], videoIDs, nil
+	}
+	return nil, nil, nil
+}
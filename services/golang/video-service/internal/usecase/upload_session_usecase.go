@@ -0,0 +1,551 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/domain/storagekey"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxUploadParts is the largest number of parts a multipart upload may have (S3 limit)
+const maxUploadParts = 10000
+
+// expiredSessionBatchSize limits how many sessions are aborted per sweep
+const expiredSessionBatchSize = 100
+
+// UploadSessionUseCase handles resumable uploads
+type UploadSessionUseCase struct {
+	sessionRepo    repository.UploadSessionRepository
+	storageService StorageService
+	videoUseCase   *VideoUseCase
+	uploadConfig   config.UploadConfig
+}
+
+// NewUploadSessionUseCase creates a new upload session use case
+func NewUploadSessionUseCase(
+	sessionRepo repository.UploadSessionRepository,
+	storageService StorageService,
+	videoUseCase *VideoUseCase,
+	uploadConfig config.UploadConfig,
+) *UploadSessionUseCase {
+	return &UploadSessionUseCase{
+		sessionRepo:    sessionRepo,
+		storageService: storageService,
+		videoUseCase:   videoUseCase,
+		uploadConfig:   uploadConfig,
+	}
+}
+
+// CreateUploadSession starts a resumable upload and returns its upload ID and chunk size
+func (uc *UploadSessionUseCase) CreateUploadSession(ctx context.Context, req *dto.CreateUploadSessionRequest) (*dto.UploadSessionResponse, error) {
+	log := logger.ForContext(ctx)
+
+	if req.FileSize <= 0 {
+		return nil, errors.ErrInvalidParam
+	}
+	if req.FileSize > uc.uploadConfig.MaxFileSize {
+		return nil, uc.videoUseCase.videoTooLarge()
+	}
+
+	// Sessions are charged when created, so abandoning them does not get around the quota
+	charge, err := uc.videoUseCase.chargeUploadQuota(ctx, req.UserID, 1, req.FileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Grow the chunk size for huge files so the part count stays within the storage limit
+	chunkSize := uc.uploadConfig.ChunkSize
+	if minChunk := (req.FileSize + maxUploadParts - 1) / maxUploadParts; chunkSize < minChunk {
+		chunkSize = minChunk
+	}
+
+	videoID := uuid.New()
+	storageUploadID, err := uc.storageService.CreateVideoUpload(ctx, videoID)
+	if err != nil {
+		log.Error("Failed to start storage upload", zap.Error(err))
+		uc.videoUseCase.refundUploadQuota(ctx, charge)
+		return nil, errors.ErrInternal
+	}
+
+	session := &entity.UploadSession{
+		SessionID:       uuid.New(),
+		UserID:          req.UserID,
+		VideoID:         videoID,
+		StorageUploadID: storageUploadID,
+		Title:           req.Title,
+		Description:     req.Description,
+		DurationSeconds: req.DurationSeconds,
+		Width:           req.Width,
+		Height:          req.Height,
+		TotalSize:       req.FileSize,
+		ChunkSize:       chunkSize,
+		Mode:            entity.UploadModeChunked,
+		Status:          entity.UploadSessionActive,
+		ExpiresAt:       time.Now().Add(uc.uploadConfig.SessionTTL),
+	}
+
+	if err := uc.sessionRepo.Create(ctx, session); err != nil {
+		log.Error("Failed to save upload session", zap.Error(err))
+		uc.abortStorageUpload(ctx, session)
+		uc.videoUseCase.refundUploadQuota(ctx, charge)
+		return nil, errors.ErrInternal
+	}
+
+	log.Info("Upload session created",
+		zap.String("sessionID", session.SessionID.String()),
+		zap.Int("totalParts", session.TotalParts()))
+	return uc.toUploadSessionResponse(session, nil), nil
+}
+
+// UploadChunk stores one numbered part of a resumable upload.
+// Re-sending a part replaces the previous copy, so clients can safely retry.
+func (uc *UploadSessionUseCase) UploadChunk(ctx context.Context, req *dto.UploadChunkRequest) (*dto.UploadSessionResponse, error) {
+	log := logger.ForContext(ctx)
+
+	session, err := uc.getOwnedSession(ctx, req.SessionID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Mode != entity.UploadModeChunked {
+		return nil, errors.ErrInvalidParam
+	}
+	if err := uc.checkAcceptsParts(session); err != nil {
+		return nil, err
+	}
+
+	if req.PartNumber < 1 || req.PartNumber > session.TotalParts() {
+		return nil, errors.ErrInvalidParam
+	}
+	if int64(len(req.Data)) != session.PartSize(req.PartNumber) {
+		return nil, errors.ErrInvalidParam
+	}
+
+	etag, err := uc.storageService.UploadVideoPart(ctx, session.VideoID, session.StorageUploadID, req.PartNumber, req.Data)
+	if err != nil {
+		log.Error("Failed to upload part", zap.Int("partNumber", req.PartNumber), zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	part := &entity.UploadPart{
+		SessionID:  session.SessionID,
+		PartNumber: req.PartNumber,
+		ETag:       etag,
+		Size:       int64(len(req.Data)),
+	}
+	if err := uc.sessionRepo.SavePart(ctx, part); err != nil {
+		log.Error("Failed to save part", zap.Int("partNumber", req.PartNumber), zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	return uc.sessionResponse(ctx, session)
+}
+
+// GetUploadSession reports the state of a resumable upload and which parts were received
+func (uc *UploadSessionUseCase) GetUploadSession(ctx context.Context, sessionID, userID uuid.UUID) (*dto.UploadSessionResponse, error) {
+	session, err := uc.getOwnedSession(ctx, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sessionResponse(ctx, session)
+}
+
+// CompleteUpload assembles the received parts and creates the video
+func (uc *UploadSessionUseCase) CompleteUpload(ctx context.Context, req *dto.CompleteUploadRequest) (*dto.VideoResponse, error) {
+	log := logger.ForContext(ctx)
+
+	session, err := uc.getOwnedSession(ctx, req.SessionID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Mode != entity.UploadModeChunked {
+		return nil, errors.ErrInvalidParam
+	}
+	if err := uc.checkAcceptsParts(session); err != nil {
+		return nil, err
+	}
+
+	parts, err := uc.sessionRepo.GetParts(ctx, session.SessionID)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	if len(parts) != session.TotalParts() {
+		return nil, ErrUploadIncomplete
+	}
+	thumbnailType, err := uc.videoUseCase.checkThumbnail(req.ThumbnailData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Claim the session so concurrent completions cannot assemble it twice
+	claimed, err := uc.sessionRepo.TransitionStatus(ctx, session.SessionID, entity.UploadSessionActive, entity.UploadSessionCompleting)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	if !claimed {
+		return nil, ErrUploadSessionClosed
+	}
+
+	videoKey, err := uc.storageService.CompleteVideoUpload(ctx, session.VideoID, session.StorageUploadID, parts)
+	if err != nil {
+		log.Error("Failed to assemble upload", zap.Error(err))
+		uc.releaseSession(ctx, session)
+		return nil, errors.ErrInternal
+	}
+
+	video := &entity.Video{
+		VideoID:        session.VideoID,
+		UserID:         session.UserID,
+		Region:         req.Region,
+		Title:          session.Title,
+		Description:    session.Description,
+		VideoURL:       uc.storageService.URL(videoKey),
+		VideoKey:       videoKey,
+		FileSize:       session.TotalSize,
+		EncodingStatus: entity.EncodingStatusUploaded,
+		Visibility:     entity.VisibilityPublic,
+		IsPublic:       true,
+		AllowComments:  true,
+		AllowDuet:      true,
+		AllowStitch:    true,
+	}
+
+	response, err := uc.videoUseCase.completeUpload(ctx, video, nil, req.ThumbnailData, thumbnailType)
+	if err != nil {
+		// The parts are consumed, so the session cannot be completed again
+		uc.failSession(ctx, session)
+		return nil, err
+	}
+
+	if _, err := uc.sessionRepo.TransitionStatus(ctx, session.SessionID, entity.UploadSessionCompleting, entity.UploadSessionCompleted); err != nil {
+		log.Warn("Failed to mark upload session completed", zap.Error(err))
+	}
+
+	return response, nil
+}
+
+// RequestUploadURL creates a pending video and returns presigned URLs so the client
+// can upload the file straight to storage. Large files get one URL per multipart part.
+func (uc *UploadSessionUseCase) RequestUploadURL(ctx context.Context, req *dto.RequestUploadURLRequest) (*dto.UploadURLResponse, error) {
+	log := logger.ForContext(ctx)
+
+	if req.FileSize <= 0 {
+		return nil, errors.ErrInvalidParam
+	}
+	if req.FileSize > uc.uploadConfig.MaxFileSize {
+		return nil, uc.videoUseCase.videoTooLarge()
+	}
+	if req.ChecksumSHA256 != "" {
+		sum, err := base64.StdEncoding.DecodeString(req.ChecksumSHA256)
+		if err != nil || len(sum) != 32 {
+			return nil, errors.ErrInvalidParam
+		}
+	}
+
+	charge, err := uc.videoUseCase.chargeUploadQuota(ctx, req.UserID, 1, req.FileSize)
+	if err != nil {
+		return nil, err
+	}
+	// Refund unless the session is saved
+	saved := false
+	defer func() {
+		if !saved {
+			uc.videoUseCase.refundUploadQuota(ctx, charge)
+		}
+	}()
+
+	session := &entity.UploadSession{
+		SessionID:       uuid.New(),
+		UserID:          req.UserID,
+		VideoID:         uuid.New(),
+		Title:           req.Title,
+		Description:     req.Description,
+		DurationSeconds: req.DurationSeconds,
+		Width:           req.Width,
+		Height:          req.Height,
+		TotalSize:       req.FileSize,
+		ChunkSize:       req.FileSize,
+		Mode:            entity.UploadModePresigned,
+		ChecksumSHA256:  req.ChecksumSHA256,
+		Status:          entity.UploadSessionActive,
+		ExpiresAt:       time.Now().Add(uc.uploadConfig.SessionTTL),
+	}
+
+	response := &dto.UploadURLResponse{
+		VideoID:   session.VideoID.String(),
+		UploadID:  session.SessionID.String(),
+		Headers:   map[string]string{"Content-Type": "video/mp4"},
+		ExpiresAt: time.Now().Add(uc.uploadConfig.PresignTTL),
+	}
+
+	if req.FileSize <= uc.uploadConfig.PresignMultipartThreshold {
+		url, err := uc.storageService.PresignVideoUpload(ctx, session.VideoID, req.ChecksumSHA256, uc.uploadConfig.PresignTTL)
+		if err != nil {
+			log.Error("Failed to presign upload URL", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+		response.UploadURL = url
+		if req.ChecksumSHA256 != "" {
+			response.Headers["x-amz-checksum-sha256"] = req.ChecksumSHA256
+		}
+	} else {
+		session.ChunkSize = uc.uploadConfig.ChunkSize
+		if minChunk := (req.FileSize + maxUploadParts - 1) / maxUploadParts; session.ChunkSize < minChunk {
+			session.ChunkSize = minChunk
+		}
+
+		storageUploadID, err := uc.storageService.CreateVideoUpload(ctx, session.VideoID)
+		if err != nil {
+			log.Error("Failed to start storage upload", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+		session.StorageUploadID = storageUploadID
+
+		response.Parts = make([]dto.PresignedPart, session.TotalParts())
+		for i := range response.Parts {
+			url, err := uc.storageService.PresignVideoPartUpload(ctx, session.VideoID, storageUploadID, i+1, uc.uploadConfig.PresignTTL)
+			if err != nil {
+				log.Error("Failed to presign part upload URL", zap.Error(err))
+				uc.abortStorageUpload(ctx, session)
+				return nil, errors.ErrInternal
+			}
+			response.Parts[i] = dto.PresignedPart{PartNumber: i + 1, URL: url}
+		}
+	}
+	response.ChunkSize = session.ChunkSize
+
+	video := &entity.Video{
+		VideoID:       session.VideoID,
+		UserID:        session.UserID,
+		Region:        req.Region,
+		Title:         session.Title,
+		Description:   session.Description,
+		Visibility:    entity.VisibilityPublic,
+		IsPublic:      true,
+		AllowComments: true,
+		AllowDuet:     true,
+		AllowStitch:   true,
+	}
+	if err := uc.videoUseCase.createPendingVideo(ctx, video); err != nil {
+		log.Error("Failed to save pending video", zap.Error(err))
+		uc.abortStorageUpload(ctx, session)
+		return nil, errors.ErrInternal
+	}
+
+	if err := uc.sessionRepo.Create(ctx, session); err != nil {
+		log.Error("Failed to save upload session", zap.Error(err))
+		uc.abortStorageUpload(ctx, session)
+		if err := uc.videoUseCase.discardPendingVideo(ctx, video.VideoID); err != nil {
+			log.Warn("Failed to discard pending video", zap.Error(err))
+		}
+		return nil, errors.ErrInternal
+	}
+
+	saved = true
+	log.Info("Presigned upload requested",
+		zap.String("videoID", video.VideoID.String()),
+		zap.Bool("multipart", session.IsMultipart()))
+	return response, nil
+}
+
+// ConfirmUpload verifies a presigned upload landed in storage with the declared size
+// and checksum, then records the file and starts transcoding
+func (uc *UploadSessionUseCase) ConfirmUpload(ctx context.Context, req *dto.ConfirmUploadRequest) (*dto.VideoResponse, error) {
+	log := logger.ForContext(ctx)
+
+	session, err := uc.sessionRepo.GetByVideoID(ctx, req.VideoID)
+	if err != nil || session.UserID != req.UserID {
+		return nil, errors.ErrNotFound
+	}
+	if session.Mode != entity.UploadModePresigned {
+		return nil, errors.ErrInvalidParam
+	}
+	if err := uc.checkAcceptsParts(session); err != nil {
+		return nil, err
+	}
+	if session.IsMultipart() && len(req.Parts) != session.TotalParts() {
+		return nil, ErrUploadIncomplete
+	}
+
+	claimed, err := uc.sessionRepo.TransitionStatus(ctx, session.SessionID, entity.UploadSessionActive, entity.UploadSessionCompleting)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	if !claimed {
+		return nil, ErrUploadSessionClosed
+	}
+
+	if session.IsMultipart() {
+		parts := make([]*entity.UploadPart, len(req.Parts))
+		for i, part := range req.Parts {
+			parts[i] = &entity.UploadPart{SessionID: session.SessionID, PartNumber: part.PartNumber, ETag: part.ETag}
+		}
+		if _, err := uc.storageService.CompleteVideoUpload(ctx, session.VideoID, session.StorageUploadID, parts); err != nil {
+			log.Error("Failed to assemble presigned upload", zap.Error(err))
+			uc.releaseSession(ctx, session)
+			return nil, ErrUploadIncomplete
+		}
+	}
+
+	file, err := uc.storageService.StatVideo(ctx, session.VideoID)
+	if err != nil {
+		log.Warn("Uploaded file not found in storage", zap.Error(err))
+		uc.releaseSession(ctx, session)
+		return nil, ErrUploadIncomplete
+	}
+	if file.Size != session.TotalSize {
+		uc.releaseSession(ctx, session)
+		return nil, ErrUploadMismatch
+	}
+	// Multipart objects only carry a checksum of the part checksums, so the
+	// whole-file checksum can only be compared for single PUT uploads
+	if !session.IsMultipart() && session.ChecksumSHA256 != "" && file.ChecksumSHA256 != session.ChecksumSHA256 {
+		uc.releaseSession(ctx, session)
+		return nil, ErrUploadMismatch
+	}
+
+	response, err := uc.videoUseCase.activateUploadedVideo(ctx, session.VideoID, file)
+	if err != nil {
+		uc.releaseSession(ctx, session)
+		return nil, err
+	}
+
+	if _, err := uc.sessionRepo.TransitionStatus(ctx, session.SessionID, entity.UploadSessionCompleting, entity.UploadSessionCompleted); err != nil {
+		log.Warn("Failed to mark upload session completed", zap.Error(err))
+	}
+
+	return response, nil
+}
+
+// AbortExpiredSessions aborts the storage uploads of sessions whose TTL has passed
+func (uc *UploadSessionUseCase) AbortExpiredSessions(ctx context.Context) error {
+	log := logger.ForContext(ctx)
+
+	sessions, err := uc.sessionRepo.GetExpired(ctx, time.Now(), expiredSessionBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		expired, err := uc.sessionRepo.TransitionStatus(ctx, session.SessionID, entity.UploadSessionActive, entity.UploadSessionExpired)
+		if err != nil {
+			return err
+		}
+		if !expired {
+			continue
+		}
+
+		uc.abortStorageUpload(ctx, session)
+
+		if session.Mode == entity.UploadModePresigned {
+			if err := uc.videoUseCase.discardPendingVideo(ctx, session.VideoID); err != nil {
+				log.Warn("Failed to discard pending video",
+					zap.String("videoID", session.VideoID.String()),
+					zap.Error(err))
+			}
+		}
+	}
+
+	if len(sessions) > 0 {
+		log.Info("Expired upload sessions aborted", zap.Int("count", len(sessions)))
+	}
+	return nil
+}
+
+// abortStorageUpload aborts the multipart upload of a session, if it has one
+func (uc *UploadSessionUseCase) abortStorageUpload(ctx context.Context, session *entity.UploadSession) {
+	if !session.IsMultipart() {
+		return
+	}
+	if err := uc.storageService.AbortVideoUpload(ctx, session.VideoID, session.StorageUploadID); err != nil {
+		logger.ForContext(ctx).Warn("Failed to abort storage upload",
+			zap.String("sessionID", session.SessionID.String()),
+			zap.Error(err))
+	}
+}
+
+// failSession closes a claimed session whose assembled file could not be made
+// into a video, deletes the file and refunds the quota charged for the session
+func (uc *UploadSessionUseCase) failSession(ctx context.Context, session *entity.UploadSession) {
+	log := logger.ForContext(ctx).With(zap.String("sessionID", session.SessionID.String()))
+
+	if _, err := uc.sessionRepo.TransitionStatus(ctx, session.SessionID, entity.UploadSessionCompleting, entity.UploadSessionExpired); err != nil {
+		log.Warn("Failed to close failed upload session", zap.Error(err))
+	}
+	if err := uc.storageService.DeletePrefix(ctx, storagekey.VideoPrefix(session.VideoID)); err != nil {
+		log.Warn("Failed to delete failed upload from storage", zap.Error(err))
+	}
+	uc.videoUseCase.refundUploadQuota(ctx, &quotaCharge{
+		userID: session.UserID,
+		day:    session.CreatedAt.UTC().Truncate(24 * time.Hour),
+		count:  1,
+		bytes:  session.TotalSize,
+	})
+}
+
+// getOwnedSession loads a session and hides sessions of other users
+func (uc *UploadSessionUseCase) getOwnedSession(ctx context.Context, sessionID, userID uuid.UUID) (*entity.UploadSession, error) {
+	session, err := uc.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if session.UserID != userID {
+		return nil, errors.ErrNotFound
+	}
+	return session, nil
+}
+
+// checkAcceptsParts ensures the session is still open for parts and completion
+func (uc *UploadSessionUseCase) checkAcceptsParts(session *entity.UploadSession) error {
+	if session.Status == entity.UploadSessionExpired || session.IsExpired(time.Now()) {
+		return ErrUploadSessionExpired
+	}
+	if !session.IsActive() {
+		return ErrUploadSessionClosed
+	}
+	return nil
+}
+
+// releaseSession reopens a claimed session after a failed completion so the client can retry
+func (uc *UploadSessionUseCase) releaseSession(ctx context.Context, session *entity.UploadSession) {
+	if _, err := uc.sessionRepo.TransitionStatus(ctx, session.SessionID, entity.UploadSessionCompleting, entity.UploadSessionActive); err != nil {
+		logger.ForContext(ctx).Warn("Failed to release upload session", zap.Error(err))
+	}
+}
+
+// sessionResponse loads the received parts and builds the session response
+func (uc *UploadSessionUseCase) sessionResponse(ctx context.Context, session *entity.UploadSession) (*dto.UploadSessionResponse, error) {
+	parts, err := uc.sessionRepo.GetParts(ctx, session.SessionID)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	return uc.toUploadSessionResponse(session, parts), nil
+}
+
+// toUploadSessionResponse converts entity to DTO
+func (uc *UploadSessionUseCase) toUploadSessionResponse(session *entity.UploadSession, parts []*entity.UploadPart) *dto.UploadSessionResponse {
+	received := make([]int, len(parts))
+	for i, part := range parts {
+		received[i] = part.PartNumber
+	}
+
+	return &dto.UploadSessionResponse{
+		UploadID:      session.SessionID.String(),
+		VideoID:       session.VideoID.String(),
+		ChunkSize:     session.ChunkSize,
+		TotalParts:    session.TotalParts(),
+		ReceivedParts: received,
+		Status:        session.Status,
+		ExpiresAt:     session.ExpiresAt,
+	}
+}
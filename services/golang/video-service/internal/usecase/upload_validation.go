@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/mediatype"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// quotaCharge is what an upload consumed from a daily quota, kept to refund it
+type quotaCharge struct {
+	userID uuid.UUID
+	day    time.Time
+	count  int
+	bytes  int64
+}
+
+// videoTooLarge returns ErrVideoTooLarge naming the size limit
+func (uc *VideoUseCase) videoTooLarge() error {
+	return ErrVideoTooLarge.WithViolation(RuleMaxFileSize,
+		fmt.Sprintf("videos may be at most %d bytes", uc.uploadConfig.MaxFileSize))
+}
+
+// sniffVideo returns the real content type of a video from its first bytes
+func sniffVideo(header []byte) (string, error) {
+	contentType, ok := mediatype.SniffVideo(header)
+	if !ok {
+		return "", ErrUnsupportedMedia.WithViolation(RuleVideoType, "videos must be MP4, QuickTime or WebM files")
+	}
+	return contentType, nil
+}
+
+// checkThumbnail validates an uploaded thumbnail and returns its real content
+// type, or "" when no thumbnail was sent
+func (uc *VideoUseCase) checkThumbnail(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
+	if int64(len(data)) > uc.uploadConfig.MaxThumbnailSize {
+		return "", ErrThumbnailTooLarge.WithViolation(RuleMaxThumbnailSize,
+			fmt.Sprintf("thumbnails may be at most %d bytes", uc.uploadConfig.MaxThumbnailSize))
+	}
+
+	contentType, ok := mediatype.SniffImage(mediatype.Header(data))
+	if !ok {
+		return "", ErrUnsupportedMedia.WithViolation(RuleThumbnailType, "thumbnails must be JPEG, PNG or WebP images")
+	}
+	return contentType, nil
+}
+
+// checkDuration validates the probed duration against the configured limits
+func (uc *VideoUseCase) checkDuration(info *entity.MediaInfo) error {
+	duration := time.Duration(info.Duration * float64(time.Second))
+
+	switch {
+	case uc.uploadConfig.MinDuration > 0 && duration < uc.uploadConfig.MinDuration:
+		return ErrInvalidDuration.WithViolation(RuleMinDuration,
+			fmt.Sprintf("video is %s long, the minimum is %s", duration.Round(time.Millisecond), uc.uploadConfig.MinDuration))
+	case uc.uploadConfig.MaxDuration > 0 && duration > uc.uploadConfig.MaxDuration:
+		return ErrInvalidDuration.WithViolation(RuleMaxDuration,
+			fmt.Sprintf("video is %s long, the maximum is %s", duration.Round(time.Millisecond), uc.uploadConfig.MaxDuration))
+	}
+	return nil
+}
+
+// isRejectedUpload checks if err rejects the uploaded file itself, as opposed
+// to a failure that may succeed on retry
+func isRejectedUpload(err error) bool {
+	return stderrors.Is(err, errors.ErrInvalidParam) ||
+		stderrors.Is(err, ErrUnsupportedMedia) ||
+		stderrors.Is(err, ErrInvalidDuration)
+}
+
+// chargeUploadQuota consumes count uploads and bytes from the daily quota of
+// userID, or returns ErrUploadQuotaExceeded naming the limit that was hit
+func (uc *VideoUseCase) chargeUploadQuota(ctx context.Context, userID uuid.UUID, count int, bytes int64) (*quotaCharge, error) {
+	charge := &quotaCharge{
+		userID: userID,
+		day:    time.Now().UTC().Truncate(24 * time.Hour),
+		count:  count,
+		bytes:  bytes,
+	}
+
+	usage, allowed, err := uc.quotaRepo.Consume(ctx, userID, charge.day, count, bytes,
+		uc.uploadConfig.DailyUploadCount, uc.uploadConfig.DailyUploadBytes)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to check upload quota", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	if allowed {
+		return charge, nil
+	}
+
+	logger.ForContext(ctx).Info("Upload quota exceeded",
+		zap.String("userID", userID.String()),
+		zap.Int("uploadCount", usage.UploadCount),
+		zap.Int64("uploadBytes", usage.UploadBytes))
+
+	if limit := uc.uploadConfig.DailyUploadCount; limit > 0 && usage.UploadCount+count > limit {
+		return nil, ErrUploadQuotaExceeded.WithViolation(RuleDailyUploadCount,
+			fmt.Sprintf("at most %d uploads per day, %d used", limit, usage.UploadCount))
+	}
+	return nil, ErrUploadQuotaExceeded.WithViolation(RuleDailyUploadBytes,
+		fmt.Sprintf("at most %d bytes per day, %d used", uc.uploadConfig.DailyUploadBytes, usage.UploadBytes))
+}
+
+// refundUploadQuota gives back a charge; a nil charge is ignored
+func (uc *VideoUseCase) refundUploadQuota(ctx context.Context, charge *quotaCharge) {
+	if charge == nil || (charge.count == 0 && charge.bytes == 0) {
+		return
+	}
+	if err := uc.quotaRepo.Refund(ctx, charge.userID, charge.day, charge.count, charge.bytes); err != nil {
+		logger.ForContext(ctx).Warn("Failed to refund upload quota", zap.Error(err))
+	}
+}
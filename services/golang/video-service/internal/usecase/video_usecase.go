@@ -1,12 +1,20 @@
 package usecase
 
 import (
+	"bufio"
 	"context"
+	"io"
+	"path"
+	"time"
 
 	"tiktok-clone/shared/common/errors"
 	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/config"
 	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/mediatype"
 	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/domain/storagekey"
 	"tiktok-clone/video-service/internal/usecase/dto"
 
 	"github.com/google/uuid"
@@ -16,32 +24,89 @@ import (
 // VideoUseCase handles video business logic
 type VideoUseCase struct {
 	videoRepo          repository.VideoRepository
+	contentRepo        repository.ContentObjectRepository
+	quotaRepo          repository.UploadQuotaRepository
+	hashtagRepo        repository.HashtagRepository
 	storageService     StorageService
 	transcodingService TranscodingService
+	mediaProber        MediaProber
+	urlSigner          PlaybackURLSigner
+	policy             *VideoPolicy
+	mentions           *MentionResolver
+	countCache         CountCache
+	counterStore       CounterStore
+	cursorCodec        *pagination.Codec
+	uploadConfig       config.UploadConfig
+	playbackConfig     config.PlaybackConfig
+	paginationConfig   config.PaginationConfig
 }
 
-// StorageService interface for file storage
+// StorageService interface for file storage.
+// Upload methods return the storage key of the stored object; URL turns a key
+// into the public URL handed to clients, PresignGetURL into an expiring one.
 type StorageService interface {
-	UploadVideo(ctx context.Context, videoID uuid.UUID, data []byte) (string, error)
-	UploadThumbnail(ctx context.Context, videoID uuid.UUID, data []byte) (string, error)
-	DeleteVideo(ctx context.Context, videoURL string) error
+	UploadVideo(ctx context.Context, videoID uuid.UUID, data []byte, contentType string) (string, error)
+	UploadVideoStream(ctx context.Context, videoID uuid.UUID, r io.Reader, contentType string) (string, error)
+	UploadThumbnail(ctx context.Context, videoID uuid.UUID, data []byte, contentType string) (string, error)
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+	URL(key string) string
+	CreateVideoUpload(ctx context.Context, videoID uuid.UUID) (string, error)
+	UploadVideoPart(ctx context.Context, videoID uuid.UUID, uploadID string, partNumber int, data []byte) (string, error)
+	CompleteVideoUpload(ctx context.Context, videoID uuid.UUID, uploadID string, parts []*entity.UploadPart) (string, error)
+	AbortVideoUpload(ctx context.Context, videoID uuid.UUID, uploadID string) error
+	PresignVideoUpload(ctx context.Context, videoID uuid.UUID, checksumSHA256 string, ttl time.Duration) (string, error)
+	PresignVideoPartUpload(ctx context.Context, videoID uuid.UUID, uploadID string, partNumber int, ttl time.Duration) (string, error)
+	PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	StatVideo(ctx context.Context, videoID uuid.UUID) (*entity.ObjectInfo, error)
 }
 
 // TranscodingService interface for video transcoding
 type TranscodingService interface {
-	StartTranscoding(ctx context.Context, videoID uuid.UUID, videoURL string) error
+	StartTranscoding(ctx context.Context, videoID uuid.UUID, videoKey string) error
 }
 
-// NewVideoUseCase creates a new video use case
+// NewVideoUseCase creates a new video use case.
+// urlSigner may be nil, in which case private videos get presigned storage URLs
+// and no playlist or storyboard.
 func NewVideoUseCase(
 	videoRepo repository.VideoRepository,
+	contentRepo repository.ContentObjectRepository,
+	quotaRepo repository.UploadQuotaRepository,
+	hashtagRepo repository.HashtagRepository,
 	storageService StorageService,
 	transcodingService TranscodingService,
+	mediaProber MediaProber,
+	urlSigner PlaybackURLSigner,
+	policy *VideoPolicy,
+	mentions *MentionResolver,
+	countCache CountCache,
+	counterStore CounterStore,
+	cursorCodec *pagination.Codec,
+	uploadConfig config.UploadConfig,
+	playbackConfig config.PlaybackConfig,
+	paginationConfig config.PaginationConfig,
 ) *VideoUseCase {
 	return &VideoUseCase{
 		videoRepo:          videoRepo,
+		contentRepo:        contentRepo,
+		quotaRepo:          quotaRepo,
+		hashtagRepo:        hashtagRepo,
 		storageService:     storageService,
 		transcodingService: transcodingService,
+		mediaProber:        mediaProber,
+		urlSigner:          urlSigner,
+		policy:             policy,
+		mentions:           mentions,
+		countCache:         countCache,
+		counterStore:       counterStore,
+		cursorCodec:        cursorCodec,
+		uploadConfig:       uploadConfig,
+		playbackConfig:     playbackConfig,
+		paginationConfig:   paginationConfig,
 	}
 }
 
@@ -50,89 +115,367 @@ func (uc *VideoUseCase) UploadVideo(ctx context.Context, req *dto.UploadVideoReq
 	log := logger.ForContext(ctx)
 	log.Info("Starting video upload", zap.String("userID", req.UserID.String()))
 
+	if int64(len(req.VideoData)) > uc.uploadConfig.MaxFileSize {
+		return nil, uc.videoTooLarge()
+	}
+	contentType, err := sniffVideo(mediatype.Header(req.VideoData))
+	if err != nil {
+		return nil, err
+	}
+	thumbnailType, err := uc.checkThumbnail(req.ThumbnailData)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTags(req.Tags); err != nil {
+		return nil, err
+	}
+
+	charge, err := uc.chargeUploadQuota(ctx, req.UserID, 1, int64(len(req.VideoData)))
+	if err != nil {
+		return nil, err
+	}
+
 	// Create video entity
 	video := &entity.Video{
-		VideoID:         uuid.New(),
-		UserID:          req.UserID,
-		Title:           req.Title,
-		Description:     req.Description,
-		DurationSeconds: req.DurationSeconds,
-		Width:           req.Width,
-		Height:          req.Height,
-		FileSize:        int64(len(req.VideoData)),
-		EncodingStatus:  "processing",
-		IsPublic:        true,
-		AllowComments:   true,
-		AllowDuet:       true,
-		AllowStitch:     true,
+		VideoID:        uuid.New(),
+		UserID:         req.UserID,
+		Region:         req.Region,
+		Title:          req.Title,
+		Description:    req.Description,
+		FileSize:       int64(len(req.VideoData)),
+		ContentType:    contentType,
+		EncodingStatus: entity.EncodingStatusUploaded,
+		Visibility:     entity.VisibilityPublic,
+		IsPublic:       true,
+		AllowComments:  true,
+		AllowDuet:      true,
+		AllowStitch:    true,
 	}
 
 	// Upload video to storage
-	videoURL, err := uc.storageService.UploadVideo(ctx, video.VideoID, req.VideoData)
+	videoKey, err := uc.storageService.UploadVideo(ctx, video.VideoID, req.VideoData, contentType)
 	if err != nil {
 		log.Error("Failed to upload video", zap.Error(err))
+		uc.refundUploadQuota(ctx, charge)
 		return nil, errors.ErrInternal
 	}
-	video.VideoURL = videoURL
+	video.VideoKey = videoKey
+	video.VideoURL = uc.storageService.URL(videoKey)
+
+	response, err := uc.completeUpload(ctx, video, req.Tags, req.ThumbnailData, thumbnailType)
+	if err != nil {
+		uc.refundUploadQuota(ctx, charge)
+		return nil, err
+	}
+	return response, nil
+}
+
+// UploadVideoStream handles a streamed video upload.
+// The data is piped straight into storage and never fully buffered in memory.
+func (uc *VideoUseCase) UploadVideoStream(ctx context.Context, req *dto.UploadVideoStreamRequest, data io.Reader) (*dto.VideoResponse, error) {
+	log := logger.ForContext(ctx)
+	log.Info("Starting streamed video upload",
+		zap.String("userID", req.UserID.String()),
+		zap.Int64("declaredSize", req.FileSize))
+
+	if req.FileSize > uc.uploadConfig.MaxFileSize {
+		return nil, uc.videoTooLarge()
+	}
+	thumbnailType, err := uc.checkThumbnail(req.ThumbnailData)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTags(req.Tags); err != nil {
+		return nil, err
+	}
+
+	// Sniff the first bytes without consuming them from the stream
+	buffered := bufio.NewReaderSize(data, mediatype.SniffLen)
+	header, err := buffered.Peek(mediatype.SniffLen)
+	if err != nil && err != io.EOF {
+		log.Warn("Failed to read video stream", zap.Error(err))
+		return nil, errors.ErrInvalidParam
+	}
+	if len(header) == 0 {
+		return nil, errors.ErrInvalidParam
+	}
+	contentType, err := sniffVideo(header)
+	if err != nil {
+		return nil, err
+	}
+
+	// The declared size is charged up front and corrected once the real size is known
+	charge, err := uc.chargeUploadQuota(ctx, req.UserID, 1, req.FileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	video := &entity.Video{
+		VideoID:        uuid.New(),
+		UserID:         req.UserID,
+		Region:         req.Region,
+		Title:          req.Title,
+		Description:    req.Description,
+		ContentType:    contentType,
+		EncodingStatus: entity.EncodingStatusUploaded,
+		Visibility:     entity.VisibilityPublic,
+		IsPublic:       true,
+		AllowComments:  true,
+		AllowDuet:      true,
+		AllowStitch:    true,
+	}
+
+	body := &limitedReader{r: buffered, limit: uc.uploadConfig.MaxFileSize}
+	videoKey, err := uc.storageService.UploadVideoStream(ctx, video.VideoID, body, contentType)
+	if err != nil {
+		uc.refundUploadQuota(ctx, charge)
+		if body.exceeded {
+			return nil, uc.videoTooLarge()
+		}
+		log.Error("Failed to upload video stream", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	video.VideoKey = videoKey
+	video.VideoURL = uc.storageService.URL(videoKey)
+	video.FileSize = body.read
+
+	if err := uc.settleStreamCharge(ctx, charge, video.FileSize); err != nil {
+		if err := uc.storageService.Delete(ctx, videoKey); err != nil {
+			log.Warn("Failed to delete video over quota from storage", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	response, err := uc.completeUpload(ctx, video, req.Tags, req.ThumbnailData, thumbnailType)
+	if err != nil {
+		uc.refundUploadQuota(ctx, charge)
+		return nil, err
+	}
+	return response, nil
+}
+
+// settleStreamCharge corrects the quota charged for the declared size of a
+// streamed upload to its real size. Over quota, the whole charge is refunded.
+func (uc *VideoUseCase) settleStreamCharge(ctx context.Context, charge *quotaCharge, size int64) error {
+	switch {
+	case size > charge.bytes:
+		if _, err := uc.chargeUploadQuota(ctx, charge.userID, 0, size-charge.bytes); err != nil {
+			uc.refundUploadQuota(ctx, charge)
+			return err
+		}
+	case size < charge.bytes:
+		uc.refundUploadQuota(ctx, &quotaCharge{userID: charge.userID, day: charge.day, bytes: charge.bytes - size})
+	}
+	charge.bytes = size
+	return nil
+}
+
+// completeUpload probes the uploaded file, stores the thumbnail, saves and
+// tags the video, resolves its mentions and starts transcoding once the video file is in storage.
+// tags are the explicit tags checked by checkTags, thumbnailType is the
+// content type returned by checkThumbnail.
+func (uc *VideoUseCase) completeUpload(ctx context.Context, video *entity.Video, tags []string, thumbnailData []byte, thumbnailType string) (*dto.VideoResponse, error) {
+	log := logger.ForContext(ctx)
+
+	if err := uc.probeUpload(ctx, video); err != nil {
+		return nil, err
+	}
 
-	// Upload thumbnail if provided
-	if len(req.ThumbnailData) > 0 {
-		thumbnailURL, err := uc.storageService.UploadThumbnail(ctx, video.VideoID, req.ThumbnailData)
+	// Upload thumbnail if provided; otherwise the transcoder generates a cover
+	if len(thumbnailData) > 0 {
+		thumbnailKey, err := uc.storageService.UploadThumbnail(ctx, video.VideoID, thumbnailData, thumbnailType)
 		if err != nil {
 			log.Warn("Failed to upload thumbnail", zap.Error(err))
 		} else {
-			video.ThumbnailURL = thumbnailURL
+			video.ThumbnailKey = thumbnailKey
+			video.ThumbnailURL = uc.storageService.URL(thumbnailKey)
 		}
 	}
 
 	// Save to database
 	if err := uc.videoRepo.Create(ctx, video); err != nil {
 		log.Error("Failed to save video", zap.Error(err))
+		uc.releaseContent(ctx, video.ContentSHA256)
 		return nil, errors.ErrInternal
 	}
+	uc.invalidateUserVideoTotals(ctx, video.UserID)
+	uc.tagVideo(ctx, video, tags)
+	uc.mentions.Update(ctx, video)
 
-	// Start transcoding asynchronously
-	go func() {
-		if err := uc.transcodingService.StartTranscoding(context.Background(), video.VideoID, videoURL); err != nil {
-			log.Error("Failed to start transcoding", zap.Error(err))
-		}
-	}()
+	uc.startTranscoding(ctx, video)
 
 	log.Info("Video uploaded successfully", zap.String("videoID", video.VideoID.String()))
-	return uc.toVideoResponse(video), nil
+	return uc.toVideoResponse(ctx, video), nil
+}
+
+// createPendingVideo saves a video whose file has not been uploaded yet and
+// tags it and resolves its mentions from its description
+func (uc *VideoUseCase) createPendingVideo(ctx context.Context, video *entity.Video) error {
+	video.EncodingStatus = entity.EncodingStatusPendingUpload
+	if err := uc.videoRepo.Create(ctx, video); err != nil {
+		return err
+	}
+	uc.tagVideo(ctx, video, nil)
+	uc.mentions.Update(ctx, video)
+	return nil
+}
+
+// activateUploadedVideo records the stored file of a pending video and starts transcoding
+func (uc *VideoUseCase) activateUploadedVideo(ctx context.Context, videoID uuid.UUID, file *entity.ObjectInfo) (*dto.VideoResponse, error) {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if !video.IsPendingUpload() {
+		return nil, ErrUploadSessionClosed
+	}
+
+	video.VideoKey = file.Key
+	video.VideoURL = file.URL
+	video.FileSize = file.Size
+
+	if err := uc.probeUpload(ctx, video); err != nil {
+		return nil, err
+	}
+
+	if err := uc.videoRepo.Update(ctx, video); err != nil {
+		logger.ForContext(ctx).Error("Failed to save uploaded video", zap.Error(err))
+		uc.releaseContent(ctx, video.ContentSHA256)
+		return nil, errors.ErrInternal
+	}
+	if err := uc.transitionVideo(ctx, video, entity.EncodingStatusUploaded, "upload confirmed"); err != nil {
+		if err == ErrInvalidStatusChange {
+			return nil, ErrUploadSessionClosed
+		}
+		return nil, err
+	}
+
+	uc.startTranscoding(ctx, video)
+
+	logger.ForContext(ctx).Info("Video upload confirmed", zap.String("videoID", video.VideoID.String()))
+	return uc.toVideoResponse(ctx, video), nil
+}
+
+// discardPendingVideo deletes a video whose file was never confirmed
+func (uc *VideoUseCase) discardPendingVideo(ctx context.Context, videoID uuid.UUID) error {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil
+	}
+	if !video.IsPendingUpload() {
+		return nil
+	}
+	return uc.videoRepo.Delete(ctx, videoID)
+}
+
+// startTranscoding queues the video for the transcoding workers.
+// A video that cannot be queued is marked failed rather than left queued.
+func (uc *VideoUseCase) startTranscoding(ctx context.Context, video *entity.Video) {
+	log := logger.ForContext(ctx).With(zap.String("videoID", video.VideoID.String()))
+
+	// Queued before the job exists, so a worker never sees an uploaded video
+	if err := uc.transitionVideo(ctx, video, entity.EncodingStatusQueued, "transcoding requested"); err != nil {
+		log.Error("Failed to mark video as queued", zap.Error(err))
+		return
+	}
+
+	if err := uc.transcodingService.StartTranscoding(ctx, video.VideoID, video.VideoKey); err != nil {
+		log.Error("Failed to queue transcoding", zap.Error(err))
+		if err := uc.transitionVideo(ctx, video, entity.EncodingStatusFailed, "transcoding could not be queued"); err != nil {
+			log.Error("Failed to mark video as failed", zap.Error(err))
+		}
+	}
 }
 
-// GetVideo retrieves a video by ID
-func (uc *VideoUseCase) GetVideo(ctx context.Context, videoID uuid.UUID) (*dto.VideoResponse, error) {
+// GetVideo retrieves a video by ID.
+// Videos the viewer may not watch are reported as not found.
+func (uc *VideoUseCase) GetVideo(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*dto.VideoResponse, error) {
 	video, err := uc.videoRepo.GetByID(ctx, videoID)
 	if err != nil {
 		return nil, errors.ErrNotFound
 	}
+	if err := uc.policy.AuthorizeView(ctx, viewer, video); err != nil {
+		return nil, err
+	}
+	uc.loadCounts(ctx, video)
+	uc.loadAnnotations(ctx, video)
 
-	return uc.toVideoResponse(video), nil
+	response := uc.toVideoResponse(ctx, video)
+	response.IsLiked = uc.isLiked(ctx, video.VideoID, viewer)
+	return response, nil
 }
 
-// GetUserVideos retrieves videos by user ID
-func (uc *VideoUseCase) GetUserVideos(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*dto.VideoResponse, error) {
-	videos, err := uc.videoRepo.GetByUserID(ctx, userID, limit, offset)
+// GetUserVideos retrieves one page of the videos of userID that viewer may
+// watch, newest first. cursor is the NextCursor of the previous page, "" for the
+// first, which also starts with the pinned videos on top of the limit.
+func (uc *VideoUseCase) GetUserVideos(ctx context.Context, userID uuid.UUID, viewer Viewer, cursor string, limit int) (*dto.VideoListResponse, error) {
+	limit = pagination.NormalizeLimit(limit)
+	scope := "user-videos:" + userID.String()
+
+	after, err := uc.cursorCodec.Decode(scope, cursor)
+	if err != nil {
+		return nil, errors.ErrInvalidParam
+	}
+
+	visibilities := uc.policy.VisibleLevels(ctx, viewer, userID)
+	statuses := uc.policy.VisibleStatuses(viewer, userID)
+	// One extra row tells whether another page follows
+	videos, err := uc.videoRepo.GetByUserID(ctx, userID, visibilities, statuses, after, limit+1)
 	if err != nil {
+		logger.ForContext(ctx).Error("Failed to list user videos", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	total, err := uc.userVideoTotal(ctx, userID, visibilities, statuses)
+	if err != nil {
+		logger.ForContext(ctx).Error("Failed to count user videos", zap.Error(err))
 		return nil, errors.ErrInternal
 	}
 
-	responses := make([]*dto.VideoResponse, len(videos))
-	for i, video := range videos {
-		responses[i] = uc.toVideoResponse(video)
+	if after == nil {
+		pinned, err := uc.videoRepo.GetPinnedByUserID(ctx, userID, visibilities, statuses)
+		if err != nil {
+			logger.ForContext(ctx).Error("Failed to list pinned videos", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+		videos = append(pinned, videos...)
+		limit += len(pinned)
 	}
 
-	return responses, nil
+	return uc.videoPage(ctx, videos, limit, total, func(last *entity.Video) string {
+		return uc.cursorCodec.Encode(scope, pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.VideoID.String()})
+	}), nil
 }
 
-// UpdateVideo updates video metadata
-func (uc *VideoUseCase) UpdateVideo(ctx context.Context, req *dto.UpdateVideoRequest) error {
+// videoPage builds a list page from up to limit+1 videos, the extra one
+// telling that another page follows. nextCursor encodes the position of the
+// last video of the page.
+func (uc *VideoUseCase) videoPage(ctx context.Context, videos []*entity.Video, limit int, total int64, nextCursor func(last *entity.Video) string) *dto.VideoListResponse {
+	response := &dto.VideoListResponse{
+		Videos: []*dto.VideoResponse{},
+		Total:  total,
+	}
+	if len(videos) > limit {
+		videos = videos[:limit]
+		response.HasMore = true
+		response.NextCursor = nextCursor(videos[limit-1])
+	}
+	uc.loadCounts(ctx, videos...)
+	uc.loadAnnotations(ctx, videos...)
+	for _, video := range videos {
+		response.Videos = append(response.Videos, uc.toVideoResponse(ctx, video))
+	}
+	return response
+}
+
+// UpdateVideo updates video metadata; only the owner and admins may
+func (uc *VideoUseCase) UpdateVideo(ctx context.Context, req *dto.UpdateVideoRequest, viewer Viewer) error {
 	video, err := uc.videoRepo.GetByID(ctx, req.VideoID)
 	if err != nil {
 		return errors.ErrNotFound
 	}
+	if err := uc.policy.AuthorizeEdit(ctx, viewer, video); err != nil {
+		return err
+	}
 
 	// Update fields if provided
 	if req.Title != nil {
@@ -141,8 +484,19 @@ func (uc *VideoUseCase) UpdateVideo(ctx context.Context, req *dto.UpdateVideoReq
 	if req.Description != nil {
 		video.Description = *req.Description
 	}
-	if req.IsPublic != nil {
-		video.IsPublic = *req.IsPublic
+	previousVisibility := video.Visibility
+	if req.Visibility != nil {
+		visibility, err := entity.ParseVisibility(*req.Visibility)
+		if err != nil {
+			return errors.ErrInvalidParam
+		}
+		video.SetVisibility(visibility)
+	} else if req.IsPublic != nil {
+		if *req.IsPublic {
+			video.SetVisibility(entity.VisibilityPublic)
+		} else {
+			video.SetVisibility(entity.VisibilityPrivate)
+		}
 	}
 	if req.AllowComments != nil {
 		video.AllowComments = *req.AllowComments
@@ -153,69 +507,218 @@ func (uc *VideoUseCase) UpdateVideo(ctx context.Context, req *dto.UpdateVideoReq
 	if req.AllowStitch != nil {
 		video.AllowStitch = *req.AllowStitch
 	}
+	if req.AllowDownload != nil {
+		video.AllowDownload = *req.AllowDownload
+	}
+	if req.Category != nil {
+		category, err := entity.ParseCategory(*req.Category)
+		if err != nil {
+			return errors.ErrInvalidParam
+		}
+		video.Category = category
+	}
 
-	return uc.videoRepo.Update(ctx, video)
+	// Hashtags follow the description; explicit tags are kept unless replaced
+	var tags []*entity.VideoTag
+	retag := req.Description != nil || req.Tags != nil
+	if retag {
+		var explicit []string
+		if req.Tags != nil {
+			if err := checkTags(*req.Tags); err != nil {
+				return err
+			}
+			explicit = *req.Tags
+		} else if explicit, err = uc.explicitTags(ctx, video.VideoID); err != nil {
+			logger.ForContext(ctx).Error("Failed to load video tags", zap.Error(err))
+			return errors.ErrInternal
+		}
+		tags, _ = entity.VideoTags(video.VideoID, video.Description, explicit)
+	}
+
+	if err := uc.videoRepo.UpdateDetails(ctx, video); err != nil {
+		return errors.ErrInternal
+	}
+	if retag {
+		if err := uc.hashtagRepo.SetVideoHashtags(ctx, video.VideoID, tags); err != nil {
+			logger.ForContext(ctx).Error("Failed to tag video", zap.Error(err))
+			return errors.ErrInternal
+		}
+	}
+	if req.Description != nil {
+		uc.mentions.Update(ctx, video)
+	}
+	if video.Visibility != previousVisibility {
+		uc.invalidateUserVideoTotals(ctx, video.UserID)
+	}
+	return nil
 }
 
-// DeleteVideo deletes a video
-func (uc *VideoUseCase) DeleteVideo(ctx context.Context, videoID, userID uuid.UUID) error {
+// DeleteVideo deletes a video; the owner, admins and moderators may
+func (uc *VideoUseCase) DeleteVideo(ctx context.Context, videoID uuid.UUID, viewer Viewer) error {
 	video, err := uc.videoRepo.GetByID(ctx, videoID)
 	if err != nil {
 		return errors.ErrNotFound
 	}
-
-	// Check ownership
-	if video.UserID != userID {
-		return errors.ErrForbidden
+	if err := uc.policy.AuthorizeDelete(ctx, viewer, video); err != nil {
+		return err
 	}
 
-	// Delete from storage
-	if err := uc.storageService.DeleteVideo(ctx, video.VideoURL); err != nil {
+	// Delete the stored files of the video itself: thumbnail, artwork, and for
+	// videos uploaded before deduplication the original and renditions
+	if err := uc.storageService.DeletePrefix(ctx, storagekey.VideoPrefix(videoID)); err != nil {
 		logger.ForContext(ctx).Warn("Failed to delete video from storage", zap.Error(err))
 	}
 
 	// Delete from database
-	return uc.videoRepo.Delete(ctx, videoID)
+	if err := uc.videoRepo.Delete(ctx, videoID); err != nil {
+		return err
+	}
+	uc.invalidateUserVideoTotals(ctx, video.UserID)
+
+	// Shared original and renditions go only with the last video using them
+	uc.releaseContent(ctx, video.ContentSHA256)
+	return nil
 }
 
-// GetTrendingVideos retrieves trending videos
-func (uc *VideoUseCase) GetTrendingVideos(ctx context.Context, limit int) ([]*dto.VideoResponse, error) {
-	videos, err := uc.videoRepo.GetTrending(ctx, limit)
+// UpdateEncodingStatus moves a video to a new encoding status.
+// It fails with ErrInvalidStatusChange if the transition table does not allow it.
+func (uc *VideoUseCase) UpdateEncodingStatus(ctx context.Context, videoID uuid.UUID, status entity.EncodingStatus, reason string) error {
+	video, err := uc.videoRepo.GetByID(ctx, videoID)
 	if err != nil {
-		return nil, errors.ErrInternal
+		return errors.ErrNotFound
 	}
 
-	responses := make([]*dto.VideoResponse, len(videos))
-	for i, video := range videos {
-		responses[i] = uc.toVideoResponse(video)
+	return uc.transitionVideo(ctx, video, status, reason)
+}
+
+// transitionVideo moves a loaded video to a new encoding status. The update
+// only applies if the stored status is still the one video was loaded with,
+// so a stale caller cannot overwrite a newer status.
+func (uc *VideoUseCase) transitionVideo(ctx context.Context, video *entity.Video, status entity.EncodingStatus, reason string) error {
+	from := video.EncodingStatus
+	if err := video.TransitionTo(status); err != nil {
+		logger.ForContext(ctx).Warn("Rejected encoding status change",
+			zap.String("videoID", video.VideoID.String()), zap.Error(err))
+		return ErrInvalidStatusChange
 	}
 
-	return responses, nil
+	updated, err := uc.videoRepo.UpdateEncodingStatus(ctx, video.VideoID, from, status, reason)
+	if err != nil {
+		return errors.ErrInternal
+	}
+	if !updated {
+		return ErrInvalidStatusChange
+	}
+	// Pending and removed videos are left out of user lists, and videos that
+	// are not ready out of the lists other users see
+	if from == entity.EncodingStatusPendingUpload || status == entity.EncodingStatusRemoved ||
+		from == entity.EncodingStatusReady || status == entity.EncodingStatusReady {
+		uc.invalidateUserVideoTotals(ctx, video.UserID)
+	}
+	return nil
 }
 
-// RecordView records a video view
-func (uc *VideoUseCase) RecordView(ctx context.Context, videoID uuid.UUID) error {
-	return uc.videoRepo.IncrementViewCount(ctx, videoID)
+// loadCounts replaces the stored view, like and share counts of videos with
+// the current ones from the counter store. The counts are decoration, so the
+// stored ones are kept if the store fails.
+func (uc *VideoUseCase) loadCounts(ctx context.Context, videos ...*entity.Video) {
+	if len(videos) == 0 {
+		return
+	}
+	stored := make(map[uuid.UUID]entity.VideoCounts, len(videos))
+	for _, video := range videos {
+		stored[video.VideoID] = video.Counts()
+	}
+
+	totals, err := uc.counterStore.Totals(ctx, stored)
+	if err != nil {
+		logger.ForContext(ctx).Warn("Failed to load counters", zap.Error(err))
+		return
+	}
+	for _, video := range videos {
+		if counts, ok := totals[video.VideoID]; ok {
+			video.SetCounts(counts)
+		}
+	}
 }
 
-// UpdateEncodingStatus updates video encoding status
-func (uc *VideoUseCase) UpdateEncodingStatus(ctx context.Context, videoID uuid.UUID, status string) error {
-	return uc.videoRepo.UpdateEncodingStatus(ctx, videoID, status)
+// loadAnnotations fills in the hashtags and mentions of the descriptions of videos
+func (uc *VideoUseCase) loadAnnotations(ctx context.Context, videos ...*entity.Video) {
+	uc.loadHashtags(ctx, videos...)
+	uc.mentions.Load(ctx, videos...)
 }
 
-// toVideoResponse converts entity to DTO
-func (uc *VideoUseCase) toVideoResponse(video *entity.Video) *dto.VideoResponse {
+// toVideoResponse converts entity to DTO, signing the URLs of private videos
+func (uc *VideoUseCase) toVideoResponse(ctx context.Context, video *entity.Video) *dto.VideoResponse {
+	urls := uc.urlsFor(ctx, video)
+
+	videoURL, thumbnailURL := video.VideoURL, video.ThumbnailURL
+	if video.VideoKey != "" {
+		videoURL = urls.object(video.VideoKey)
+	}
+	if video.ThumbnailKey != "" {
+		thumbnailURL = urls.object(video.ThumbnailKey)
+	}
+
+	var playlistURL string
+	if video.RenditionsPrefix != "" {
+		playlistURL = urls.tree(video.RenditionsPrefix, video.RenditionsPrefix+storagekey.MasterPlaylistFile)
+	}
+
+	var covers []dto.CoverImage
+	if video.CoverPrefix != "" {
+		for _, width := range storagekey.CoverWidths {
+			for _, format := range []string{storagekey.CoverFormatWebP, storagekey.CoverFormatJPEG} {
+				covers = append(covers, dto.CoverImage{
+					Width:  width,
+					Format: format,
+					URL:    urls.object(video.CoverPrefix + storagekey.CoverFile(width, format)),
+				})
+			}
+		}
+	}
+
+	var previewURL, storyboardURL string
+	if video.PreviewKey != "" {
+		previewURL = urls.object(video.PreviewKey)
+	}
+	if video.StoryboardKey != "" {
+		// The cues point at the sprite sheet next to the storyboard
+		storyboardURL = urls.tree(path.Dir(video.StoryboardKey)+"/", video.StoryboardKey)
+	}
+
+	mentions := make([]dto.MentionSpan, len(video.Mentions))
+	for i, mention := range video.Mentions {
+		mentions[i] = dto.MentionSpan{
+			UserID:   mention.UserID.String(),
+			Username: mention.Username,
+			Start:    mention.StartOffset,
+			End:      mention.EndOffset,
+		}
+	}
+
 	return &dto.VideoResponse{
 		VideoID:         video.VideoID.String(),
 		UserID:          video.UserID.String(),
 		Title:           video.Title,
 		Description:     video.Description,
-		VideoURL:        video.VideoURL,
-		ThumbnailURL:    video.ThumbnailURL,
+		VideoURL:        videoURL,
+		ThumbnailURL:    thumbnailURL,
+		PlaylistURL:     playlistURL,
+		Covers:          covers,
+		PreviewURL:      previewURL,
+		StoryboardURL:   storyboardURL,
+		URLsExpireAt:    urls.expiresAt(),
 		DurationSeconds: video.DurationSeconds,
 		Width:           video.Width,
 		Height:          video.Height,
-		EncodingStatus:  video.EncodingStatus,
+		EncodingStatus:  string(video.EncodingStatus),
+		Visibility:      string(video.Visibility),
+		IsPinned:        video.PinnedAt != nil,
+		Category:        string(video.Category),
+		Hashtags:        video.Hashtags,
+		Mentions:        mentions,
+		AllowDownload:   video.AllowDownload,
 		ViewCount:       video.ViewCount,
 		LikeCount:       video.LikeCount,
 		CommentCount:    video.CommentCount,
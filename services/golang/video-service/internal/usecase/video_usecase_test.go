@@ -0,0 +1,536 @@
+package usecase
+
+import (
+	"context"
+	stderrors "errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/pagination"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+)
+
+// fakeVideoRepository keeps videos and likes in memory. Methods the tests do
+// not reach are left to the embedded nil interface and panic.
+type fakeVideoRepository struct {
+	repository.VideoRepository
+	videos  map[uuid.UUID]*entity.Video
+	likes   map[[2]uuid.UUID]bool
+	updates int
+}
+
+func newFakeVideoRepository(videos ...*entity.Video) *fakeVideoRepository {
+	r := &fakeVideoRepository{videos: map[uuid.UUID]*entity.Video{}, likes: map[[2]uuid.UUID]bool{}}
+	for _, video := range videos {
+		stored := *video
+		r.videos[video.VideoID] = &stored
+	}
+	return r
+}
+
+func (r *fakeVideoRepository) GetByID(ctx context.Context, videoID uuid.UUID) (*entity.Video, error) {
+	video, ok := r.videos[videoID]
+	if !ok {
+		return nil, stderrors.New("record not found")
+	}
+	loaded := *video
+	return &loaded, nil
+}
+
+// listed returns the videos of userID with one of the visibility levels and
+// statuses, newest first
+func (r *fakeVideoRepository) listed(userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus, pinned bool) []*entity.Video {
+	var videos []*entity.Video
+	for _, video := range r.videos {
+		if video.UserID != userID || (video.PinnedAt != nil) != pinned {
+			continue
+		}
+		if !containsValue(visibilities, video.Visibility) || !containsValue(statuses, video.EncodingStatus) {
+			continue
+		}
+		loaded := *video
+		videos = append(videos, &loaded)
+	}
+	sort.Slice(videos, func(i, j int) bool {
+		if !videos[i].CreatedAt.Equal(videos[j].CreatedAt) {
+			return videos[i].CreatedAt.After(videos[j].CreatedAt)
+		}
+		return videos[i].VideoID.String() > videos[j].VideoID.String()
+	})
+	return videos
+}
+
+func (r *fakeVideoRepository) GetByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus, after *pagination.Cursor, limit int) ([]*entity.Video, error) {
+	var page []*entity.Video
+	for _, video := range r.listed(userID, visibilities, statuses, false) {
+		if after != nil && !video.CreatedAt.Before(after.CreatedAt) &&
+			!(video.CreatedAt.Equal(after.CreatedAt) && video.VideoID.String() < after.ID) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, video)
+	}
+	return page, nil
+}
+
+func (r *fakeVideoRepository) GetPinnedByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus) ([]*entity.Video, error) {
+	return r.listed(userID, visibilities, statuses, true), nil
+}
+
+func (r *fakeVideoRepository) CountByUserID(ctx context.Context, userID uuid.UUID, visibilities []entity.Visibility, statuses []entity.EncodingStatus) (int64, error) {
+	return int64(len(r.listed(userID, visibilities, statuses, false)) + len(r.listed(userID, visibilities, statuses, true))), nil
+}
+
+func (r *fakeVideoRepository) Pin(ctx context.Context, videoID, userID uuid.UUID, maxPinned int) (bool, error) {
+	video := r.videos[videoID]
+	if video.PinnedAt == nil {
+		now := time.Now()
+		video.PinnedAt = &now
+	}
+	return true, nil
+}
+
+func (r *fakeVideoRepository) UpdateDetails(ctx context.Context, video *entity.Video) error {
+	stored := *video
+	r.videos[video.VideoID] = &stored
+	r.updates++
+	return nil
+}
+
+func (r *fakeVideoRepository) Delete(ctx context.Context, videoID uuid.UUID) error {
+	delete(r.videos, videoID)
+	return nil
+}
+
+func (r *fakeVideoRepository) Like(ctx context.Context, videoID, userID uuid.UUID) (bool, error) {
+	key := [2]uuid.UUID{videoID, userID}
+	if r.likes[key] {
+		return false, nil
+	}
+	r.likes[key] = true
+	return true, nil
+}
+
+func (r *fakeVideoRepository) IsLiked(ctx context.Context, videoID, userID uuid.UUID) (bool, error) {
+	return r.likes[[2]uuid.UUID{videoID, userID}], nil
+}
+
+// containsValue checks if values holds value
+func containsValue[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeHashtagRepository has no hashtags
+type fakeHashtagRepository struct {
+	repository.HashtagRepository
+}
+
+func (r *fakeHashtagRepository) SetVideoHashtags(ctx context.Context, videoID uuid.UUID, tags []*entity.VideoTag) error {
+	return nil
+}
+
+func (r *fakeHashtagRepository) ListByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID][]*entity.VideoTag, error) {
+	return map[uuid.UUID][]*entity.VideoTag{}, nil
+}
+
+// fakeCountCache caches counts in memory, without expiry
+type fakeCountCache struct {
+	counts map[string]int64
+}
+
+func (c *fakeCountCache) GetCount(ctx context.Context, key string) (int64, bool, error) {
+	count, ok := c.counts[key]
+	return count, ok, nil
+}
+
+func (c *fakeCountCache) SetCount(ctx context.Context, key string, count int64, ttl time.Duration) error {
+	c.counts[key] = count
+	return nil
+}
+
+func (c *fakeCountCache) DeleteCounts(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(c.counts, key)
+	}
+	return nil
+}
+
+// fakeCounterStore adds the counted deltas to the stored counts
+type fakeCounterStore struct {
+	CounterStore
+	deltas map[uuid.UUID]entity.VideoCounts
+}
+
+func (s *fakeCounterStore) Increment(ctx context.Context, videoID uuid.UUID, delta entity.VideoCounts) error {
+	counts := s.deltas[videoID]
+	counts.Views += delta.Views
+	counts.Likes += delta.Likes
+	counts.Shares += delta.Shares
+	s.deltas[videoID] = counts
+	return nil
+}
+
+func (s *fakeCounterStore) Totals(ctx context.Context, stored map[uuid.UUID]entity.VideoCounts) (map[uuid.UUID]entity.VideoCounts, error) {
+	totals := make(map[uuid.UUID]entity.VideoCounts, len(stored))
+	for id, counts := range stored {
+		delta := s.deltas[id]
+		counts.Views += delta.Views
+		counts.Likes += delta.Likes
+		counts.Shares += delta.Shares
+		totals[id] = counts
+	}
+	return totals, nil
+}
+
+// fakeStorage records deleted prefixes and serves every key from one host
+type fakeStorage struct {
+	StorageService
+	deletedPrefixes []string
+}
+
+func (s *fakeStorage) URL(key string) string {
+	return "https://storage.test/" + key
+}
+
+func (s *fakeStorage) PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return "https://storage.test/" + key + "?signed", nil
+}
+
+func (s *fakeStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	s.deletedPrefixes = append(s.deletedPrefixes, prefix)
+	return nil
+}
+
+// videoFixture is a set of videos of one owner and the viewers of the tests
+type videoFixture struct {
+	owner, follower, stranger, anon, admin, moderator Viewer
+	videos                                            map[string]*entity.Video
+	follows                                           *fakeFollowChecker
+}
+
+// newVideoFixture returns the videos of one owner in every visibility level,
+// ready, plus a public one waiting for review and one not uploaded yet
+func newVideoFixture() *videoFixture {
+	ownerID, followerID := uuid.New(), uuid.New()
+	f := &videoFixture{
+		owner:     Viewer{UserID: ownerID},
+		follower:  Viewer{UserID: followerID},
+		stranger:  Viewer{UserID: uuid.New()},
+		admin:     Viewer{UserID: uuid.New(), Roles: []string{RoleAdmin}},
+		moderator: Viewer{UserID: uuid.New(), Roles: []string{RoleModerator}},
+		follows:   &fakeFollowChecker{follows: map[[2]uuid.UUID]bool{{followerID, ownerID}: true}},
+		videos:    map[string]*entity.Video{},
+	}
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, v := range []struct {
+		name       string
+		visibility entity.Visibility
+		status     entity.EncodingStatus
+	}{
+		{"public", entity.VisibilityPublic, entity.EncodingStatusReady},
+		{"followers", entity.VisibilityFollowers, entity.EncodingStatusReady},
+		{"private", entity.VisibilityPrivate, entity.EncodingStatusReady},
+		{"moderating", entity.VisibilityPublic, entity.EncodingStatusModerating},
+		{"pending", entity.VisibilityPublic, entity.EncodingStatusPendingUpload},
+	} {
+		created = created.Add(time.Minute)
+		f.videos[v.name] = &entity.Video{
+			VideoID:        uuid.New(),
+			UserID:         ownerID,
+			Title:          v.name,
+			Visibility:     v.visibility,
+			IsPublic:       v.visibility == entity.VisibilityPublic,
+			EncodingStatus: v.status,
+			CreatedAt:      created,
+		}
+	}
+	return f
+}
+
+// videoID returns the ID of a fixture video, or a random ID for "missing"
+func (f *videoFixture) videoID(name string) uuid.UUID {
+	if video, ok := f.videos[name]; ok {
+		return video.VideoID
+	}
+	return uuid.New()
+}
+
+// useCase returns a VideoUseCase over an in-memory repository holding the fixture videos
+func (f *videoFixture) useCase() (*VideoUseCase, *fakeVideoRepository, *fakeStorage) {
+	var videos []*entity.Video
+	for _, video := range f.videos {
+		videos = append(videos, video)
+	}
+	repo := newFakeVideoRepository(videos...)
+	storage := &fakeStorage{}
+
+	uc := NewVideoUseCase(
+		repo, nil, nil, &fakeHashtagRepository{},
+		storage, nil, nil, nil,
+		NewVideoPolicy(f.follows, config.ModerationConfig{}),
+		NewMentionResolver(newFakeMentionRepository(), nil, nil),
+		&fakeCountCache{counts: map[string]int64{}},
+		&fakeCounterStore{deltas: map[uuid.UUID]entity.VideoCounts{}},
+		pagination.NewCodec([]byte("secret")),
+		config.UploadConfig{},
+		config.PlaybackConfig{URLTTL: time.Hour},
+		config.PaginationConfig{TotalCacheTTL: time.Minute},
+	)
+	return uc, repo, storage
+}
+
+// accessCase is one viewer acting on one fixture video
+type accessCase struct {
+	name    string
+	viewer  Viewer
+	video   string
+	wantErr error
+}
+
+func TestVideoUseCaseGetVideo(t *testing.T) {
+	f := newVideoFixture()
+	tests := []accessCase{
+		{"stranger/public", f.stranger, "public", nil},
+		{"anonymous/public", f.anon, "public", nil},
+		{"stranger/followers", f.stranger, "followers", errors.ErrNotFound},
+		{"follower/followers", f.follower, "followers", nil},
+		{"stranger/private", f.stranger, "private", errors.ErrNotFound},
+		{"owner/private", f.owner, "private", nil},
+		{"moderator/private", f.moderator, "private", nil},
+		{"stranger/moderating", f.stranger, "moderating", errors.ErrNotFound},
+		{"anonymous/moderating", f.anon, "moderating", errors.ErrNotFound},
+		{"owner/moderating", f.owner, "moderating", nil},
+		{"moderator/moderating", f.moderator, "moderating", nil},
+		{"stranger/pending", f.stranger, "pending", errors.ErrNotFound},
+		{"owner/missing", f.owner, "missing", errors.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _, _ := f.useCase()
+			got, err := uc.GetVideo(testContext(), f.videoID(tt.video), tt.viewer)
+			if err != tt.wantErr {
+				t.Fatalf("GetVideo() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.VideoID != f.videoID(tt.video).String() {
+				t.Errorf("GetVideo() = video %s, want %s", got.VideoID, f.videoID(tt.video))
+			}
+		})
+	}
+}
+
+func TestVideoUseCaseUpdateVideo(t *testing.T) {
+	f := newVideoFixture()
+	tests := []accessCase{
+		{"owner/public", f.owner, "public", nil},
+		{"owner/moderating", f.owner, "moderating", nil},
+		{"admin/private", f.admin, "private", nil},
+		{"stranger/public", f.stranger, "public", errors.ErrForbidden},
+		{"follower/followers", f.follower, "followers", errors.ErrForbidden},
+		{"moderator/public", f.moderator, "public", errors.ErrForbidden},
+		{"stranger/private", f.stranger, "private", errors.ErrNotFound},
+		{"stranger/moderating", f.stranger, "moderating", errors.ErrNotFound},
+		{"owner/missing", f.owner, "missing", errors.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo, _ := f.useCase()
+			title := "renamed"
+			err := uc.UpdateVideo(testContext(), &dto.UpdateVideoRequest{VideoID: f.videoID(tt.video), Title: &title}, tt.viewer)
+			if err != tt.wantErr {
+				t.Fatalf("UpdateVideo() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				if got := repo.videos[f.videoID(tt.video)].Title; got != title {
+					t.Errorf("stored title = %q, want %q", got, title)
+				}
+			} else if repo.updates != 0 {
+				t.Errorf("video saved %d times, want none", repo.updates)
+			}
+		})
+	}
+}
+
+func TestVideoUseCaseDeleteVideo(t *testing.T) {
+	f := newVideoFixture()
+	tests := []accessCase{
+		{"owner/public", f.owner, "public", nil},
+		{"owner/pending", f.owner, "pending", nil},
+		{"admin/private", f.admin, "private", nil},
+		{"moderator/moderating", f.moderator, "moderating", nil},
+		{"stranger/public", f.stranger, "public", errors.ErrForbidden},
+		{"follower/followers", f.follower, "followers", errors.ErrForbidden},
+		{"stranger/private", f.stranger, "private", errors.ErrNotFound},
+		{"stranger/moderating", f.stranger, "moderating", errors.ErrNotFound},
+		{"owner/missing", f.owner, "missing", errors.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo, storage := f.useCase()
+			videoID := f.videoID(tt.video)
+			err := uc.DeleteVideo(testContext(), videoID, tt.viewer)
+			if err != tt.wantErr {
+				t.Fatalf("DeleteVideo() error = %v, want %v", err, tt.wantErr)
+			}
+			_, stored := repo.videos[videoID]
+			if tt.wantErr == nil && (stored || len(storage.deletedPrefixes) != 1) {
+				t.Errorf("video kept in the database or storage")
+			}
+			if tt.wantErr != nil && tt.video != "missing" && (!stored || len(storage.deletedPrefixes) != 0) {
+				t.Errorf("video deleted without permission")
+			}
+		})
+	}
+}
+
+func TestVideoUseCasePinVideo(t *testing.T) {
+	f := newVideoFixture()
+	tests := []accessCase{
+		{"owner/public", f.owner, "public", nil},
+		{"owner/moderating", f.owner, "moderating", nil},
+		{"admin/private", f.admin, "private", nil},
+		{"owner/pending", f.owner, "pending", ErrVideoNotPinnable},
+		{"stranger/public", f.stranger, "public", errors.ErrForbidden},
+		{"moderator/public", f.moderator, "public", errors.ErrForbidden},
+		{"stranger/private", f.stranger, "private", errors.ErrNotFound},
+		{"stranger/moderating", f.stranger, "moderating", errors.ErrNotFound},
+		{"owner/missing", f.owner, "missing", errors.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo, _ := f.useCase()
+			got, err := uc.PinVideo(testContext(), f.videoID(tt.video), tt.viewer)
+			if err != tt.wantErr {
+				t.Fatalf("PinVideo() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !got.IsPinned {
+				t.Errorf("PinVideo() returned an unpinned video")
+			}
+			if video, ok := repo.videos[f.videoID(tt.video)]; ok && (video.PinnedAt != nil) != (tt.wantErr == nil) {
+				t.Errorf("pinned = %v, want %v", video.PinnedAt != nil, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestVideoUseCaseLikeVideo(t *testing.T) {
+	f := newVideoFixture()
+	tests := []accessCase{
+		{"stranger/public", f.stranger, "public", nil},
+		{"follower/followers", f.follower, "followers", nil},
+		{"owner/private", f.owner, "private", nil},
+		{"anonymous/public", f.anon, "public", errors.ErrUnauthorized},
+		{"stranger/followers", f.stranger, "followers", errors.ErrNotFound},
+		{"stranger/private", f.stranger, "private", errors.ErrNotFound},
+		{"stranger/moderating", f.stranger, "moderating", errors.ErrNotFound},
+		{"owner/moderating", f.owner, "moderating", ErrVideoNotReady},
+		{"stranger/missing", f.stranger, "missing", errors.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo, _ := f.useCase()
+			got, err := uc.LikeVideo(testContext(), f.videoID(tt.video), tt.viewer)
+			if err != tt.wantErr {
+				t.Fatalf("LikeVideo() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (!got.IsLiked || got.LikeCount != 1) {
+				t.Errorf("LikeVideo() = %+v, want liked once", got)
+			}
+			if liked := len(repo.likes) == 1; liked != (tt.wantErr == nil) {
+				t.Errorf("like recorded = %v, want %v", liked, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestVideoUseCaseGetUserVideos(t *testing.T) {
+	f := newVideoFixture()
+	// Owner and staff see every listed video, the others only the ready ones they may watch
+	everything := []string{"moderating", "private", "followers", "public"}
+	tests := []struct {
+		name   string
+		viewer Viewer
+		want   []string
+	}{
+		{"owner", f.owner, everything},
+		{"admin", f.admin, everything},
+		{"moderator", f.moderator, everything},
+		{"follower", f.follower, []string{"followers", "public"}},
+		{"stranger", f.stranger, []string{"public"}},
+		{"anonymous", f.anon, []string{"public"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _, _ := f.useCase()
+			got, err := uc.GetUserVideos(testContext(), f.owner.UserID, tt.viewer, "", 10)
+			if err != nil {
+				t.Fatalf("GetUserVideos() error = %v", err)
+			}
+
+			var titles []string
+			for _, video := range got.Videos {
+				titles = append(titles, video.Title)
+			}
+			if !reflect.DeepEqual(titles, tt.want) {
+				t.Errorf("GetUserVideos() = %v, want %v", titles, tt.want)
+			}
+			if got.Total != int64(len(tt.want)) {
+				t.Errorf("Total = %d, want %d", got.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestVideoUseCaseGetUserVideosPages(t *testing.T) {
+	f := newVideoFixture()
+	uc, repo, _ := f.useCase()
+	// A pinned video heads the first page on top of the limit
+	now := time.Now()
+	repo.videos[f.videoID("private")].PinnedAt = &now
+
+	var titles []string
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		got, err := uc.GetUserVideos(testContext(), f.owner.UserID, f.owner, cursor, 1)
+		if err != nil {
+			t.Fatalf("GetUserVideos() error = %v", err)
+		}
+		for _, video := range got.Videos {
+			titles = append(titles, video.Title)
+		}
+		if !got.HasMore {
+			break
+		}
+		cursor = got.NextCursor
+	}
+
+	if want := []string{"private", "moderating", "followers", "public"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("pages = %v, want %v", titles, want)
+	}
+
+	// A cursor is bound to the list it was issued for
+	if _, err := uc.GetUserVideos(testContext(), f.stranger.UserID, f.owner, cursor, 1); err != errors.ErrInvalidParam {
+		t.Errorf("GetUserVideos() with the cursor of another user error = %v, want ErrInvalidParam", err)
+	}
+}
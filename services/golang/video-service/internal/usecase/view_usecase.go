@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"tiktok-clone/shared/common/errors"
+	"tiktok-clone/shared/common/logger"
+	"tiktok-clone/video-service/internal/config"
+	"tiktok-clone/video-service/internal/domain/entity"
+	"tiktok-clone/video-service/internal/domain/repository"
+	"tiktok-clone/video-service/internal/usecase/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxSessionIDLength is the length of the session_id column
+const maxSessionIDLength = 100
+
+// ViewDeduplicator interface for remembering who recently viewed a video
+type ViewDeduplicator interface {
+	// FirstView records that viewer viewed a video and reports whether they
+	// had not already within window
+	FirstView(ctx context.Context, videoID uuid.UUID, viewer string, window time.Duration) (bool, error)
+}
+
+// ViewUseCase records view events and counts the qualified ones
+type ViewUseCase struct {
+	videoRepo    repository.VideoRepository
+	viewRepo     repository.VideoViewRepository
+	deduplicator ViewDeduplicator
+	counterStore CounterStore
+	policy       *VideoPolicy
+	viewConfig   config.ViewConfig
+}
+
+// NewViewUseCase creates a new view use case
+func NewViewUseCase(
+	videoRepo repository.VideoRepository,
+	viewRepo repository.VideoViewRepository,
+	deduplicator ViewDeduplicator,
+	counterStore CounterStore,
+	policy *VideoPolicy,
+	viewConfig config.ViewConfig,
+) *ViewUseCase {
+	return &ViewUseCase{
+		videoRepo:    videoRepo,
+		viewRepo:     viewRepo,
+		deduplicator: deduplicator,
+		counterStore: counterStore,
+		policy:       policy,
+		viewConfig:   viewConfig,
+	}
+}
+
+// RecordView stores a view event and counts it if it qualifies: the video was
+// watched long enough, and the viewer has not viewed it within the dedup
+// window. It reports whether the view was counted.
+func (uc *ViewUseCase) RecordView(ctx context.Context, req *dto.RecordViewRequest, viewer Viewer) (bool, error) {
+	if req.WatchDuration < 0 || math.IsNaN(req.CompletionRate) || req.CompletionRate < 0 || len(req.SessionID) > maxSessionIDLength {
+		return false, errors.ErrInvalidParam
+	}
+
+	video, err := uc.videoRepo.GetByID(ctx, req.VideoID)
+	if err != nil {
+		return false, errors.ErrNotFound
+	}
+	if err := uc.policy.AuthorizeView(ctx, viewer, video); err != nil {
+		return false, err
+	}
+	if !video.IsReady() {
+		return false, ErrVideoNotReady
+	}
+
+	view := &entity.VideoView{
+		VideoID:              video.VideoID,
+		SessionID:            req.SessionID,
+		DeviceType:           entity.ParseDeviceType(req.DeviceType),
+		WatchDurationSeconds: int(req.WatchDuration.Round(time.Second) / time.Second),
+		// Replays are reported as more than 100 percent
+		CompletionRate: math.Min(req.CompletionRate, 100),
+		UserAgent:      req.UserAgent,
+	}
+	if !viewer.IsAnonymous() {
+		view.UserID = &viewer.UserID
+	}
+	if req.ClientIP != "" {
+		view.IPAddress = &req.ClientIP
+	}
+
+	if uc.qualifies(video, req) {
+		view.Counted = uc.firstView(ctx, video.VideoID, viewer, req)
+	}
+
+	if err := uc.viewRepo.Create(ctx, view); err != nil {
+		logger.ForContext(ctx).Error("Failed to record view", zap.Error(err))
+		return false, errors.ErrInternal
+	}
+	// The counted event is kept, so if counting fails the reconciliation
+	// corrects the count
+	if view.Counted {
+		if err := uc.counterStore.Increment(ctx, video.VideoID, entity.VideoCounts{Views: 1}); err != nil {
+			logger.ForContext(ctx).Warn("Failed to count view", zap.Error(err))
+		}
+	}
+
+	return view.Counted, nil
+}
+
+// qualifies checks if a view was long enough to count. Videos shorter than
+// the minimum watch duration must be watched to the end.
+func (uc *ViewUseCase) qualifies(video *entity.Video, req *dto.RecordViewRequest) bool {
+	if req.WatchDuration >= uc.viewConfig.MinWatchDuration {
+		return true
+	}
+	videoLength := time.Duration(video.DurationSeconds) * time.Second
+	return videoLength < uc.viewConfig.MinWatchDuration &&
+		(req.CompletionRate >= 100 || (videoLength > 0 && req.WatchDuration >= videoLength))
+}
+
+// firstView checks if viewer has not viewed the video within the dedup window.
+// Signed-in viewers are told apart by user ID, anonymous ones by IP address,
+// else by player session; views of callers without either are not counted.
+// If the deduplicator fails the view is counted, rather than losing views
+// while Redis is down.
+func (uc *ViewUseCase) firstView(ctx context.Context, videoID uuid.UUID, viewer Viewer, req *dto.RecordViewRequest) bool {
+	var key string
+	switch {
+	case !viewer.IsAnonymous():
+		key = "user:" + viewer.UserID.String()
+	case req.ClientIP != "":
+		key = "ip:" + req.ClientIP
+	case req.SessionID != "":
+		key = "session:" + req.SessionID
+	default:
+		return false
+	}
+
+	first, err := uc.deduplicator.FirstView(ctx, videoID, key, uc.viewConfig.DedupWindow)
+	if err != nil {
+		logger.ForContext(ctx).Warn("Failed to deduplicate view, counting it", zap.Error(err))
+		return true
+	}
+	return first
+}
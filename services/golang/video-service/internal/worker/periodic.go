@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"tiktok-clone/shared/common/logger"
+
+	"go.uber.org/zap"
+)
+
+// RunPeriodic calls fn every interval until ctx is cancelled.
+// Errors are logged and the job keeps running on the next tick.
+func RunPeriodic(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	log := logger.ForContext(ctx).With(zap.String("job", name))
+	log.Info("Periodic job started", zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Periodic job stopped")
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Error("Periodic job failed", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"tiktok-clone/shared/common/logger"
+
+	"go.uber.org/zap"
+)
+
+// RunPool starts n workers that call fn in a loop until ctx is cancelled and
+// blocks until all of them have returned. fn reports whether it found work;
+// a worker that found none waits idle before polling again.
+// Every worker gets an ID unique across processes, e.g. for job leases.
+func RunPool(ctx context.Context, name string, n int, idle time.Duration, fn func(ctx context.Context, workerID string) (bool, error)) {
+	log := logger.ForContext(ctx).With(zap.String("job", name))
+	log.Info("Worker pool started", zap.Int("workers", n))
+
+	hostname, _ := os.Hostname()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		workerID := fmt.Sprintf("%s-%d-%s-%d", hostname, os.Getpid(), name, i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				found, err := fn(ctx, workerID)
+				if err != nil && ctx.Err() == nil {
+					log.Error("Worker failed", zap.String("workerID", workerID), zap.Error(err))
+				}
+				if found && err == nil {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(idle):
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	log.Info("Worker pool stopped")
+}
@@ -0,0 +1,31 @@
+-- Resumable upload sessions
+CREATE TABLE upload_sessions (
+    session_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    video_id UUID NOT NULL,
+    storage_upload_id VARCHAR(1024) NOT NULL,
+    title VARCHAR(255),
+    description TEXT,
+    duration_seconds INTEGER,
+    width INTEGER,
+    height INTEGER,
+    total_size BIGINT NOT NULL,
+    chunk_size BIGINT NOT NULL,
+    status VARCHAR(20) DEFAULT 'active', -- active, completing, completed, expired
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_upload_sessions_user_id ON upload_sessions(user_id);
+CREATE INDEX idx_upload_sessions_status_expires_at ON upload_sessions(status, expires_at);
+
+-- Parts received for an upload session
+CREATE TABLE upload_session_parts (
+    session_id UUID NOT NULL REFERENCES upload_sessions(session_id) ON DELETE CASCADE,
+    part_number INTEGER NOT NULL,
+    etag VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, part_number)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_upload_sessions_video_id;
+
+ALTER TABLE upload_sessions DROP COLUMN IF EXISTS checksum_sha256;
+ALTER TABLE upload_sessions DROP COLUMN IF EXISTS mode;
+UPDATE upload_sessions SET storage_upload_id = '' WHERE storage_upload_id IS NULL;
+ALTER TABLE upload_sessions ALTER COLUMN storage_upload_id SET NOT NULL;
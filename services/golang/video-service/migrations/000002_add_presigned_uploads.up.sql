@@ -0,0 +1,6 @@
+-- Presigned (direct-to-storage) uploads reuse upload sessions
+ALTER TABLE upload_sessions ALTER COLUMN storage_upload_id DROP NOT NULL;
+ALTER TABLE upload_sessions ADD COLUMN mode VARCHAR(20) DEFAULT 'chunked'; -- chunked, presigned
+ALTER TABLE upload_sessions ADD COLUMN checksum_sha256 VARCHAR(64);
+
+CREATE INDEX idx_upload_sessions_video_id ON upload_sessions(video_id);
@@ -0,0 +1,3 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS renditions_prefix;
+ALTER TABLE videos DROP COLUMN IF EXISTS thumbnail_key;
+ALTER TABLE videos DROP COLUMN IF EXISTS video_key;
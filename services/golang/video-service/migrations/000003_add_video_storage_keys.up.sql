@@ -0,0 +1,14 @@
+-- Storage keys are persisted separately from the public URLs they are served under
+ALTER TABLE videos ADD COLUMN video_key VARCHAR(500);
+ALTER TABLE videos ADD COLUMN thumbnail_key VARCHAR(500);
+ALTER TABLE videos ADD COLUMN renditions_prefix VARCHAR(500);
+
+-- One-off backfill: every object key starts with videos/<id>/, whatever host
+-- or bucket style the URL was built with
+UPDATE videos
+SET video_key = substring(video_url from '(videos/[^?#]+)')
+WHERE video_key IS NULL AND video_url <> '';
+
+UPDATE videos
+SET thumbnail_key = substring(thumbnail_url from '(videos/[^?#]+)')
+WHERE thumbnail_key IS NULL AND thumbnail_url <> '';
@@ -0,0 +1,22 @@
+-- Durable transcoding jobs, claimed by workers with a renewable lease
+CREATE TABLE transcoding_jobs (
+    job_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    video_id UUID NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    status VARCHAR(20) DEFAULT 'pending', -- pending, running, succeeded, dead
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    last_error TEXT,
+    run_after TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lease_owner VARCHAR(255),
+    lease_expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transcoding_jobs_video_id ON transcoding_jobs(video_id, created_at DESC);
+CREATE INDEX idx_transcoding_jobs_pending ON transcoding_jobs(run_after) WHERE status = 'pending';
+CREATE INDEX idx_transcoding_jobs_lease ON transcoding_jobs(lease_expires_at) WHERE status = 'running';
+
+-- Videos left in processing by the old in-process queue get a job
+INSERT INTO transcoding_jobs (video_id, max_attempts)
+SELECT video_id, 5 FROM videos WHERE encoding_status = 'processing';
@@ -0,0 +1,12 @@
+-- Lossy: the status history is dropped, and the statuses the pre-lifecycle
+-- code does not know are folded into processing, completed and failed.
+-- pending_upload predates the lifecycle and is kept.
+ALTER TABLE videos ALTER COLUMN encoding_status SET DEFAULT 'processing';
+
+UPDATE videos SET encoding_status = 'completed' WHERE encoding_status = 'ready';
+UPDATE videos SET encoding_status = 'processing'
+WHERE encoding_status IN ('uploaded', 'queued', 'transcoding', 'moderating');
+-- Taken-down videos become private failures so they stay out of public lists
+UPDATE videos SET encoding_status = 'failed', is_public = FALSE WHERE encoding_status = 'removed';
+
+DROP TABLE IF EXISTS video_status_history;
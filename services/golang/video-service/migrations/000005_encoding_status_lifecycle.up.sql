@@ -0,0 +1,18 @@
+-- Encoding status transitions, kept for debugging (rows outlive deleted videos)
+CREATE TABLE video_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    video_id UUID NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_video_status_history_video_id ON video_status_history(video_id, created_at);
+
+-- Map the old statuses onto the lifecycle:
+-- pending_upload, uploaded, queued, transcoding, moderating, ready, failed, removed
+UPDATE videos SET encoding_status = 'queued' WHERE encoding_status = 'processing';
+UPDATE videos SET encoding_status = 'ready' WHERE encoding_status = 'completed';
+
+ALTER TABLE videos ALTER COLUMN encoding_status SET DEFAULT 'uploaded';
@@ -0,0 +1,6 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS bitrate;
+ALTER TABLE videos DROP COLUMN IF EXISTS frame_rate;
+ALTER TABLE videos DROP COLUMN IF EXISTS rotation;
+ALTER TABLE videos DROP COLUMN IF EXISTS audio_codec;
+ALTER TABLE videos DROP COLUMN IF EXISTS video_codec;
+ALTER TABLE videos DROP COLUMN IF EXISTS container;
@@ -0,0 +1,7 @@
+-- Properties measured from the uploaded file by the media prober
+ALTER TABLE videos ADD COLUMN container VARCHAR(20);
+ALTER TABLE videos ADD COLUMN video_codec VARCHAR(20);
+ALTER TABLE videos ADD COLUMN audio_codec VARCHAR(20);
+ALTER TABLE videos ADD COLUMN rotation INTEGER DEFAULT 0;
+ALTER TABLE videos ADD COLUMN frame_rate NUMERIC(6,2);
+ALTER TABLE videos ADD COLUMN bitrate BIGINT;
@@ -0,0 +1,7 @@
+ALTER TABLE transcoding_jobs DROP COLUMN IF EXISTS cover_at_ms;
+ALTER TABLE transcoding_jobs DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS storyboard_key;
+ALTER TABLE videos DROP COLUMN IF EXISTS preview_key;
+ALTER TABLE videos DROP COLUMN IF EXISTS cover_at_ms;
+ALTER TABLE videos DROP COLUMN IF EXISTS cover_prefix;
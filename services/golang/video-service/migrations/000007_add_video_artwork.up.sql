@@ -0,0 +1,9 @@
+-- Images generated from the video: cover sizes, animated preview, scrub sprite sheet
+ALTER TABLE videos ADD COLUMN cover_prefix VARCHAR(500);
+ALTER TABLE videos ADD COLUMN cover_at_ms BIGINT;
+ALTER TABLE videos ADD COLUMN preview_key VARCHAR(500);
+ALTER TABLE videos ADD COLUMN storyboard_key VARCHAR(500);
+
+-- Jobs that only re-render the cover share the transcoding queue
+ALTER TABLE transcoding_jobs ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'transcode';
+ALTER TABLE transcoding_jobs ADD COLUMN cover_at_ms BIGINT;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_videos_fingerprinted;
+DROP INDEX IF EXISTS idx_videos_content_sha256;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS fingerprint;
+ALTER TABLE videos DROP COLUMN IF EXISTS content_sha256;
+
+DROP TABLE IF EXISTS content_objects;
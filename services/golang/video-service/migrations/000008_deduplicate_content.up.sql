@@ -0,0 +1,16 @@
+-- Originals and renditions shared by every video uploaded with the same file
+CREATE TABLE content_objects (
+    content_sha256 CHAR(64) PRIMARY KEY,
+    original_key VARCHAR(500) NOT NULL,
+    renditions_prefix VARCHAR(500),
+    ref_count INTEGER NOT NULL DEFAULT 0, -- number of videos using the content
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing videos keep their own objects under videos/<id>/ and are not deduplicated
+ALTER TABLE videos ADD COLUMN content_sha256 CHAR(64);
+ALTER TABLE videos ADD COLUMN fingerprint VARCHAR(512) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_videos_content_sha256 ON videos(content_sha256);
+CREATE INDEX idx_videos_fingerprinted ON videos(duration_seconds) WHERE fingerprint <> '';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS upload_quotas;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS content_type;
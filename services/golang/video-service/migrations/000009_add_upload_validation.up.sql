@@ -0,0 +1,12 @@
+-- Content type sniffed from the leading bytes of the uploaded file
+ALTER TABLE videos ADD COLUMN content_type VARCHAR(50);
+
+-- Per-user upload usage per UTC day, checked against the daily quota
+CREATE TABLE upload_quotas (
+    user_id UUID NOT NULL,
+    day DATE NOT NULL,
+    upload_count INTEGER NOT NULL DEFAULT 0,
+    upload_bytes BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, day)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_videos_user_visibility;
+
+-- Followers-only videos fall back to private
+UPDATE videos SET is_public = FALSE WHERE visibility <> 'public';
+
+ALTER TABLE videos DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,7 @@
+-- Who may watch a video: public, followers (of the owner) or private.
+-- is_public stays in sync (visibility = 'public') for the listing queries.
+ALTER TABLE videos ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public';
+
+UPDATE videos SET visibility = 'private' WHERE is_public = FALSE;
+
+CREATE INDEX idx_videos_user_visibility ON videos(user_id, visibility);
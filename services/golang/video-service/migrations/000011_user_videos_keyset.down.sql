@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_videos_user_created;
@@ -0,0 +1,2 @@
+-- Keyset pagination of user video lists over (created_at, video_id)
+CREATE INDEX idx_videos_user_created ON videos(user_id, created_at DESC, video_id DESC);
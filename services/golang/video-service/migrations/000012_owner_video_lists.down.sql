@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_videos_user_likes;
+DROP INDEX IF EXISTS idx_videos_user_views;
+DROP INDEX IF EXISTS idx_videos_user_pinned;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS pinned_at;
@@ -0,0 +1,8 @@
+-- Videos pinned to the top of the owner's grid, at most 3 per user
+ALTER TABLE videos ADD COLUMN pinned_at TIMESTAMP;
+
+CREATE INDEX idx_videos_user_pinned ON videos(user_id, pinned_at DESC) WHERE pinned_at IS NOT NULL;
+
+-- Keyset pagination of the owner's own list by views and likes
+CREATE INDEX idx_videos_user_views ON videos(user_id, view_count DESC, video_id DESC);
+CREATE INDEX idx_videos_user_likes ON videos(user_id, like_count DESC, video_id DESC);
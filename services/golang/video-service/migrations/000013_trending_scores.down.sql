@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_videos_trending_candidates;
+DROP TABLE IF EXISTS trending_scores;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS region;
@@ -0,0 +1,40 @@
+-- Country a video was uploaded from, for regional trending lists
+ALTER TABLE videos ADD COLUMN region VARCHAR(2) NOT NULL DEFAULT '';
+
+-- Raw view events; the trending job reads the completion rate from them
+CREATE TABLE IF NOT EXISTS video_views (
+    view_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    video_id UUID NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    user_id UUID,
+    watch_duration_seconds INTEGER,
+    completion_rate DECIMAL(5,2), -- percent of the video watched
+    ip_address INET,
+    user_agent TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_views_video ON video_views(video_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_video_views_user ON video_views(user_id);
+CREATE INDEX IF NOT EXISTS idx_video_views_created_at ON video_views(created_at DESC);
+
+-- Snapshots of the trending score of recent videos, one row per video and run.
+-- The counts of the previous snapshot give the view and engagement velocity.
+CREATE TABLE trending_scores (
+    video_id UUID NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    computed_at TIMESTAMP NOT NULL,
+    region VARCHAR(2) NOT NULL DEFAULT '',
+    view_count BIGINT NOT NULL DEFAULT 0,
+    like_count BIGINT NOT NULL DEFAULT 0,
+    comment_count BIGINT NOT NULL DEFAULT 0,
+    share_count BIGINT NOT NULL DEFAULT 0,
+    view_velocity DOUBLE PRECISION NOT NULL DEFAULT 0,   -- views per hour, smoothed
+    engagement_rate DOUBLE PRECISION NOT NULL DEFAULT 0, -- weighted likes, comments and shares per hour, smoothed
+    completion_rate DOUBLE PRECISION NOT NULL DEFAULT 0, -- mean fraction watched, 0-1
+    trending_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (video_id, computed_at)
+);
+
+CREATE INDEX idx_trending_scores_run ON trending_scores(computed_at DESC, region, trending_score DESC);
+
+-- Candidates of the trending job
+CREATE INDEX idx_videos_trending_candidates ON videos(created_at DESC) WHERE is_public = TRUE AND encoding_status = 'ready';
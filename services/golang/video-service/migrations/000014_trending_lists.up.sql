@@ -0,0 +1,2 @@
+-- Category a creator files a video under; trending lists are also kept per category
+ALTER TABLE videos ADD COLUMN category VARCHAR(20) NOT NULL DEFAULT '';
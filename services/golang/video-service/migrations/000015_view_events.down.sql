@@ -0,0 +1,3 @@
+ALTER TABLE video_views DROP COLUMN IF EXISTS counted;
+ALTER TABLE video_views DROP COLUMN IF EXISTS device_type;
+ALTER TABLE video_views DROP COLUMN IF EXISTS session_id;
@@ -0,0 +1,5 @@
+-- Details players report with every view, and whether it was counted: views
+-- shorter than the minimum watch time and repeated views are kept uncounted
+ALTER TABLE video_views ADD COLUMN session_id VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE video_views ADD COLUMN device_type VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE video_views ADD COLUMN counted BOOLEAN NOT NULL DEFAULT FALSE;
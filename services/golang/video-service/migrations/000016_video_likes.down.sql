@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_video_likes_user_created;
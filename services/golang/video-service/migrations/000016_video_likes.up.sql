@@ -0,0 +1,12 @@
+-- One row per user and liked video; videos.like_count is updated in the same transaction
+CREATE TABLE IF NOT EXISTS video_likes (
+    like_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    video_id UUID NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, video_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_likes_video ON video_likes(video_id);
+-- Keyset pagination of the liked videos tab
+CREATE INDEX IF NOT EXISTS idx_video_likes_user_created ON video_likes(user_id, created_at DESC, video_id DESC);
@@ -0,0 +1,8 @@
+-- Batches of Redis counter deltas already added to videos; a batch that is
+-- flushed again after a crash is skipped
+CREATE TABLE counter_flush_batches (
+    batch_id VARCHAR(64) PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_counter_flush_batches_applied ON counter_flush_batches(applied_at);
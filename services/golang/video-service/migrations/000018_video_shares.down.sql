@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS share_links;
+ALTER TABLE videos DROP COLUMN IF EXISTS download_key;
+ALTER TABLE videos DROP COLUMN IF EXISTS allow_download;
@@ -0,0 +1,18 @@
+-- Whether shares may include a watermarked copy of the video, and where that
+-- copy is stored once rendered
+ALTER TABLE videos ADD COLUMN allow_download BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE videos ADD COLUMN download_key VARCHAR(500) NOT NULL DEFAULT '';
+
+-- One row per share, behind the short link handed to the sharer;
+-- videos.share_count counts them
+CREATE TABLE IF NOT EXISTS share_links (
+    code VARCHAR(16) PRIMARY KEY,
+    video_id UUID NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    user_id UUID,
+    channel VARCHAR(20) NOT NULL,
+    download BOOLEAN NOT NULL DEFAULT FALSE,
+    click_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_video ON share_links(video_id);
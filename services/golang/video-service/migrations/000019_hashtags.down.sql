@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_video_hashtags_hashtag_video;
+ALTER TABLE video_hashtags DROP COLUMN IF EXISTS explicit;
+ALTER TABLE video_hashtags DROP COLUMN IF EXISTS position;
+DROP INDEX IF EXISTS idx_hashtags_name_prefix;
@@ -0,0 +1,54 @@
+-- Hashtags of video descriptions and explicit tags, stored lowercased in NFC;
+-- usage_count counts the videos tagged with each. The tables are part of the
+-- baseline schema, so they are only created on databases without it.
+CREATE TABLE IF NOT EXISTS hashtags (
+    hashtag_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    usage_count BIGINT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE hashtags ADD COLUMN IF NOT EXISTS usage_count BIGINT DEFAULT 0;
+
+-- Prefix search of hashtag suggestions
+CREATE INDEX IF NOT EXISTS idx_hashtags_name_prefix ON hashtags(name text_pattern_ops);
+
+CREATE TABLE IF NOT EXISTS video_hashtags (
+    video_id UUID NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    hashtag_id UUID NOT NULL REFERENCES hashtags(hashtag_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (video_id, hashtag_id)
+);
+
+-- position keeps the order hashtags appear in, description ones first
+ALTER TABLE video_hashtags ADD COLUMN IF NOT EXISTS position SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE video_hashtags ADD COLUMN IF NOT EXISTS explicit BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Hashtag pages join from the hashtag to its videos
+CREATE INDEX IF NOT EXISTS idx_video_hashtags_hashtag_video ON video_hashtags(hashtag_id, video_id);
+
+-- Tag existing videos from their descriptions
+CREATE TEMPORARY TABLE description_hashtags AS
+SELECT DISTINCT ON (v.video_id, lower(m.match[1]))
+    v.video_id, lower(m.match[1]) AS name, m.position
+FROM videos v
+CROSS JOIN LATERAL regexp_matches(normalize(v.description, NFC), '(?<![[:alnum:]_])#([[:alnum:]_]+)', 'g')
+    WITH ORDINALITY AS m(match, position)
+WHERE v.description IS NOT NULL AND char_length(m.match[1]) <= 100
+ORDER BY v.video_id, lower(m.match[1]), m.position;
+
+INSERT INTO hashtags (name)
+SELECT DISTINCT name FROM description_hashtags
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO video_hashtags (video_id, hashtag_id, position)
+SELECT d.video_id, h.hashtag_id, d.position - 1
+FROM description_hashtags d
+JOIN hashtags h ON h.name = d.name
+ON CONFLICT DO NOTHING;
+
+UPDATE hashtags h SET usage_count = (
+    SELECT COUNT(*) FROM video_hashtags vh WHERE vh.hashtag_id = h.hashtag_id
+);
+
+DROP TABLE description_hashtags;
@@ -0,0 +1,14 @@
+-- One row per @username in a description that names an existing user;
+-- offsets are in characters and cover the '@'
+CREATE TABLE IF NOT EXISTS video_mentions (
+    video_id UUID NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    username VARCHAR(50) NOT NULL,
+    start_offset INT NOT NULL,
+    end_offset INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (video_id, start_offset)
+);
+
+-- Videos mentioning a user
+CREATE INDEX IF NOT EXISTS idx_video_mentions_user ON video_mentions(user_id, created_at DESC);
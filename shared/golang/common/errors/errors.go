@@ -4,16 +4,25 @@ import (
 	"fmt"
 	"net/http"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 )
 
 // AppError là cấu trúc lỗi chuẩn trong ứng dụng
 type AppError struct {
-	Code       int        // Mã lỗi nội bộ (tùy chỉnh)
-	Message    string     // Thông báo lỗi cho Developer
-	HTTPStatus int        // HTTP Status Code cho API Gateway
-	GRPCCode   codes.Code // gRPC Status Code cho giao tiếp nội bộ
+	Code       int         // Mã lỗi nội bộ (tùy chỉnh)
+	Message    string      // Thông báo lỗi cho Developer
+	HTTPStatus int         // HTTP Status Code cho API Gateway
+	GRPCCode   codes.Code  // gRPC Status Code cho giao tiếp nội bộ
+	Violations []Violation // Các quy tắc bị vi phạm, gửi kèm trong gRPC error details
+}
+
+// Violation mô tả một quy tắc bị vi phạm, ví dụ giới hạn dung lượng hoặc hạn mức
+type Violation struct {
+	Rule        string // Tên quy tắc, ví dụ "max_file_size"
+	Description string // Mô tả cho client
 }
 
 // Implement Error interface
@@ -21,6 +30,20 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("Code: %d, Message: %s", e.Code, e.Message)
 }
 
+// Is cho phép errors.Is so sánh theo mã lỗi, kể cả với bản sao có Violations
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// WithViolation trả về bản sao của lỗi kèm thêm một quy tắc bị vi phạm.
+// Lỗi gốc (biến toàn cục) không bị thay đổi.
+func (e *AppError) WithViolation(rule, description string) *AppError {
+	clone := *e
+	clone.Violations = append(append([]Violation{}, e.Violations...), Violation{Rule: rule, Description: description})
+	return &clone
+}
+
 // NewAppError tạo một lỗi ứng dụng mới
 func NewAppError(code int, msg string, httpStatus int, grpcCode codes.Code) *AppError {
 	return &AppError{
@@ -33,17 +56,27 @@ func NewAppError(code int, msg string, httpStatus int, grpcCode codes.Code) *App
 
 // Định nghĩa các lỗi thường gặp
 var (
-	ErrNotFound     = NewAppError(1001, "Resource not found", http.StatusNotFound, codes.NotFound)
-	ErrUnauthorized = NewAppError(1002, "Authentication failed", http.StatusUnauthorized, codes.Unauthenticated)
-	ErrForbidden    = NewAppError(1003, "Access denied", http.StatusForbidden, codes.PermissionDenied)
-	ErrInternal     = NewAppError(1004, "Internal server error", http.StatusInternalServerError, codes.Internal)
-	ErrInvalidParam = NewAppError(1005, "Invalid request parameter", http.StatusBadRequest, codes.InvalidArgument)
+	ErrNotFound          = NewAppError(1001, "Resource not found", http.StatusNotFound, codes.NotFound)
+	ErrUnauthorized      = NewAppError(1002, "Authentication failed", http.StatusUnauthorized, codes.Unauthenticated)
+	ErrForbidden         = NewAppError(1003, "Access denied", http.StatusForbidden, codes.PermissionDenied)
+	ErrInternal          = NewAppError(1004, "Internal server error", http.StatusInternalServerError, codes.Internal)
+	ErrInvalidParam      = NewAppError(1005, "Invalid request parameter", http.StatusBadRequest, codes.InvalidArgument)
+	ErrResourceExhausted = NewAppError(1006, "Resource exhausted", http.StatusTooManyRequests, codes.ResourceExhausted)
 )
 
-// ToGRPCCode chuyển AppError sang gRPC Status
+// ToGRPCCode chuyển AppError sang gRPC Status.
+// Violations được gửi kèm dưới dạng QuotaFailure với ResourceExhausted,
+// và BadRequest (mỗi quy tắc là một field violation) với các mã khác.
 func ToGRPCCode(err error) error {
 	if appErr, ok := err.(*AppError); ok {
-		return status.Error(appErr.GRPCCode, appErr.Message)
+		st := status.New(appErr.GRPCCode, appErr.Message)
+		if len(appErr.Violations) == 0 {
+			return st.Err()
+		}
+		if withDetails, detailErr := st.WithDetails(violationDetails(appErr)); detailErr == nil {
+			return withDetails.Err()
+		}
+		return st.Err()
 	}
 	// Mặc định là Internal nếu không phải AppError
 	return status.Error(codes.Internal, ErrInternal.Message)
@@ -65,7 +98,32 @@ func FromGRPCCode(err error) *AppError {
 		return ErrForbidden
 	case codes.InvalidArgument:
 		return ErrInvalidParam
+	case codes.ResourceExhausted:
+		return ErrResourceExhausted
 	default:
 		return ErrInternal
 	}
 }
+
+// violationDetails chuyển Violations sang gRPC error details chuẩn
+func violationDetails(appErr *AppError) protoadapt.MessageV1 {
+	if appErr.GRPCCode == codes.ResourceExhausted {
+		quota := &errdetails.QuotaFailure{}
+		for _, v := range appErr.Violations {
+			quota.Violations = append(quota.Violations, &errdetails.QuotaFailure_Violation{
+				Subject:     v.Rule,
+				Description: v.Description,
+			})
+		}
+		return quota
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range appErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Rule,
+			Description: v.Description,
+		})
+	}
+	return badRequest
+}
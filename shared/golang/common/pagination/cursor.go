@@ -0,0 +1,109 @@
+// Package pagination cung cấp cursor keyset có chữ ký cho các API danh sách.
+//
+// Cursor là vị trí của phần tử cuối cùng của trang trước theo khóa sắp xếp
+// (CreatedAt, ID), hoặc (Score, ID) cho danh sách sắp xếp theo một số đếm như
+// lượt xem. Client chỉ nhận một chuỗi mờ (opaque), được ký HMAC và gắn
+// với một scope (ví dụ danh sách video của một user), nên không thể sửa hoặc
+// dùng lại cho danh sách khác.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Giới hạn kích thước trang
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// signatureLen là số byte HMAC giữ lại trong cursor
+const signatureLen = 16
+
+// ErrInvalidCursor được trả về khi cursor bị sửa, sai scope hoặc sai định dạng
+var ErrInvalidCursor = errors.New("pagination: invalid cursor")
+
+// Cursor là vị trí của phần tử cuối cùng đã trả về
+type Cursor struct {
+	CreatedAt time.Time
+	// Score là khóa sắp xếp của danh sách không sắp xếp theo thời gian, bỏ trống nếu không dùng
+	Score int64
+	ID    string
+}
+
+// payload là dạng JSON của Cursor bên trong chuỗi mã hóa
+type payload struct {
+	CreatedAt int64  `json:"t"`
+	Score     int64  `json:"s,omitempty"`
+	ID        string `json:"id"`
+}
+
+// Codec mã hóa và ký cursor bằng một khóa bí mật của service
+type Codec struct {
+	key []byte
+}
+
+// NewCodec tạo Codec với khóa ký
+func NewCodec(key []byte) *Codec {
+	return &Codec{key: key}
+}
+
+// Encode mã hóa cursor thành chuỗi mờ, chỉ hợp lệ cho scope
+func (c *Codec) Encode(scope string, cursor Cursor) string {
+	data, _ := json.Marshal(payload{CreatedAt: cursor.CreatedAt.UnixNano(), Score: cursor.Score, ID: cursor.ID})
+	body := base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(c.sign(scope, body))
+}
+
+// Decode giải mã cursor của scope. Chuỗi rỗng nghĩa là trang đầu tiên, trả về nil
+func (c *Codec) Decode(scope, token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(signature, c.sign(scope, body)) {
+		return nil, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil || p.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: time.Unix(0, p.CreatedAt), Score: p.Score, ID: p.ID}, nil
+}
+
+// sign tính HMAC rút gọn của scope và phần thân cursor
+func (c *Codec) sign(scope, body string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write([]byte(body))
+	return mac.Sum(nil)[:signatureLen]
+}
+
+// NormalizeLimit đưa kích thước trang về khoảng [1, MaxLimit], mặc định DefaultLimit
+func NormalizeLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultLimit
+	case limit > MaxLimit:
+		return MaxLimit
+	default:
+		return limit
+	}
+}
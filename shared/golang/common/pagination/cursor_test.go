@@ -0,0 +1,175 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	createdAt := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"theo thời gian", Cursor{CreatedAt: createdAt, ID: "video-1"}},
+		{"theo số đếm", Cursor{CreatedAt: createdAt, Score: 42, ID: "video-2"}},
+		{"thời gian rỗng", Cursor{ID: "video-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := codec.Decode("user:1", codec.Encode("user:1", tt.cursor))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !got.CreatedAt.Equal(time.Unix(0, tt.cursor.CreatedAt.UnixNano())) || got.Score != tt.cursor.Score || got.ID != tt.cursor.ID {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestCodecDecodeEmpty(t *testing.T) {
+	got, err := NewCodec([]byte("secret")).Decode("user:1", "")
+	if got != nil || err != nil {
+		t.Errorf("Decode(\"\") = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestCodecDecodeInvalid(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	token := codec.Encode("user:1", Cursor{CreatedAt: time.Now(), ID: "video-1"})
+	body, sig, _ := strings.Cut(token, ".")
+
+	// Thân cursor bị sửa nhưng giữ nguyên chữ ký
+	tamperedBody := base64.RawURLEncoding.EncodeToString([]byte(`{"t":1,"id":"video-2"}`)) + "." + sig
+	// Chữ ký bị đổi một ký tự
+	flipped := []byte(sig)
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+	tamperedSig := body + "." + string(flipped)
+	// Payload hợp lệ được ký đúng nhưng không phải JSON của cursor
+	notJSON := base64.RawURLEncoding.EncodeToString([]byte("not json"))
+	missingID := base64.RawURLEncoding.EncodeToString([]byte(`{"t":1}`))
+
+	tests := []struct {
+		name  string
+		codec *Codec
+		scope string
+		token string
+	}{
+		{"thân bị sửa", codec, "user:1", tamperedBody},
+		{"chữ ký bị sửa", codec, "user:1", tamperedSig},
+		{"sai khóa", NewCodec([]byte("other secret")), "user:1", token},
+		{"sai scope", codec, "user:2", token},
+		{"bị cắt ngắn", codec, "user:1", token[:len(token)-4]},
+		{"thiếu chữ ký", codec, "user:1", body},
+		{"chữ ký rỗng", codec, "user:1", body + "."},
+		{"rác", codec, "user:1", "!!!.???"},
+		{"không phải JSON", codec, "user:1", notJSON + "." + base64.RawURLEncoding.EncodeToString(codec.sign("user:1", notJSON))},
+		{"thiếu ID", codec, "user:1", missingID + "." + base64.RawURLEncoding.EncodeToString(codec.sign("user:1", missingID))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.codec.Decode(tt.scope, tt.token)
+			if err != ErrInvalidCursor {
+				t.Errorf("Decode() = %+v, %v, want ErrInvalidCursor", got, err)
+			}
+		})
+	}
+}
+
+// item là một phần tử của danh sách sắp xếp theo (CreatedAt, ID) giảm dần
+type item struct {
+	createdAt time.Time
+	id        string
+}
+
+// after kiểm tra item nằm sau cursor theo khóa keyset (CreatedAt, ID) giảm dần
+func (it item) after(c *Cursor) bool {
+	if c == nil {
+		return true
+	}
+	if !it.createdAt.Equal(c.CreatedAt) {
+		return it.createdAt.Before(c.CreatedAt)
+	}
+	return it.id < c.ID
+}
+
+func TestCodecPagesThroughCreatedAtTies(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Ba phần tử trùng CreatedAt nằm vắt qua ranh giới trang, chỉ ID phân biệt được chúng
+	items := []item{
+		{base.Add(time.Minute), "e"},
+		{base, "d"},
+		{base, "b"},
+		{base, "c"},
+		{base.Add(-time.Minute), "a"},
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].createdAt.Equal(items[j].createdAt) {
+			return items[i].createdAt.After(items[j].createdAt)
+		}
+		return items[i].id > items[j].id
+	})
+
+	var (
+		got   []string
+		token string
+	)
+	for page := 0; page < len(items); page++ {
+		cursor, err := codec.Decode("feed", token)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+
+		var next []item
+		for _, it := range items {
+			if it.after(cursor) && len(next) < 2 {
+				next = append(next, it)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		for _, it := range next {
+			got = append(got, it.id)
+		}
+		last := next[len(next)-1]
+		token = codec.Encode("feed", Cursor{CreatedAt: last.createdAt, ID: last.id})
+	}
+
+	if want := "e,d,c,b,a"; strings.Join(got, ",") != want {
+		t.Errorf("pages = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestNormalizeLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{-1, DefaultLimit},
+		{0, DefaultLimit},
+		{1, 1},
+		{MaxLimit, MaxLimit},
+		{MaxLimit + 1, MaxLimit},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeLimit(tt.limit); got != tt.want {
+			t.Errorf("NormalizeLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -15,6 +15,7 @@ type AppConfig struct {
 	Redis       RedisConfig
 	Kafka       KafkaConfig
 	Tracing     TracingConfig
+	Storage     StorageConfig
 }
 
 // ServerConfig cho HTTP/gRPC
@@ -42,6 +43,27 @@ type KafkaConfig struct {
 	Brokers []string `mapstructure:"KAFKA_BROKERS"`
 }
 
+// StorageConfig cho Object Storage (local / s3 / minio)
+type StorageConfig struct {
+	Type   string `mapstructure:"STORAGE_TYPE"` // local, s3, minio
+	Bucket string `mapstructure:"AWS_S3_BUCKET"`
+	Region string `mapstructure:"AWS_REGION"`
+
+	// Dành cho server tương thích S3 (MinIO...)
+	Endpoint       string `mapstructure:"STORAGE_ENDPOINT"`         // host:port, để trống nếu dùng AWS
+	UseSSL         bool   `mapstructure:"STORAGE_USE_SSL"`          // Dùng HTTPS khi gọi endpoint
+	ForcePathStyle bool   `mapstructure:"STORAGE_FORCE_PATH_STYLE"` // http://endpoint/bucket/key thay vì bucket.endpoint
+	AccessKey      string `mapstructure:"AWS_ACCESS_KEY_ID"`
+	SecretKey      string `mapstructure:"AWS_SECRET_ACCESS_KEY"`
+	PublicURL      string `mapstructure:"STORAGE_PUBLIC_URL"` // URL gốc trả về cho client (CDN...)
+
+	// Dành cho backend local (dev/test)
+	LocalRoot      string `mapstructure:"STORAGE_LOCAL_ROOT"`       // Thư mục gốc lưu file
+	LocalAddr      string `mapstructure:"STORAGE_LOCAL_ADDR"`       // Địa chỉ HTTP file server
+	LocalPublicURL string `mapstructure:"STORAGE_LOCAL_PUBLIC_URL"` // URL công khai trỏ tới file server
+	SigningKey     string `mapstructure:"STORAGE_SIGNING_KEY"`      // Khóa ký presigned URL
+}
+
 // TracingConfig cho OpenTelemetry
 type TracingConfig struct {
 	JaegerEndpoint string `mapstructure:"JAEGER_ENDPOINT"`
@@ -52,6 +74,14 @@ func LoadConfig() AppConfig {
 	// Set default values (optional)
 	viper.SetDefault("SERVICE_NAME", "unknown-service")
 	viper.SetDefault("ENVIRONMENT", "development")
+	viper.SetDefault("STORAGE_TYPE", "s3")
+	viper.SetDefault("AWS_S3_BUCKET", "tiktok-videos")
+	viper.SetDefault("AWS_REGION", "us-east-1")
+	viper.SetDefault("STORAGE_USE_SSL", true)
+	viper.SetDefault("STORAGE_LOCAL_ROOT", "./data/storage")
+	viper.SetDefault("STORAGE_LOCAL_ADDR", ":9100")
+	viper.SetDefault("STORAGE_LOCAL_PUBLIC_URL", "http://localhost:9100")
+	viper.SetDefault("REDIS_ADDR", "localhost:6379")
 
 	// Đọc từ biến môi trường
 	viper.AutomaticEnv()
@@ -72,6 +102,27 @@ func LoadConfig() AppConfig {
 		log.Println("KAFKA_BROKERS not set or empty. Using default.")
 	}
 
+	// Struct lồng nhau không được viper.Unmarshal điền từ biến môi trường, nên đọc trực tiếp
+	cfg.Storage = StorageConfig{
+		Type:           viper.GetString("STORAGE_TYPE"),
+		Bucket:         viper.GetString("AWS_S3_BUCKET"),
+		Region:         viper.GetString("AWS_REGION"),
+		Endpoint:       viper.GetString("STORAGE_ENDPOINT"),
+		UseSSL:         viper.GetBool("STORAGE_USE_SSL"),
+		ForcePathStyle: viper.GetBool("STORAGE_FORCE_PATH_STYLE"),
+		AccessKey:      viper.GetString("AWS_ACCESS_KEY_ID"),
+		SecretKey:      viper.GetString("AWS_SECRET_ACCESS_KEY"),
+		PublicURL:      viper.GetString("STORAGE_PUBLIC_URL"),
+		LocalRoot:      viper.GetString("STORAGE_LOCAL_ROOT"),
+		LocalAddr:      viper.GetString("STORAGE_LOCAL_ADDR"),
+		LocalPublicURL: viper.GetString("STORAGE_LOCAL_PUBLIC_URL"),
+		SigningKey:     viper.GetString("STORAGE_SIGNING_KEY"),
+	}
+
+	cfg.Redis = RedisConfig{
+		Addr: viper.GetString("REDIS_ADDR"),
+	}
+
 	log.Printf("Configuration loaded successfully for service: %s", cfg.ServiceName)
 	return cfg
 }
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tiktok-clone/shared/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// InitRedis kết nối và trả về Redis client
+func InitRedis(cfg config.RedisConfig) *redis.Client {
+	client := redis.NewClient(&redis.Options{
+		Addr: cfg.Addr,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+
+	log.Println("Connected successfully to Redis")
+	return client
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"net"
 	"strings"
 
 	"go.uber.org/zap"
@@ -16,10 +17,26 @@ import (
 const AuthKey = "user-id"
 const MetadataAuthHeader = "x-user-id" // Header/Metadata chuẩn từ API Gateway
 
+// RolesKey là key để lưu danh sách role của user trong context
+const RolesKey = "user-roles"
+const MetadataRolesHeader = "x-user-roles" // Danh sách role, phân cách bằng dấu phẩy
+
+// RegionKey là key để lưu quốc gia của người gọi trong context
+const RegionKey = "user-region"
+const MetadataRegionHeader = "x-region" // Mã quốc gia ISO 3166-1 alpha-2, Gateway suy ra từ IP
+
+// ClientIPKey là key để lưu địa chỉ IP của client trong context
+const ClientIPKey = "client-ip"
+const MetadataClientIPHeader = "x-client-ip" // IP của client mà Gateway nhận request
+
+// UserAgentKey là key để lưu User-Agent của client trong context
+const UserAgentKey = "client-user-agent"
+const MetadataUserAgentHeader = "x-client-user-agent" // User-Agent gốc của client, Gateway chuyển tiếp
+
 // GetUserIDFromContext lấy User ID từ context
 func GetUserIDFromContext(ctx context.Context) (string, error) {
-	id, ok := ctx.Value(AuthKey).(string)
-	if !ok || id == "" {
+	id, ok := LookupUserID(ctx)
+	if !ok {
 		// Log lỗi nếu User ID không tồn tại
 		logger.ForContext(ctx).Error("User ID not found in context")
 		return "", errors.ErrUnauthorized
@@ -27,8 +44,59 @@ func GetUserIDFromContext(ctx context.Context) (string, error) {
 	return id, nil
 }
 
+// LookupUserID lấy User ID từ context nếu có, không log lỗi.
+// Dùng cho các endpoint Public mà kết quả phụ thuộc vào người gọi
+func LookupUserID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(AuthKey).(string)
+	return id, ok && id != ""
+}
+
+// GetUserRolesFromContext lấy danh sách role của user từ context, rỗng nếu không có
+func GetUserRolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(RolesKey).([]string)
+	return roles
+}
+
+// GetRegionFromContext lấy mã quốc gia (chữ hoa) của người gọi từ context, rỗng nếu không rõ
+func GetRegionFromContext(ctx context.Context) string {
+	region, _ := ctx.Value(RegionKey).(string)
+	return region
+}
+
+// GetClientIPFromContext lấy địa chỉ IP của client từ context, rỗng nếu không rõ
+func GetClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPKey).(string)
+	return ip
+}
+
+// GetUserAgentFromContext lấy User-Agent của client từ context, rỗng nếu không rõ
+func GetUserAgentFromContext(ctx context.Context) string {
+	userAgent, _ := ctx.Value(UserAgentKey).(string)
+	return userAgent
+}
+
 // GRPCExtractUserInterceptor là gRPC Interceptor để trích xuất User ID từ Metadata
 func GRPCExtractUserInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(extractUser(ctx), req)
+}
+
+// GRPCExtractUserStreamInterceptor là phiên bản Stream của GRPCExtractUserInterceptor
+func GRPCExtractUserStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &userServerStream{ServerStream: ss, ctx: extractUser(ss.Context())})
+}
+
+// userServerStream bọc ServerStream để trả về context đã có User ID
+type userServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *userServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// extractUser đọc User ID từ Metadata và đặt vào context
+func extractUser(ctx context.Context) context.Context {
 	log := logger.ForContext(ctx)
 
 	// Lấy metadata từ context (do API Gateway .NET truyền qua gRPC)
@@ -36,7 +104,22 @@ func GRPCExtractUserInterceptor(ctx context.Context, req interface{}, info *grpc
 	if !ok {
 		log.Warn("Missing gRPC metadata in request")
 		// Vẫn cho phép đi tiếp nếu service không yêu cầu AUTH
-		return handler(ctx, req)
+		return ctx
+	}
+
+	// Quốc gia, IP và User-Agent có cả với người dùng chưa đăng nhập
+	if regions := md.Get(MetadataRegionHeader); len(regions) > 0 {
+		if region, ok := ParseRegion(regions[0]); ok {
+			ctx = context.WithValue(ctx, RegionKey, region)
+		}
+	}
+	if ips := md.Get(MetadataClientIPHeader); len(ips) > 0 {
+		if ip := net.ParseIP(strings.TrimSpace(ips[0])); ip != nil {
+			ctx = context.WithValue(ctx, ClientIPKey, ip.String())
+		}
+	}
+	if userAgents := md.Get(MetadataUserAgentHeader); len(userAgents) > 0 && userAgents[0] != "" {
+		ctx = context.WithValue(ctx, UserAgentKey, userAgents[0])
 	}
 
 	// Lấy User ID từ header nội bộ (đã được xác thực từ Gateway)
@@ -45,13 +128,39 @@ func GRPCExtractUserInterceptor(ctx context.Context, req interface{}, info *grpc
 		userID := strings.TrimSpace(userIDs[0])
 
 		// Đặt User ID vào context để các handler sau dễ dàng sử dụng
-		newCtx := context.WithValue(ctx, AuthKey, userID)
 		log.Debug("Authenticated user found", zap.String("userID", userID))
+		ctx = context.WithValue(ctx, AuthKey, userID)
 
-		return handler(newCtx, req)
+		// Role chỉ có nghĩa khi đi kèm User ID đã được Gateway xác thực
+		if roles := parseRoles(md.Get(MetadataRolesHeader)); len(roles) > 0 {
+			ctx = context.WithValue(ctx, RolesKey, roles)
+		}
+		return ctx
 	}
 
 	// Nếu không có header, tiếp tục xử lý (dành cho các endpoint Public)
 	// Các handler cụ thể sẽ check auth nếu cần
-	return handler(ctx, req)
+	return ctx
+}
+
+// ParseRegion chuẩn hóa mã quốc gia ISO gồm 2 chữ cái, trả về false nếu giá trị không hợp lệ
+func ParseRegion(value string) (string, bool) {
+	region := strings.ToUpper(strings.TrimSpace(value))
+	if len(region) != 2 || region[0] < 'A' || region[0] > 'Z' || region[1] < 'A' || region[1] > 'Z' {
+		return "", false
+	}
+	return region, true
+}
+
+// parseRoles tách các giá trị role, mỗi giá trị có thể chứa nhiều role phân cách bằng dấu phẩy
+func parseRoles(values []string) []string {
+	var roles []string
+	for _, value := range values {
+		for _, role := range strings.Split(value, ",") {
+			if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
 }
@@ -6,18 +6,43 @@ package proto
 import (
 	"context"
 
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 // Stub types - replace with actual generated code
 type VideoServiceServer interface {
 	UploadVideo(ctx context.Context, req *UploadVideoRequest) (*VideoResponse, error)
+	UploadVideoStream(stream VideoService_UploadVideoStreamServer) error
 	GetVideo(ctx context.Context, req *GetVideoRequest) (*VideoResponse, error)
 	GetUserVideos(ctx context.Context, req *GetUserVideosRequest) (*GetUserVideosResponse, error)
 	UpdateVideo(ctx context.Context, req *UpdateVideoRequest) (*VideoResponse, error)
 	DeleteVideo(ctx context.Context, req *DeleteVideoRequest) (*DeleteVideoResponse, error)
 	GetTrendingVideos(ctx context.Context, req *GetTrendingVideosRequest) (*GetTrendingVideosResponse, error)
 	RecordView(ctx context.Context, req *RecordViewRequest) (*RecordViewResponse, error)
+	CreateUploadSession(ctx context.Context, req *CreateUploadSessionRequest) (*UploadSessionResponse, error)
+	UploadChunk(ctx context.Context, req *UploadChunkRequest) (*UploadSessionResponse, error)
+	GetUploadSession(ctx context.Context, req *GetUploadSessionRequest) (*UploadSessionResponse, error)
+	CompleteUpload(ctx context.Context, req *CompleteUploadRequest) (*VideoResponse, error)
+	RequestUploadURL(ctx context.Context, req *RequestUploadURLRequest) (*UploadURLResponse, error)
+	ConfirmUpload(ctx context.Context, req *ConfirmUploadRequest) (*VideoResponse, error)
+	GetTranscodingJob(ctx context.Context, req *GetTranscodingJobRequest) (*TranscodingJobResponse, error)
+	SetCoverFrame(ctx context.Context, req *SetCoverFrameRequest) (*TranscodingJobResponse, error)
+	FindDuplicates(ctx context.Context, req *FindDuplicatesRequest) (*FindDuplicatesResponse, error)
+	ListVideosForReview(ctx context.Context, req *ListVideosForReviewRequest) (*GetUserVideosResponse, error)
+	ReviewVideo(ctx context.Context, req *ReviewVideoRequest) (*VideoResponse, error)
+	ListMyVideos(ctx context.Context, req *ListMyVideosRequest) (*GetUserVideosResponse, error)
+	PinVideo(ctx context.Context, req *PinVideoRequest) (*VideoResponse, error)
+	UnpinVideo(ctx context.Context, req *PinVideoRequest) (*VideoResponse, error)
+	LikeVideo(ctx context.Context, req *LikeVideoRequest) (*LikeVideoResponse, error)
+	UnlikeVideo(ctx context.Context, req *LikeVideoRequest) (*LikeVideoResponse, error)
+	GetVideoStats(ctx context.Context, req *GetVideoStatsRequest) (*VideoStatsResponse, error)
+	ListLikedVideos(ctx context.Context, req *ListLikedVideosRequest) (*GetUserVideosResponse, error)
+	ShareVideo(ctx context.Context, req *ShareVideoRequest) (*ShareVideoResponse, error)
+	ResolveShareLink(ctx context.Context, req *ResolveShareLinkRequest) (*ResolveShareLinkResponse, error)
+	GetHashtag(ctx context.Context, req *GetHashtagRequest) (*Hashtag, error)
+	ListVideosByHashtag(ctx context.Context, req *ListVideosByHashtagRequest) (*GetUserVideosResponse, error)
+	SuggestHashtags(ctx context.Context, req *SuggestHashtagsRequest) (*SuggestHashtagsResponse, error)
 }
 
 type UnimplementedVideoServiceServer struct{}
@@ -27,11 +52,36 @@ type UploadVideoRequest struct {
 	Description     string
 	VideoData       []byte
 	ThumbnailData   []byte
+	Tags            []string
 	DurationSeconds int32
 	Width           int32
 	Height          int32
 }
 
+// UploadVideoStreamRequest mirrors the "payload" oneof: the first message of the
+// stream carries Metadata, every following message carries a Chunk
+type UploadVideoStreamRequest struct {
+	Metadata *UploadVideoMetadata
+	Chunk    []byte
+}
+
+type UploadVideoMetadata struct {
+	Title           string
+	Description     string
+	ThumbnailData   []byte
+	DurationSeconds int32
+	Width           int32
+	Height          int32
+	FileSize        int64
+	Tags            []string
+}
+
+type VideoService_UploadVideoStreamServer interface {
+	SendAndClose(*VideoResponse) error
+	Recv() (*UploadVideoStreamRequest, error)
+	grpc.ServerStream
+}
+
 type VideoResponse struct {
 	VideoId         string
 	UserId          string
@@ -39,10 +89,22 @@ type VideoResponse struct {
 	Description     string
 	VideoUrl        string
 	ThumbnailUrl    string
+	PlaylistUrl     string
+	Covers          []*CoverImage
+	PreviewUrl      string
+	StoryboardUrl   string
+	UrlsExpireAt    int64 // Unix time the signed URLs of a private video expire, 0 if unsigned
 	DurationSeconds int32
 	Width           int32
 	Height          int32
 	EncodingStatus  string
+	Visibility      string
+	IsPinned        bool
+	Category        string
+	Tags            []string // Hashtags without '#'
+	Mentions        []*MentionSpan
+	IsLiked         bool
+	AllowDownload   bool
 	ViewCount       int64
 	LikeCount       int64
 	CommentCount    int64
@@ -50,6 +112,20 @@ type VideoResponse struct {
 	CreatedAt       int64
 }
 
+// MentionSpan is an @username of the description at rune offsets [Start, End)
+type MentionSpan struct {
+	UserId   string
+	Username string
+	Start    int32
+	End      int32
+}
+
+type CoverImage struct {
+	Width  int32
+	Format string
+	Url    string
+}
+
 type GetVideoRequest struct {
 	VideoId string
 }
@@ -57,12 +133,78 @@ type GetVideoRequest struct {
 type GetUserVideosRequest struct {
 	UserId string
 	Limit  int32
-	Offset int32
+	Cursor string
 }
 
 type GetUserVideosResponse struct {
-	Videos []*VideoResponse
-	Total  int32
+	Videos     []*VideoResponse
+	Total      int32
+	NextCursor string
+	HasMore    bool
+}
+
+type ListMyVideosRequest struct {
+	Visibilities  []string
+	Statuses      []string
+	CreatedAfter  int64 // Unix time, 0 for no bound
+	CreatedBefore int64
+	Sort          string
+	Limit         int32
+	Cursor        string
+}
+
+type PinVideoRequest struct {
+	VideoId string
+}
+
+type LikeVideoRequest struct {
+	VideoId string
+}
+
+type LikeVideoResponse struct {
+	IsLiked    bool
+	LikesCount int64
+}
+
+type GetVideoStatsRequest struct {
+	VideoId string
+}
+
+type VideoStatsResponse struct {
+	ViewsCount    int64
+	LikesCount    int64
+	CommentsCount int64
+	SharesCount   int64
+	IsLiked       bool
+}
+
+type ListLikedVideosRequest struct {
+	Limit  int32
+	Cursor string
+}
+
+type ShareVideoRequest struct {
+	VideoId  string
+	Channel  string // copy_link, message or external_app
+	Download bool
+}
+
+type ShareVideoResponse struct {
+	Code            string
+	ShareUrl        string
+	SharesCount     int64
+	DownloadUrl     string
+	DownloadPending bool
+}
+
+type ResolveShareLinkRequest struct {
+	Code string
+}
+
+type ResolveShareLinkResponse struct {
+	VideoId     string
+	RedirectUrl string
+	ClickCount  int64
 }
 
 type UpdateVideoRequest struct {
@@ -70,9 +212,43 @@ type UpdateVideoRequest struct {
 	Title         *wrapperspb.StringValue
 	Description   *wrapperspb.StringValue
 	IsPublic      *wrapperspb.BoolValue
+	Visibility    *wrapperspb.StringValue
 	AllowComments *wrapperspb.BoolValue
 	AllowDuet     *wrapperspb.BoolValue
 	AllowStitch   *wrapperspb.BoolValue
+	Category      *wrapperspb.StringValue
+	AllowDownload *wrapperspb.BoolValue
+	Tags          *TagList // nil keeps the explicit tags
+}
+
+type TagList struct {
+	Tags []string
+}
+
+type GetHashtagRequest struct {
+	Name string
+}
+
+type Hashtag struct {
+	Name       string
+	UsageCount int64
+	CreatedAt  int64
+}
+
+type ListVideosByHashtagRequest struct {
+	Hashtag string
+	Sort    string // recent or popular
+	Limit   int32
+	Cursor  string
+}
+
+type SuggestHashtagsRequest struct {
+	Prefix string
+	Limit  int32
+}
+
+type SuggestHashtagsResponse struct {
+	Hashtags []*Hashtag
 }
 
 type DeleteVideoRequest struct {
@@ -84,19 +260,148 @@ type DeleteVideoResponse struct {
 }
 
 type GetTrendingVideosRequest struct {
-	Limit int32
+	Region   string
+	Category string
+	Hashtag  string
+	Window   string
+	Limit    int32
+	Cursor   string
 }
 
 type GetTrendingVideosResponse struct {
-	Videos []*VideoResponse
+	Videos     []*VideoResponse
+	NextCursor string
+	HasMore    bool
+	Region     string
 }
 
 type RecordViewRequest struct {
-	VideoId string
+	VideoId         string
+	WatchDurationMs int64
+	CompletionRate  float64 // Percent of the video watched, above 100 for replays
+	DeviceType      string
+	SessionId       string
 }
 
 type RecordViewResponse struct {
 	Success bool
+	Counted bool
+}
+
+type CreateUploadSessionRequest struct {
+	Title           string
+	Description     string
+	DurationSeconds int32
+	Width           int32
+	Height          int32
+	FileSize        int64
+}
+
+type UploadChunkRequest struct {
+	UploadId   string
+	PartNumber int32
+	Data       []byte
+}
+
+type GetUploadSessionRequest struct {
+	UploadId string
+}
+
+type CompleteUploadRequest struct {
+	UploadId      string
+	ThumbnailData []byte
+}
+
+type UploadSessionResponse struct {
+	UploadId      string
+	VideoId       string
+	ChunkSize     int64
+	TotalParts    int32
+	ReceivedParts []int32
+	Status        string
+	ExpiresAt     int64
+}
+
+type RequestUploadURLRequest struct {
+	Title           string
+	Description     string
+	DurationSeconds int32
+	Width           int32
+	Height          int32
+	FileSize        int64
+	ChecksumSha256  string
+}
+
+type PresignedPart struct {
+	PartNumber int32
+	Url        string
+}
+
+type UploadURLResponse struct {
+	VideoId   string
+	UploadId  string
+	UploadUrl string
+	Parts     []*PresignedPart
+	ChunkSize int64
+	Headers   map[string]string
+	ExpiresAt int64
+}
+
+type CompletedPart struct {
+	PartNumber int32
+	Etag       string
+}
+
+type ConfirmUploadRequest struct {
+	VideoId string
+	Parts   []*CompletedPart
+}
+
+type GetTranscodingJobRequest struct {
+	VideoId string
+}
+
+type TranscodingJobResponse struct {
+	JobId       string
+	VideoId     string
+	Kind        string
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	LastError   string
+	NextRunAt   int64
+	CreatedAt   int64
+	UpdatedAt   int64
+}
+
+type SetCoverFrameRequest struct {
+	VideoId     string
+	TimestampMs int64
+}
+
+type FindDuplicatesRequest struct {
+	VideoId string
+}
+
+type DuplicateVideo struct {
+	Video    *VideoResponse
+	Match    string
+	Distance int32
+}
+
+type FindDuplicatesResponse struct {
+	Duplicates []*DuplicateVideo
+}
+
+type ListVideosForReviewRequest struct {
+	Limit  int32
+	Cursor string
+}
+
+type ReviewVideoRequest struct {
+	VideoId string
+	Approve bool
+	Reason  string
 }
 
 func RegisterVideoServiceServer(s interface{}, srv VideoServiceServer) {}